@@ -0,0 +1,129 @@
+package geojson
+
+import (
+	geo "github.com/kellydunn/golang-geo"
+	"math"
+)
+
+/*
+
+Things that cross the antimeridian (Fiji, Chukotka, the Aleutians) are written with longitudes
+that jump from something like 179.9 to something like -179.9 and back again. Taken at face
+value that makes for rings that go the long way round the planet, bounding boxes that are
+(nearly) 360 degrees wide and raycasting that gets the wrong answer.
+
+The rule we use is the usual one: any edge whose longitudes are more than 180 degrees apart is
+assumed to cross the antimeridian. When a ring (or a line) does that we work with "shifted"
+longitudes instead, where everything west of the prime meridian has 360 added to it so that
+the geometry is continuous again, and shift back (or shift the point we're testing) as
+necessary. Bounding boxes for geometries that cross the antimeridian have a west longitude
+that is greater than their east longitude, as per RFC 7946 (see BoundingBox.Rects).
+
+Shifting only makes sense for things that cross the antimeridian (or, when comparing them with
+something that does, things that are entirely west of the prime meridian; see predicates.go):
+shift something that crosses the prime meridian and it is torn in two. By the same token a
+geometry that crosses both, like a ring that goes all the way round the planet, is beyond
+help.
+
+*/
+
+// CrossesAntimeridian reports whether any of the lines or polygons in g cross the antimeridian
+
+func CrossesAntimeridian(g Geometry) bool {
+
+	for _, part := range Flatten(g) {
+
+		switch geom := part.(type) {
+
+		case *LineString:
+
+			if crossesAntimeridian(geom.Coordinates) {
+				return true
+			}
+
+		case *Polygon:
+
+			if geom.CrossesAntimeridian() {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// CrossesAntimeridian reports whether the polygon's outer ring crosses the antimeridian
+
+func (p *WOFPolygon) CrossesAntimeridian() bool {
+	return crossesAntimeridian(p.OuterRing.Points())
+}
+
+func crossesAntimeridian(points []*geo.Point) bool {
+
+	for i := 1; i < len(points); i++ {
+
+		if math.Abs(points[i].Lng()-points[i-1].Lng()) > 180.0 {
+			return true
+		}
+	}
+
+	return false
+}
+
+func shiftLongitude(longitude float64) float64 {
+
+	if longitude < 0.0 {
+		return longitude + 360.0
+	}
+
+	return longitude
+}
+
+func unshiftLongitude(longitude float64) float64 {
+
+	if longitude > 180.0 {
+		return longitude - 360.0
+	}
+
+	return longitude
+}
+
+func shiftPoints(points []*geo.Point) []*geo.Point {
+
+	shifted := make([]*geo.Point, len(points))
+
+	for i, pt := range points {
+		shifted[i] = geo.NewPoint(pt.Lat(), shiftLongitude(pt.Lng()))
+	}
+
+	return shifted
+}
+
+func unshiftPoints(points []*geo.Point) []*geo.Point {
+
+	unshifted := make([]*geo.Point, len(points))
+
+	for i, pt := range points {
+		unshifted[i] = geo.NewPoint(pt.Lat(), unshiftLongitude(pt.Lng()))
+	}
+
+	return unshifted
+}
+
+// shiftedPolygonRings returns the outer ring followed by the interior rings; if the polygon crosses
+// the antimeridian they are shifted (see above) and shifted is true
+
+func shiftedPolygonRings(poly *WOFPolygon) ([][]*geo.Point, bool) {
+
+	rings := polygonRings(poly)
+
+	if !crossesAntimeridian(rings[0]) {
+		return rings, false
+	}
+
+	for i, points := range rings {
+		rings[i] = shiftPoints(points)
+	}
+
+	return rings, true
+}
@@ -0,0 +1,135 @@
+package geojson
+
+import (
+	"testing"
+)
+
+// a Fiji-like polygon that crosses the antimeridian, a UK-like one that crosses the prime
+// meridian (at the same latitudes as Fiji, so that only the longitudes keep them apart), a
+// Samoa-like one just west of the antimeridian and a Vanuatu-like one just east of it
+
+const (
+	fijiGeometry    = `{"type":"Polygon","coordinates":[[[177,-20],[-178,-20],[-178,-15],[177,-15],[177,-20]]]}`
+	ukGeometry      = `{"type":"Polygon","coordinates":[[[-6,-19],[2,-19],[2,-16],[-6,-16],[-6,-19]]]}`
+	samoaGeometry   = `{"type":"Polygon","coordinates":[[[-179,-19],[-178.5,-19],[-178.5,-18],[-179,-18],[-179,-19]]]}`
+	vanuatuGeometry = `{"type":"Polygon","coordinates":[[[178,-19],[179,-19],[179,-18],[178,-18],[178,-19]]]}`
+)
+
+func TestCrossesAntimeridian(t *testing.T) {
+
+	tests := []struct {
+		geometry string
+		expected bool
+	}{
+		{fijiGeometry, true},
+		{ukGeometry, false},
+		{samoaGeometry, false},
+		{`{"type":"LineString","coordinates":[[179,0],[-179,1]]}`, true},
+		{`{"type":"LineString","coordinates":[[-179,0],[179,1]]}`, true},
+		{`{"type":"LineString","coordinates":[[-90,0],[89,1]]}`, false},
+		{`{"type":"Point","coordinates":[180,0]}`, false},
+		{`{"type":"MultiPolygon","coordinates":[[[[0,0],[1,0],[1,1],[0,0]]],[[[177,-20],[-178,-20],[-178,-15],[177,-20]]]]}`, true},
+	}
+
+	for _, test := range tests {
+
+		g := testGeometry(t, test.geometry)
+
+		if CrossesAntimeridian(g) != test.expected {
+			t.Errorf("expected CrossesAntimeridian(%s) to be %t", test.geometry, test.expected)
+		}
+	}
+}
+
+func TestAntimeridianBounds(t *testing.T) {
+
+	bb := ComputeBounds(testGeometry(t, fijiGeometry))
+	expected := BoundingBox{SWLat: -20, SWLon: 177, NELat: -15, NELon: -178}
+
+	if !bb.Equals(&expected, 0.0) {
+		t.Errorf("expected the bounds to be %s, got %s", &expected, bb)
+	}
+
+	if !bb.CrossesAntimeridian() {
+		t.Error("expected the bounds to cross the antimeridian")
+	}
+
+	rects, err := bb.Rects()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(rects) != 2 {
+		t.Errorf("expected two rects, got %d", len(rects))
+	}
+
+	uk := ComputeBounds(testGeometry(t, ukGeometry))
+
+	if uk.CrossesAntimeridian() || uk.SWLon != -6 || uk.NELon != 2 {
+		t.Errorf("expected the UK's bounds to be left alone, got %s", uk)
+	}
+}
+
+func TestAntimeridianContains(t *testing.T) {
+
+	f := geometryFeature(t, fijiGeometry)
+
+	tests := []struct {
+		lat      float64
+		lon      float64
+		expected bool
+	}{
+		{-17, 178, true},
+		{-17, -179, true},
+		{-17, 180, true},
+		{-17, -180, true},
+		{-17, 176, false},
+		{-17, -177, false},
+		{-17, 0, false},
+		{-17, -2, false},
+		{-10, 179, false},
+	}
+
+	for _, test := range tests {
+
+		if f.Contains(test.lat, test.lon) != test.expected {
+			t.Errorf("expected Contains(%v, %v) to be %t", test.lat, test.lon, test.expected)
+		}
+	}
+}
+
+func TestAntimeridianPredicates(t *testing.T) {
+
+	fiji := testGeometry(t, fijiGeometry)
+	uk := testGeometry(t, ukGeometry)
+	samoa := testGeometry(t, samoaGeometry)
+	vanuatu := testGeometry(t, vanuatuGeometry)
+
+	tests := []struct {
+		name      string
+		a         Geometry
+		b         Geometry
+		predicate func(Geometry, Geometry) bool
+		expected  bool
+	}{
+		// shifting the UK as well as Fiji would tear it in two and wrap it round the planet,
+		// right through Fiji
+		{"Intersects(uk, fiji)", uk, fiji, Intersects, false},
+		{"Intersects(fiji, uk)", fiji, uk, Intersects, false},
+		{"Disjoint(fiji, uk)", fiji, uk, Disjoint, true},
+		{"Within(samoa, fiji)", samoa, fiji, Within, true},
+		{"Covers(fiji, samoa)", fiji, samoa, Covers, true},
+		{"Within(vanuatu, fiji)", vanuatu, fiji, Within, true},
+		{"Covers(fiji, vanuatu)", fiji, vanuatu, Covers, true},
+		{"Intersects(samoa, vanuatu)", samoa, vanuatu, Intersects, false},
+		{"Intersects(uk, samoa)", uk, samoa, Intersects, false},
+	}
+
+	for _, test := range tests {
+
+		if test.predicate(test.a, test.b) != test.expected {
+			t.Errorf("expected %s to be %t", test.name, test.expected)
+		}
+	}
+}
@@ -0,0 +1,266 @@
+package geojson
+
+import (
+	"fmt"
+	rtreego "github.com/dhconnelly/rtreego"
+	"math"
+	"strconv"
+	"strings"
+)
+
+/*
+
+BoundingBox is a plain old bounding box. ComputeBounds works one out from a geometry (rather than
+trusting whatever is in the feature's "bbox" property) and, unlike the code it replaces, doesn't
+use 0.0 as a "not set yet" value so it gets the right answer for things that touch the equator
+or the prime meridian.
+
+*/
+
+// rtreego insists that every side of a rectangle have a positive length so points and
+// perfectly horizontal or vertical lines are padded by this much
+
+const minBoundsLength = 0.000001
+
+type BoundingBox struct {
+	SWLat float64
+	SWLon float64
+	NELat float64
+	NELon float64
+}
+
+// NewBoundingBox returns an "empty" bounding box that will become whatever the first
+// coordinate added to it is
+
+func NewBoundingBox() *BoundingBox {
+
+	bb := BoundingBox{
+		SWLat: math.Inf(1),
+		SWLon: math.Inf(1),
+		NELat: math.Inf(-1),
+		NELon: math.Inf(-1),
+	}
+
+	return &bb
+}
+
+// IsEmpty reports whether nothing has been added to the bounding box. Note that it only looks
+// at latitudes since the west longitude of a bounding box that crosses the antimeridian is
+// greater than its east longitude.
+
+func (bb *BoundingBox) IsEmpty() bool {
+	return bb.SWLat > bb.NELat
+}
+
+// CrossesAntimeridian reports whether the bounding box's west longitude is greater than its
+// east longitude, which is how RFC 7946 says to write bounding boxes that cross the antimeridian
+
+func (bb *BoundingBox) CrossesAntimeridian() bool {
+	return !bb.IsEmpty() && bb.SWLon > bb.NELon
+}
+
+// Extend and Union don't know anything about the antimeridian
+
+func (bb *BoundingBox) Extend(latitude float64, longitude float64) {
+
+	bb.SWLat = math.Min(bb.SWLat, latitude)
+	bb.SWLon = math.Min(bb.SWLon, longitude)
+	bb.NELat = math.Max(bb.NELat, latitude)
+	bb.NELon = math.Max(bb.NELon, longitude)
+}
+
+func (bb *BoundingBox) Union(other *BoundingBox) {
+
+	if other.IsEmpty() {
+		return
+	}
+
+	bb.Extend(other.SWLat, other.SWLon)
+	bb.Extend(other.NELat, other.NELon)
+}
+
+// Equals reports whether each corner of the two bounding boxes is within tolerance degrees
+// of the other
+
+func (bb *BoundingBox) Equals(other *BoundingBox, tolerance float64) bool {
+
+	return math.Abs(bb.SWLat-other.SWLat) <= tolerance &&
+		math.Abs(bb.SWLon-other.SWLon) <= tolerance &&
+		math.Abs(bb.NELat-other.NELat) <= tolerance &&
+		math.Abs(bb.NELon-other.NELon) <= tolerance
+}
+
+// Array returns the bounding box in GeoJSON order: min longitude, min latitude, max longitude,
+// max latitude
+
+func (bb *BoundingBox) Array() []float64 {
+	return []float64{bb.SWLon, bb.SWLat, bb.NELon, bb.NELat}
+}
+
+// String returns the bounding box as a comma-separated string, in the same order as Array
+// (which is what the "geom:bbox" property looks like)
+
+func (bb *BoundingBox) String() string {
+
+	coords := make([]string, 0)
+
+	for _, c := range bb.Array() {
+		coords = append(coords, strconv.FormatFloat(c, 'f', -1, 64))
+	}
+
+	return strings.Join(coords, ",")
+}
+
+// Rect returns the bounding box as something rtreego can index. If the bounding box crosses
+// the antimeridian that means every longitude between its south and north latitudes; if you
+// want something tighter use Rects.
+
+func (bb *BoundingBox) Rect() (*rtreego.Rect, error) {
+
+	if bb.IsEmpty() {
+		return nil, fmt.Errorf("empty bounding box")
+	}
+
+	if bb.CrossesAntimeridian() {
+		return newRect(bb.SWLat, -180.0, bb.NELat, 180.0)
+	}
+
+	return newRect(bb.SWLat, bb.SWLon, bb.NELat, bb.NELon)
+}
+
+// Rects returns the bounding box as one rtreego.Rect or, if it crosses the antimeridian, two:
+// one on either side of it
+
+func (bb *BoundingBox) Rects() ([]*rtreego.Rect, error) {
+
+	if !bb.CrossesAntimeridian() {
+
+		rect, err := bb.Rect()
+
+		if err != nil {
+			return nil, err
+		}
+
+		return []*rtreego.Rect{rect}, nil
+	}
+
+	west, err := newRect(bb.SWLat, bb.SWLon, bb.NELat, 180.0)
+
+	if err != nil {
+		return nil, err
+	}
+
+	east, err := newRect(bb.SWLat, -180.0, bb.NELat, bb.NELon)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return []*rtreego.Rect{west, east}, nil
+}
+
+func newRect(swlat float64, swlon float64, nelat float64, nelon float64) (*rtreego.Rect, error) {
+
+	llat := nelat - swlat
+	llon := nelon - swlon
+
+	if llat <= 0.0 {
+		llat = minBoundsLength
+	}
+
+	if llon <= 0.0 {
+		llon = minBoundsLength
+	}
+
+	pt := rtreego.Point{swlon, swlat}
+	return rtreego.NewRect(pt, []float64{llon, llat})
+}
+
+// ComputeBounds returns the bounding box for any geometry (for polygons that means the outer
+// rings). The bounding box for a geometry without any coordinates is empty (see IsEmpty) and
+// the bounding box for a geometry that crosses the antimeridian has a west longitude that is
+// greater than its east longitude.
+
+func ComputeBounds(g Geometry) *BoundingBox {
+
+	bb := NewBoundingBox()
+	wrapped := CrossesAntimeridian(g)
+
+	for _, pt := range boundingPoints(g) {
+
+		longitude := pt.Lng()
+
+		if wrapped {
+			longitude = shiftLongitude(longitude)
+		}
+
+		bb.Extend(pt.Lat(), longitude)
+	}
+
+	if wrapped && !bb.IsEmpty() {
+		bb.SWLon = unshiftLongitude(bb.SWLon)
+		bb.NELon = unshiftLongitude(bb.NELon)
+	}
+
+	return bb
+}
+
+// ComputeBounds returns the bounding box for the feature's geometry, ignoring whatever is in
+// its "bbox" property
+
+func (wof WOFFeature) ComputeBounds() (*BoundingBox, error) {
+
+	geom, err := wof.Geometry()
+
+	if err != nil {
+		return nil, err
+	}
+
+	bb := ComputeBounds(geom)
+
+	if bb.IsEmpty() {
+		return nil, &DecodeError{Path: "geometry", Reason: "geometry has no coordinates"}
+	}
+
+	return bb, nil
+}
+
+// BoundingBox returns the feature's "bbox" property; if it doesn't have one the error is
+// ErrNoBoundingBox
+
+func (wof WOFFeature) BoundingBox() (*BoundingBox, error) {
+
+	body := wof.Body()
+
+	if !body.Exists("bbox") {
+		return nil, ErrNoBoundingBox
+	}
+
+	children, _ := body.S("bbox").Children()
+
+	if len(children) != 4 {
+		return nil, &DecodeError{Path: "bbox", Reason: "weird and freaky bounding box"}
+	}
+
+	coords := make([]float64, 4)
+
+	for i, child := range children {
+
+		n, err := decodeNumber(child.Data(), indexPath("bbox", i))
+
+		if err != nil {
+			return nil, err
+		}
+
+		coords[i] = n
+	}
+
+	bb := BoundingBox{
+		SWLon: coords[0],
+		SWLat: coords[1],
+		NELon: coords[2],
+		NELat: coords[3],
+	}
+
+	return &bb, nil
+}
@@ -0,0 +1,186 @@
+package geojson
+
+import (
+	"testing"
+)
+
+func TestComputeBounds(t *testing.T) {
+
+	tests := []struct {
+		name     string
+		geometry string
+		expected string
+	}{
+		{"point", `{"type":"Point","coordinates":[-1.5,2.5]}`, "-1.5,2.5,-1.5,2.5"},
+		{"equator", `{"type":"LineString","coordinates":[[-2,0],[-1,0]]}`, "-2,0,-1,0"},
+		{"prime meridian", `{"type":"LineString","coordinates":[[0,-3],[0,-2]]}`, "0,-3,0,-2"},
+		{"southern hemisphere", `{"type":"Polygon","coordinates":[[[-10,-10],[-5,-10],[-5,-5],[-10,-5],[-10,-10]]]}`, "-10,-10,-5,-5"},
+		{"outer ring only", `{"type":"Polygon","coordinates":[[[0,0],[4,0],[4,4],[0,4],[0,0]],[[1,1],[1,9],[9,9],[1,1]]]}`, "0,0,4,4"},
+		{"multipolygon", `{"type":"MultiPolygon","coordinates":[[[[0,0],[1,0],[1,1],[0,0]]],[[[5,-5],[6,-5],[6,-4],[5,-5]]]]}`, "0,-5,6,1"},
+		{"collection", `{"type":"GeometryCollection","geometries":[{"type":"Point","coordinates":[3,4]},{"type":"MultiPoint","coordinates":[[-3,-4],[1,1]]}]}`, "-3,-4,3,4"},
+		{"antimeridian", `{"type":"Polygon","coordinates":[[[177,-20],[-178,-20],[-178,-15],[177,-15],[177,-20]]]}`, "177,-20,-178,-15"},
+	}
+
+	for _, test := range tests {
+
+		bb := ComputeBounds(testGeometry(t, test.geometry))
+
+		if bb.IsEmpty() {
+			t.Errorf("%s: expected the bounds not to be empty", test.name)
+			continue
+		}
+
+		if bb.String() != test.expected {
+			t.Errorf("%s: expected %s, got %s", test.name, test.expected, bb.String())
+		}
+	}
+
+	for _, geom := range []string{`null`, `{"type":"GeometryCollection","geometries":[]}`, `{"type":"MultiPoint","coordinates":[]}`} {
+
+		bb := ComputeBounds(testGeometry(t, geom))
+
+		if !bb.IsEmpty() || bb.CrossesAntimeridian() {
+			t.Errorf("expected the bounds of %s to be empty, got %s", geom, bb)
+		}
+
+		_, err := bb.Rect()
+
+		if err == nil {
+			t.Errorf("expected an empty bounding box not to have a Rect")
+		}
+
+		_, err = bb.Rects()
+
+		if err == nil {
+			t.Errorf("expected an empty bounding box not to have any Rects")
+		}
+	}
+}
+
+func TestBoundingBoxUnionAndEquals(t *testing.T) {
+
+	bb := NewBoundingBox()
+	bb.Union(NewBoundingBox())
+
+	if !bb.IsEmpty() {
+		t.Error("expected the union of two empty bounding boxes to be empty")
+	}
+
+	bb.Union(&BoundingBox{SWLat: -1, SWLon: -2, NELat: 1, NELon: 2})
+	bb.Union(&BoundingBox{SWLat: 0, SWLon: 0, NELat: 3, NELon: 1})
+	bb.Union(NewBoundingBox())
+
+	tests := []struct {
+		other     *BoundingBox
+		tolerance float64
+		expected  bool
+	}{
+		{&BoundingBox{SWLat: -1, SWLon: -2, NELat: 3, NELon: 2}, 0.0, true},
+		{&BoundingBox{SWLat: -1, SWLon: -2, NELat: 3.001, NELon: 2}, 0.0, false},
+		{&BoundingBox{SWLat: -1, SWLon: -2, NELat: 3.001, NELon: 2}, 0.01, true},
+		{&BoundingBox{SWLat: -1, SWLon: -2.1, NELat: 3, NELon: 2}, 0.01, false},
+	}
+
+	for _, test := range tests {
+
+		if bb.Equals(test.other, test.tolerance) != test.expected {
+			t.Errorf("expected %s equals %s (give or take %v) to be %t", bb, test.other, test.tolerance, test.expected)
+		}
+	}
+
+	arr := bb.Array()
+
+	if len(arr) != 4 || arr[0] != -2 || arr[1] != -1 || arr[2] != 2 || arr[3] != 3 {
+		t.Errorf("expected the array to be in GeoJSON order, got %v", arr)
+	}
+}
+
+func TestBoundingBoxRect(t *testing.T) {
+
+	tests := []struct {
+		bb       *BoundingBox
+		expected [][4]float64 // min x, min y, max x, max y
+	}{
+		{&BoundingBox{SWLat: 1, SWLon: 2, NELat: 3, NELon: 5}, [][4]float64{{2, 1, 5, 3}}},
+		{&BoundingBox{SWLat: 1, SWLon: 2, NELat: 1, NELon: 2}, [][4]float64{{2, 1, 2 + minBoundsLength, 1 + minBoundsLength}}},
+		{&BoundingBox{SWLat: -20, SWLon: 177, NELat: -15, NELon: -178}, [][4]float64{{177, -20, 180, -15}, {-180, -20, -178, -15}}},
+	}
+
+	for _, test := range tests {
+
+		rects, err := test.bb.Rects()
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if len(rects) != len(test.expected) {
+			t.Errorf("expected %d rects for %s, got %d", len(test.expected), test.bb, len(rects))
+			continue
+		}
+
+		for i, r := range rects {
+
+			e := test.expected[i]
+
+			if !almostEqual(r.PointCoord(0), e[0], 1e-9) || !almostEqual(r.PointCoord(1), e[1], 1e-9) ||
+				!almostEqual(r.LengthsCoord(0), e[2]-e[0], 1e-9) || !almostEqual(r.LengthsCoord(1), e[3]-e[1], 1e-9) {
+				t.Errorf("expected rect %d for %s to be %v, got %v", i, test.bb, e, r)
+			}
+		}
+	}
+
+	// a single Rect for something that crosses the antimeridian goes all the way round
+
+	r, err := (&BoundingBox{SWLat: -20, SWLon: 177, NELat: -15, NELon: -178}).Rect()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if r.PointCoord(0) != -180.0 || r.LengthsCoord(0) != 360.0 {
+		t.Errorf("expected the rect to go all the way round, got %v", r)
+	}
+}
+
+func TestFeatureBounds(t *testing.T) {
+
+	square := `{"type":"Polygon","coordinates":[[[0,0],[2,0],[2,2],[0,2],[0,0]]]}`
+
+	f := testFeature(t, `{"type":"Feature","properties":{},"bbox":[10,10,12,12],"geometry":`+square+`}`)
+
+	computed, err := f.ComputeBounds()
+
+	if err != nil || computed.String() != "0,0,2,2" {
+		t.Errorf("expected ComputeBounds to ignore the bbox property, got %v %v", computed, err)
+	}
+
+	stored, err := f.BoundingBox()
+
+	if err != nil || stored.String() != "10,10,12,12" {
+		t.Errorf("expected BoundingBox to return the bbox property, got %v %v", stored, err)
+	}
+
+	// EnSpatialize trusts the bbox property if there is one
+
+	sp, err := f.EnSpatialize()
+
+	if err != nil || sp.Bounds().PointCoord(0) != 10.0 {
+		t.Errorf("expected EnSpatialize to use the bbox property, got %v %v", sp, err)
+	}
+
+	f = geometryFeature(t, square)
+	sp, err = f.EnSpatialize()
+
+	if err != nil || sp.Bounds().PointCoord(0) != 0.0 || sp.Bounds().LengthsCoord(1) != 2.0 {
+		t.Errorf("expected EnSpatialize to compute the bounds, got %v %v", sp, err)
+	}
+
+	f = geometryFeature(t, `{"type":"MultiPolygon","coordinates":[]}`)
+
+	_, err = f.ComputeBounds()
+
+	if err == nil {
+		t.Error("expected a geometry without any coordinates not to have bounds")
+	}
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	geojson "github.com/whosonfirst/go-whosonfirst-geojson"
+	"log"
+	"os"
+)
+
+func main() {
+
+	var tolerance = flag.Float64("tolerance", 0.000001, "How far (in degrees) the stored bbox may be from the computed one before it counts as different")
+	var rewrite = flag.Bool("rewrite", false, "Replace the stored bbox (and geom:bbox, if present) with the computed one")
+
+	flag.Parse()
+	args := flag.Args()
+
+	mismatches := 0
+
+	for _, path := range args {
+
+		f, parse_err := geojson.UnmarshalFile(path)
+
+		if parse_err != nil {
+			log.Printf("%s %s\n", path, parse_err)
+			continue
+		}
+
+		computed, err := f.ComputeBounds()
+
+		if err != nil {
+			log.Printf("%s %s\n", path, err)
+			continue
+		}
+
+		stored, err := f.BoundingBox()
+
+		if err != nil && err != geojson.ErrNoBoundingBox {
+			log.Printf("%s %s\n", path, err)
+			continue
+		}
+
+		if err == geojson.ErrNoBoundingBox {
+			fmt.Printf("%s missing computed %s\n", path, computed)
+		} else if !stored.Equals(computed, *tolerance) {
+			fmt.Printf("%s stored %s computed %s\n", path, stored, computed)
+		} else {
+			continue
+		}
+
+		mismatches += 1
+
+		if !*rewrite {
+			continue
+		}
+
+		body := f.Body()
+
+		_, err = body.Set(computed.Array(), "bbox")
+
+		if err != nil {
+			log.Printf("%s %s\n", path, err)
+			continue
+		}
+
+		if body.Exists("properties", "geom:bbox") {
+			body.Set(computed.String(), "properties", "geom:bbox")
+		}
+
+		err = geojson.WriteFile(path, f)
+
+		if err != nil {
+			log.Printf("failed to write %s, because %s\n", path, err)
+			continue
+		}
+
+		fmt.Printf("%s updated\n", path)
+	}
+
+	if mismatches > 0 && !*rewrite {
+		os.Exit(1)
+	}
+}
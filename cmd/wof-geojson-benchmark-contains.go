@@ -0,0 +1,138 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	geojson "github.com/whosonfirst/go-whosonfirst-geojson"
+	"log"
+	"math/rand"
+	"time"
+)
+
+func main() {
+
+	var count = flag.Int("points", 1000, "The number of random points (inside each feature's bounding box) to test")
+	var seed = flag.Int64("seed", 1, "The seed for the random number generator")
+	var rounds = flag.Int("rounds", 10, "The number of times to test every point with each method")
+
+	flag.Parse()
+	args := flag.Args()
+
+	r := rand.New(rand.NewSource(*seed))
+
+	for _, path := range args {
+
+		f, parse_err := geojson.UnmarshalFile(path)
+
+		if parse_err != nil {
+			log.Fatal(parse_err)
+		}
+
+		polygons, err := f.GeomToPolygonsWithError()
+
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		if len(polygons) == 0 {
+			log.Printf("%s has no polygons, skipping\n", path)
+			continue
+		}
+
+		swlat, swlon, nelat, nelon := 90.0, 180.0, -90.0, -180.0
+		points := 0
+
+		for _, p := range polygons {
+
+			points += p.CountPoints()
+
+			for _, pt := range p.OuterRing.Points() {
+
+				if pt.Lat() < swlat {
+					swlat = pt.Lat()
+				}
+
+				if pt.Lat() > nelat {
+					nelat = pt.Lat()
+				}
+
+				if pt.Lng() < swlon {
+					swlon = pt.Lng()
+				}
+
+				if pt.Lng() > nelon {
+					nelon = pt.Lng()
+				}
+			}
+		}
+
+		lats := make([]float64, *count)
+		lons := make([]float64, *count)
+
+		for i := 0; i < *count; i++ {
+			lats[i] = swlat + r.Float64()*(nelat-swlat)
+			lons[i] = swlon + r.Float64()*(nelon-swlon)
+		}
+
+		prepared, err := geojson.NewPreparedFeature(f)
+
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		mismatches := 0
+
+		for i := 0; i < *count; i++ {
+
+			if f.Contains(lats[i], lons[i]) != prepared.Contains(lats[i], lons[i]) {
+				mismatches += 1
+			}
+		}
+
+		// this is a quick way to try things out on real files; see prepared_test.go for
+		// the benchmarks proper (go test -bench Contains)
+
+		wof := timeContains(*count, *rounds, func(i int) {
+
+			for _, p := range polygons {
+
+				if p.Contains(lats[i], lons[i]) {
+					break
+				}
+			}
+		})
+
+		prep := timeContains(*count, *rounds, func(i int) {
+			prepared.Contains(lats[i], lons[i])
+		})
+
+		fmt.Printf("# %s (%d polygons, %d points)\n", path, len(polygons), points)
+		fmt.Printf("WOFPolygon.Contains %d ns/op\n", wof)
+		fmt.Printf("PreparedFeature.Contains %d ns/op\n", prep)
+
+		if prep > 0 {
+			fmt.Printf("speed-up %.1fx\n", float64(wof)/float64(prep))
+		}
+
+		fmt.Printf("mismatches %d/%d\n", mismatches, *count)
+	}
+}
+
+// timeContains returns the average time (in nanoseconds) it takes to call fn
+
+func timeContains(count int, rounds int, fn func(i int)) int64 {
+
+	ops := count * rounds
+
+	if ops <= 0 {
+		return 0
+	}
+
+	t1 := time.Now()
+
+	for n := 0; n < ops; n++ {
+		fn(n % count)
+	}
+
+	return time.Since(t1).Nanoseconds() / int64(ops)
+}
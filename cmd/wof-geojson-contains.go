@@ -1,23 +1,396 @@
 package main
 
 import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
 	"flag"
 	"fmt"
 	"github.com/whosonfirst/go-whosonfirst-geojson"
+	"github.com/whosonfirst/go-whosonfirst-geojson/index"
+	"io"
 	"log"
+	"os"
+	"runtime"
+	"sort"
 	"strconv"
 	"strings"
+	"sync"
 )
 
+// Batch mode (-points) reads a stream of points, tests each one against every feature (either
+// the files listed on the command line or everything in a -source directory) and writes one
+// result for each point, in the same order the points were read
+
+type BatchPoint struct {
+	Seq       int     `json:"-"`
+	Id        string  `json:"id"`
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+	Error     string  `json:"error,omitempty"`
+}
+
+type BatchMatch struct {
+	Id     int `json:"wof:id"`
+	Offset int `json:"offset"`
+}
+
+type BatchResult struct {
+	BatchPoint
+	Matches []BatchMatch `json:"matches"`
+}
+
+func readCSVPoints(fh io.Reader, points chan BatchPoint) error {
+
+	reader := csv.NewReader(fh)
+	reader.FieldsPerRecord = -1
+
+	// if the first row is a header use it to find the id, latitude and longitude
+	// columns otherwise assume that's what the first three columns are
+
+	columns := map[string]int{"id": 0, "latitude": 1, "longitude": 2}
+	seq := 0
+
+	for row := 0; ; row++ {
+
+		record, err := reader.Read()
+
+		if err == io.EOF {
+			break
+		}
+
+		if err != nil {
+			return err
+		}
+
+		if row == 0 {
+
+			header := make(map[string]int)
+
+			for i, name := range record {
+				header[strings.ToLower(strings.TrimSpace(name))] = i
+			}
+
+			_, has_lat := header["latitude"]
+			_, has_lon := header["longitude"]
+
+			if has_lat && has_lon {
+
+				columns = header
+
+				if _, ok := header["id"]; !ok {
+					columns["id"] = -1
+				}
+
+				continue
+			}
+		}
+
+		pt := BatchPoint{Seq: seq, Id: strconv.Itoa(row)}
+		seq += 1
+
+		field := func(name string) string {
+
+			i := columns[name]
+
+			if i < 0 || i >= len(record) {
+				return ""
+			}
+
+			return strings.TrimSpace(record[i])
+		}
+
+		if columns["id"] >= 0 {
+			pt.Id = field("id")
+		}
+
+		lat, lat_err := strconv.ParseFloat(field("latitude"), 64)
+		lon, lon_err := strconv.ParseFloat(field("longitude"), 64)
+
+		if lat_err != nil || lon_err != nil {
+			pt.Error = fmt.Sprintf("invalid coordinates on row %d", row+1)
+		}
+
+		pt.Latitude = lat
+		pt.Longitude = lon
+
+		points <- pt
+	}
+
+	return nil
+}
+
+func readJSONPoints(fh io.Reader, points chan BatchPoint) error {
+
+	scanner := bufio.NewScanner(fh)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+
+	seq := 0
+
+	for line := 1; scanner.Scan(); line++ {
+
+		raw := strings.TrimSpace(scanner.Text())
+
+		if raw == "" {
+			continue
+		}
+
+		pt := BatchPoint{Seq: seq, Id: strconv.Itoa(line)}
+		seq += 1
+
+		var record map[string]interface{}
+
+		decoder := json.NewDecoder(strings.NewReader(raw))
+		decoder.UseNumber()
+
+		err := decoder.Decode(&record)
+
+		if err != nil {
+			pt.Error = fmt.Sprintf("invalid JSON on line %d", line)
+			points <- pt
+			continue
+		}
+
+		id, ok := record["id"]
+
+		if ok {
+			pt.Id = fmt.Sprintf("%v", id)
+		}
+
+		lat, lat_err := strconv.ParseFloat(fmt.Sprintf("%v", record["latitude"]), 64)
+		lon, lon_err := strconv.ParseFloat(fmt.Sprintf("%v", record["longitude"]), 64)
+
+		if lat_err != nil || lon_err != nil {
+			pt.Error = fmt.Sprintf("invalid coordinates on line %d", line)
+		}
+
+		pt.Latitude = lat
+		pt.Longitude = lon
+
+		points <- pt
+	}
+
+	return scanner.Err()
+}
+
+func batch(idx *index.Index, fh io.Reader, input string, output string, procs int) error {
+
+	points := make(chan BatchPoint)
+	results := make(chan BatchResult)
+
+	read_err := make(chan error, 1)
+
+	go func() {
+
+		var err error
+
+		if input == "csv" {
+			err = readCSVPoints(fh, points)
+		} else {
+			err = readJSONPoints(fh, points)
+		}
+
+		close(points)
+		read_err <- err
+	}()
+
+	wg := new(sync.WaitGroup)
+
+	for i := 0; i < procs; i++ {
+
+		wg.Add(1)
+
+		go func() {
+
+			defer wg.Done()
+
+			for pt := range points {
+
+				matches := make([]BatchMatch, 0)
+
+				if pt.Error == "" {
+
+					for _, sp := range idx.GetByLatLon(pt.Latitude, pt.Longitude) {
+						matches = append(matches, BatchMatch{sp.Id, sp.Offset})
+					}
+
+					sort.Slice(matches, func(i, j int) bool {
+
+						if matches[i].Id != matches[j].Id {
+							return matches[i].Id < matches[j].Id
+						}
+
+						return matches[i].Offset < matches[j].Offset
+					})
+				}
+
+				results <- BatchResult{pt, matches}
+			}
+		}()
+	}
+
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	writer := bufio.NewWriter(os.Stdout)
+	defer writer.Flush()
+
+	csv_writer := csv.NewWriter(writer)
+	defer csv_writer.Flush()
+
+	if output == "csv" {
+		csv_writer.Write([]string{"id", "latitude", "longitude", "wof_id", "offset", "error"})
+	}
+
+	write := func(rsp BatchResult) error {
+
+		if output != "csv" {
+
+			enc, err := json.Marshal(rsp)
+
+			if err != nil {
+				return err
+			}
+
+			writer.Write(enc)
+			writer.WriteString("\n")
+			return nil
+		}
+
+		lat := strconv.FormatFloat(rsp.Latitude, 'f', -1, 64)
+		lon := strconv.FormatFloat(rsp.Longitude, 'f', -1, 64)
+
+		if len(rsp.Matches) == 0 {
+			return csv_writer.Write([]string{rsp.Id, lat, lon, "", "", rsp.Error})
+		}
+
+		for _, m := range rsp.Matches {
+
+			err := csv_writer.Write([]string{rsp.Id, lat, lon, strconv.Itoa(m.Id), strconv.Itoa(m.Offset), rsp.Error})
+
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+
+	// results arrive in whatever order the workers finish them so hold on to
+	// them until it's their turn in order to keep the output stable
+
+	pending := make(map[int]BatchResult)
+	next := 0
+
+	for rsp := range results {
+
+		pending[rsp.Seq] = rsp
+
+		for {
+
+			r, ok := pending[next]
+
+			if !ok {
+				break
+			}
+
+			delete(pending, next)
+			next += 1
+
+			err := write(r)
+
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return <-read_err
+}
+
 func main() {
 
 	var lat = flag.Float64("latitude", 0.0, "")
 	var lon = flag.Float64("longitude", 0.0, "")
 	var point = flag.String("point", "", "")
 
+	var points = flag.String("points", "", "Run in batch mode, reading points from this file (or \"-\" for STDIN)")
+	var input = flag.String("input", "csv", "The format of the points in batch mode: csv (id,latitude,longitude) or json (one {\"id\":..., \"latitude\":..., \"longitude\":...} object per line)")
+	var output = flag.String("output", "json", "The format of the results in batch mode: json (one object per line) or csv (one row per match)")
+	var source = flag.String("source", "", "In batch mode, test points against every GeoJSON file in this directory (as well as any files listed on the command line)")
+	var procs = flag.Int("processes", runtime.NumCPU()*2, "Number of concurrent processes to use in batch mode")
+
 	flag.Parse()
 	args := flag.Args()
 
+	if *points != "" {
+
+		if *input != "csv" && *input != "json" {
+			log.Fatal("Invalid -input format")
+		}
+
+		if *output != "csv" && *output != "json" {
+			log.Fatal("Invalid -output format")
+		}
+
+		idx := index.NewIndex()
+
+		// results are reported by WOF ID so a file that can't be indexed (including one
+		// without an ID) is a mistake rather than something to skip over quietly
+
+		for _, path := range args {
+
+			err := idx.IndexFile(path)
+
+			if err != nil {
+				log.Fatal(fmt.Sprintf("failed to index %s, because %s", path, err))
+			}
+		}
+
+		if *source != "" {
+
+			err := idx.IndexDirectory(*source, *procs)
+
+			if err != nil {
+
+				walk_err, ok := err.(*geojson.WalkError)
+
+				if ok {
+
+					for _, e := range walk_err.Errors {
+						log.Println(e)
+					}
+				}
+
+				log.Fatal(fmt.Sprintf("failed to index %s, because %s", *source, err))
+			}
+		}
+
+		var fh io.Reader = os.Stdin
+
+		if *points != "-" {
+
+			f, err := os.Open(*points)
+
+			if err != nil {
+				log.Fatal(err)
+			}
+
+			defer f.Close()
+			fh = f
+		}
+
+		err := batch(idx, fh, *input, *output, *procs)
+
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		return
+	}
+
 	if *point != "" {
 
 		parts := strings.Split(*point, ",")
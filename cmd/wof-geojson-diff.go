@@ -0,0 +1,90 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	geojson "github.com/whosonfirst/go-whosonfirst-geojson"
+	"log"
+	"os"
+	"strings"
+)
+
+func main() {
+
+	var ignore = flag.String("ignore", "", "A comma-separated list of properties to ignore")
+	var ignore_noise = flag.Bool("ignore-noise", false, "Ignore properties that change every time a record is exported (like wof:lastmodified)")
+
+	flag.Parse()
+	args := flag.Args()
+
+	if len(args) != 2 {
+		log.Fatal("Usage: wof-geojson-diff [options] OLD NEW")
+	}
+
+	a, err := geojson.UnmarshalFile(args[0])
+
+	if err != nil {
+		log.Fatalf("%s %s", args[0], err)
+	}
+
+	b, err := geojson.UnmarshalFile(args[1])
+
+	if err != nil {
+		log.Fatalf("%s %s", args[1], err)
+	}
+
+	opts := geojson.DiffOptions{
+		IgnoreNoise: *ignore_noise,
+	}
+
+	if *ignore != "" {
+		opts.IgnoreProperties = strings.Split(*ignore, ",")
+	}
+
+	d, err := geojson.DiffWithOptions(a, b, &opts)
+
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	for _, c := range d.Changes {
+
+		switch c.Type {
+		case geojson.ChangeAdded:
+			fmt.Printf("+ %s %s\n", c.Path, encode(c.New))
+		case geojson.ChangeRemoved:
+			fmt.Printf("- %s %s\n", c.Path, encode(c.Old))
+		default:
+			fmt.Printf("~ %s %s -> %s\n", c.Path, encode(c.Old), encode(c.New))
+		}
+	}
+
+	g := d.Geometry
+
+	if g.Changed {
+
+		fmt.Printf("~ geometry %s -> %s\n", g.OldType, g.NewType)
+		fmt.Printf("  positions %+d\n", g.PositionDelta)
+		fmt.Printf("  area %+f m2\n", g.AreaDelta)
+		fmt.Printf("  bbox %+f,%+f,%+f,%+f\n", g.BoundsDelta[0], g.BoundsDelta[1], g.BoundsDelta[2], g.BoundsDelta[3])
+		fmt.Printf("  topologically equal %t\n", g.TopologicallyEqual)
+	}
+
+	// like diff(1) exit with 1 if the records are different
+
+	if !d.IsEmpty() {
+		os.Exit(1)
+	}
+}
+
+func encode(v interface{}) string {
+
+	enc, err := json.Marshal(v)
+
+	if err != nil {
+		return fmt.Sprintf("%v", v)
+	}
+
+	return string(enc)
+}
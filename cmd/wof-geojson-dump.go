@@ -4,6 +4,9 @@ import (
 	"flag"
 	"fmt"
 	"github.com/whosonfirst/go-whosonfirst-geojson"
+	"io"
+	"log"
+	"os"
 )
 
 func main() {
@@ -13,19 +16,45 @@ func main() {
 
 	for _, path := range args {
 
-		f, parse_err := geojson.UnmarshalFile(path)
+		// Files may be a single Feature, a FeatureCollection or a sequence of
+		// (newline-delimited) Features - the reader figures that out for us
 
-		if parse_err != nil {
-			panic(parse_err)
+		fh, open_err := os.Open(path)
+
+		if open_err != nil {
+			panic(open_err)
 		}
 
-		fmt.Printf("# %s\n", path)
+		reader := geojson.NewReader(fh)
+
+		for {
+
+			f, parse_err := reader.Next()
+
+			if parse_err == io.EOF {
+				break
+			}
+
+			if parse_err != nil {
 
-		fmt.Printf("ID is %d\n", f.Id())
-		fmt.Printf("Name is %s\n", f.Name())
-		fmt.Printf("Placetype is %s\n", f.Placetype())
+				if _, ok := parse_err.(*geojson.DecodeError); ok {
+					log.Printf("%s %s\n", path, parse_err)
+					continue
+				}
+
+				panic(parse_err)
+			}
+
+			fmt.Printf("# %s\n", path)
+
+			fmt.Printf("ID is %d\n", f.Id())
+			fmt.Printf("Name is %s\n", f.Name())
+			fmt.Printf("Placetype is %s\n", f.Placetype())
+
+			fmt.Printf("Hierarchy is %v\n", f.Hierarchy())
+		}
 
-		fmt.Printf("Hierarchy is %s\n", f.Hierarchy())
+		fh.Close()
 	}
 
 }
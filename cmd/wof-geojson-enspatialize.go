@@ -4,6 +4,7 @@ import (
 	"flag"
 	"fmt"
 	geojson "github.com/whosonfirst/go-whosonfirst-geojson"
+	"log"
 )
 
 func main() {
@@ -21,13 +22,23 @@ func main() {
 
 		fmt.Println("Enspatialize bounding box")
 
-		sp, _ := f.EnSpatialize()
-		fmt.Printf("%v\n", sp)
-		fmt.Printf("%d\n", sp.Id)
+		sp, err := f.EnSpatialize()
+
+		if err != nil {
+			log.Printf("%s %s\n", path, err)
+		} else {
+			fmt.Printf("%v\n", sp)
+			fmt.Printf("%d\n", sp.Id)
+		}
 
 		fmt.Println("Enspatialize geom")
 
-		spg, _ := f.EnSpatializeGeom()
+		spg, err := f.EnSpatializeGeom()
+
+		if err != nil {
+			log.Printf("%s %s\n", path, err)
+			continue
+		}
 		fmt.Printf("%v\n", len(spg))
 
 		for _, s := range spg {
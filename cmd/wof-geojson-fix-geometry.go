@@ -0,0 +1,72 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	geojson "github.com/whosonfirst/go-whosonfirst-geojson"
+	"log"
+)
+
+func main() {
+
+	var write = flag.Bool("write", false, "Write the repaired geometry back to the file (the default is to just report what would change)")
+
+	flag.Parse()
+	args := flag.Args()
+
+	for _, path := range args {
+
+		f, parse_err := geojson.UnmarshalFile(path)
+
+		if parse_err != nil {
+			log.Printf("%s %s\n", path, parse_err)
+			continue
+		}
+
+		geom, err := f.Geometry()
+
+		if err != nil {
+			log.Printf("%s %s\n", path, err)
+			continue
+		}
+
+		problems := geojson.ValidateGeometry(geom)
+
+		if len(problems) == 0 {
+			fmt.Printf("%s OK\n", path)
+			continue
+		}
+
+		for _, p := range problems {
+			fmt.Printf("%s %s\n", path, p)
+		}
+
+		repaired, remaining := geojson.RepairGeometry(geom)
+
+		fmt.Printf("%s %d problems before repair, %d after\n", path, len(problems), len(remaining))
+
+		for _, p := range remaining {
+			fmt.Printf("%s could not fix %s\n", path, p)
+		}
+
+		if !*write || len(remaining) >= len(problems) {
+			continue
+		}
+
+		err = f.SetGeometry(repaired)
+
+		if err != nil {
+			log.Printf("%s %s\n", path, err)
+			continue
+		}
+
+		err = geojson.WriteFile(path, f)
+
+		if err != nil {
+			log.Printf("failed to write %s, because %s\n", path, err)
+			continue
+		}
+
+		fmt.Printf("%s updated\n", path)
+	}
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	geojson "github.com/whosonfirst/go-whosonfirst-geojson"
+	"log"
+	"os"
+)
+
+func main() {
+
+	var check = flag.Bool("check", false, "Compare the computed hash with the stored geom:hash and wof:geomhash properties and report the ones that are stale")
+	var rewrite = flag.Bool("rewrite", false, "Replace stale (or missing) geom:hash properties with the computed hash")
+
+	flag.Parse()
+	args := flag.Args()
+
+	stale := 0
+
+	for _, path := range args {
+
+		f, parse_err := geojson.UnmarshalFile(path)
+
+		if parse_err != nil {
+			log.Printf("%s %s\n", path, parse_err)
+			continue
+		}
+
+		computed, err := f.GeometryHash()
+
+		if err != nil {
+			log.Printf("%s %s\n", path, err)
+			continue
+		}
+
+		if !*check && !*rewrite {
+			fmt.Printf("%s %s\n", path, computed)
+			continue
+		}
+
+		// older records may have a wof:geomhash as well as (or instead of) a geom:hash and
+		// either one of them may be the stale one
+
+		is_stale := false
+		has_hash := false
+
+		for _, prop := range []string{"geom:hash", "wof:geomhash"} {
+
+			if !f.Body().Exists("properties", prop) {
+				continue
+			}
+
+			has_hash = true
+			stored, _ := f.StringProperty(prop)
+
+			if stored != computed {
+				fmt.Printf("%s %s stored %s computed %s\n", path, prop, stored, computed)
+				is_stale = true
+			}
+		}
+
+		if !has_hash {
+			fmt.Printf("%s missing computed %s\n", path, computed)
+			is_stale = true
+		}
+
+		if !is_stale {
+			continue
+		}
+
+		stale += 1
+
+		if !*rewrite {
+			continue
+		}
+
+		err = f.SetProperty("geom:hash", computed)
+
+		if err == nil && f.Body().Exists("properties", "wof:geomhash") {
+			err = f.SetProperty("wof:geomhash", computed)
+		}
+
+		if err != nil {
+			log.Printf("%s %s\n", path, err)
+			continue
+		}
+
+		err = geojson.WriteFile(path, f)
+
+		if err != nil {
+			log.Printf("failed to write %s, because %s\n", path, err)
+			continue
+		}
+
+		fmt.Printf("%s updated\n", path)
+	}
+
+	if stale > 0 && !*rewrite {
+		os.Exit(1)
+	}
+}
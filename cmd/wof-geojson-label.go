@@ -0,0 +1,61 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	geojson "github.com/whosonfirst/go-whosonfirst-geojson"
+	"log"
+)
+
+func main() {
+
+	var precision = flag.Float64("precision", 0.0001, "How close (in degrees) to the true pole of inaccessibility the label point needs to be")
+	var write = flag.Bool("write", false, "Write the label point to the lbl:latitude and lbl:longitude properties (the default is to just print it)")
+
+	flag.Parse()
+	args := flag.Args()
+
+	if !(*precision > 0.0) {
+		log.Fatal(fmt.Sprintf("Invalid -precision '%v', it must be greater than zero", *precision))
+	}
+
+	for _, path := range args {
+
+		f, parse_err := geojson.UnmarshalFile(path)
+
+		if parse_err != nil {
+			log.Printf("%s %s\n", path, parse_err)
+			continue
+		}
+
+		pt, err := f.LabelPoint(*precision)
+
+		if err != nil {
+			log.Printf("%s %s\n", path, err)
+			continue
+		}
+
+		fmt.Printf("%s %f,%f\n", path, pt.Lat(), pt.Lng())
+
+		if !*write {
+			continue
+		}
+
+		err = f.SetProperty("lbl:latitude", pt.Lat())
+
+		if err == nil {
+			err = f.SetProperty("lbl:longitude", pt.Lng())
+		}
+
+		if err != nil {
+			log.Printf("%s %s\n", path, err)
+			continue
+		}
+
+		err = geojson.WriteFile(path, f)
+
+		if err != nil {
+			log.Printf("failed to write %s, because %s\n", path, err)
+		}
+	}
+}
@@ -0,0 +1,240 @@
+package main
+
+// apply a JSON patch (RFC 6902) to one or more WOF records - or at least the parts of it
+// that make sense for WOF records:
+//
+//	add, replace, remove	/properties/{prop}
+//	add			/properties/{prop}/-	(append to a list)
+//	add, replace		/geometry
+//	test			/properties/{prop} or /geometry
+//
+// all changes go through the SetProperty (etc.) methods so wof:lastmodified and the geom:
+// properties take care of themselves. as per the RFC if any operation fails (including a
+// test) none of them are applied to that record.
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	geojson "github.com/whosonfirst/go-whosonfirst-geojson"
+	"io/ioutil"
+	"log"
+	"reflect"
+	"strings"
+)
+
+type Operation struct {
+	Op    string      `json:"op"`
+	Path  string      `json:"path"`
+	Value interface{} `json:"value"`
+}
+
+func main() {
+
+	var patch = flag.String("patch", "", "The path to a JSON patch file (a list of operations)")
+	var dryrun = flag.Bool("dryrun", false, "Report what would be changed but don't write anything")
+
+	flag.Parse()
+	args := flag.Args()
+
+	if *patch == "" {
+		log.Fatal("Missing -patch file")
+	}
+
+	body, err := ioutil.ReadFile(*patch)
+
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	var ops []*Operation
+
+	err = json.Unmarshal(body, &ops)
+
+	if err != nil {
+		log.Fatalf("Failed to parse %s, because %s", *patch, err)
+	}
+
+	for _, path := range args {
+
+		f, parse_err := geojson.UnmarshalFile(path)
+
+		if parse_err != nil {
+			log.Printf("%s %s\n", path, parse_err)
+			continue
+		}
+
+		ok := true
+
+		for i, op := range ops {
+
+			err := apply(f, op)
+
+			if err != nil {
+				log.Printf("%s operation %d (%s %s) failed, because %s\n", path, i, op.Op, op.Path, err)
+				ok = false
+				break
+			}
+		}
+
+		if !ok {
+			continue
+		}
+
+		if *dryrun {
+			fmt.Printf("%s would be updated\n", path)
+			continue
+		}
+
+		err = geojson.WriteFile(path, f)
+
+		if err != nil {
+			log.Printf("failed to write %s, because %s\n", path, err)
+			continue
+		}
+
+		fmt.Printf("%s updated\n", path)
+	}
+}
+
+func apply(f *geojson.WOFFeature, op *Operation) error {
+
+	parts := strings.Split(op.Path, "/")
+
+	if len(parts) < 2 || parts[0] != "" {
+		return errors.New("invalid path")
+	}
+
+	parts = parts[1:]
+
+	for i, p := range parts {
+		parts[i] = strings.Replace(strings.Replace(p, "~1", "/", -1), "~0", "~", -1)
+	}
+
+	if len(parts) == 1 && parts[0] == "geometry" {
+		return applyGeometry(f, op)
+	}
+
+	if parts[0] != "properties" || len(parts) < 2 || len(parts) > 3 {
+		return errors.New("unsupported path")
+	}
+
+	prop := parts[1]
+	exists := f.Body().Exists("properties", prop)
+
+	if len(parts) == 3 {
+
+		if parts[2] != "-" || op.Op != "add" {
+			return errors.New("unsupported path")
+		}
+
+		return f.AppendToList(prop, op.Value)
+	}
+
+	switch op.Op {
+
+	case "add":
+		return f.SetProperty(prop, op.Value)
+
+	case "replace":
+
+		if !exists {
+			return errors.New("property does not exist")
+		}
+
+		return f.SetProperty(prop, op.Value)
+
+	case "remove":
+
+		if !exists {
+			return errors.New("property does not exist")
+		}
+
+		return f.RemoveProperty(prop)
+
+	case "test":
+
+		if !exists || !equal(f.Body().Search("properties", prop).Data(), op.Value) {
+			return errors.New("test failed")
+		}
+
+		return nil
+
+	default:
+		return fmt.Errorf("unsupported operation '%s'", op.Op)
+	}
+}
+
+func applyGeometry(f *geojson.WOFFeature, op *Operation) error {
+
+	switch op.Op {
+
+	case "add", "replace":
+
+		geom, err := geojson.DecodeGeometry(op.Value)
+
+		if err != nil {
+			return err
+		}
+
+		return f.SetGeometry(geom)
+
+	case "test":
+
+		if !equal(f.Body().Search("geometry").Data(), op.Value) {
+			return errors.New("test failed")
+		}
+
+		return nil
+
+	default:
+		return fmt.Errorf("unsupported operation '%s' for geometry", op.Op)
+	}
+}
+
+// the feature's numbers are json.Number (so they can be written back out as they were read)
+// and the patch's are float64 so they are all turned in to float64 before comparing
+
+func equal(a interface{}, b interface{}) bool {
+	return reflect.DeepEqual(toFloats(a), toFloats(b))
+}
+
+func toFloats(v interface{}) interface{} {
+
+	switch value := v.(type) {
+
+	case json.Number:
+
+		f, err := value.Float64()
+
+		if err != nil {
+			return v
+		}
+
+		return f
+
+	case []interface{}:
+
+		list := make([]interface{}, len(value))
+
+		for i, item := range value {
+			list[i] = toFloats(item)
+		}
+
+		return list
+
+	case map[string]interface{}:
+
+		dict := make(map[string]interface{})
+
+		for k, item := range value {
+			dict[k] = toFloats(item)
+		}
+
+		return dict
+
+	default:
+		return v
+	}
+}
@@ -0,0 +1,256 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"expvar"
+	"flag"
+	"fmt"
+	rtreego "github.com/dhconnelly/rtreego"
+	geojson "github.com/whosonfirst/go-whosonfirst-geojson"
+	"github.com/whosonfirst/go-whosonfirst-geojson/index"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"runtime"
+	"strconv"
+	"syscall"
+	"time"
+)
+
+type Place struct {
+	Id        int    `json:"wof:id"`
+	Name      string `json:"wof:name"`
+	Placetype string `json:"wof:placetype"`
+}
+
+var requests = expvar.NewMap("requests")
+var failures = expvar.NewMap("errors")
+var timings = expvar.NewMap("timings_ms")
+var features = expvar.NewInt("features")
+var started = expvar.NewString("started")
+
+func parseLatLon(req *http.Request) (float64, float64, error) {
+
+	query := req.URL.Query()
+
+	lat, err := strconv.ParseFloat(query.Get("latitude"), 64)
+
+	if err != nil || lat < -90.0 || lat > 90.0 {
+		return 0.0, 0.0, fmt.Errorf("Invalid latitude '%s'", query.Get("latitude"))
+	}
+
+	lon, err := strconv.ParseFloat(query.Get("longitude"), 64)
+
+	if err != nil || lon < -180.0 || lon > 180.0 {
+		return 0.0, 0.0, fmt.Errorf("Invalid longitude '%s'", query.Get("longitude"))
+	}
+
+	return lat, lon, nil
+}
+
+func writeJSON(rsp http.ResponseWriter, body interface{}) {
+
+	enc, err := json.Marshal(body)
+
+	if err != nil {
+		http.Error(rsp, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	rsp.Header().Set("Content-Type", "application/json")
+	rsp.Header().Set("Access-Control-Allow-Origin", "*")
+	rsp.Write(enc)
+}
+
+// wrap a handler so that we count requests, errors (anything that isn't a 200) and how long
+// things take, all of which are published (as JSON) by the /metrics endpoint
+
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func instrument(name string, handler http.HandlerFunc) http.HandlerFunc {
+
+	return func(rsp http.ResponseWriter, req *http.Request) {
+
+		t1 := time.Now()
+
+		rec := &statusRecorder{rsp, http.StatusOK}
+		handler(rec, req)
+
+		requests.Add(name, 1)
+		timings.Add(name, int64(time.Since(t1)/time.Millisecond))
+
+		if rec.status != http.StatusOK {
+			failures.Add(name, 1)
+		}
+	}
+}
+
+func main() {
+
+	var source = flag.String("source", "", "Where to look for files")
+	var host = flag.String("host", "localhost", "The hostname to listen for requests on")
+	var port = flag.Int("port", 8080, "The port number to listen for requests on")
+	var procs = flag.Int("processes", runtime.NumCPU()*2, "Number of concurrent processes to use when indexing")
+	var timeout = flag.Duration("shutdown-timeout", 10*time.Second, "How long to wait for in-flight requests to finish when shutting down")
+
+	flag.Parse()
+
+	info, err := os.Stat(*source)
+
+	if err != nil || !info.IsDir() {
+		log.Fatal(fmt.Sprintf("Invalid -source directory '%s'", *source))
+	}
+
+	idx := index.NewIndex()
+
+	t1 := time.Now()
+
+	// better not to start at all than to serve answers from half an index; the crawler
+	// can't tell us whether it saw everything so let IndexDirectory do the walking
+
+	err = idx.IndexDirectory(*source, *procs)
+
+	if err != nil {
+
+		walk_err, ok := err.(*geojson.WalkError)
+
+		if ok {
+
+			for _, e := range walk_err.Errors {
+				log.Println(e)
+			}
+		}
+
+		log.Fatal(fmt.Sprintf("refusing to start, because %s", err))
+	}
+
+	features.Set(int64(idx.Size()))
+	started.Set(time.Now().Format(time.RFC3339))
+
+	log.Printf("indexed %d features in %v\n", idx.Size(), time.Since(t1))
+
+	pip := func(rsp http.ResponseWriter, req *http.Request) {
+
+		if req.URL.Path != "/" {
+			http.NotFound(rsp, req)
+			return
+		}
+
+		lat, lon, err := parseLatLon(req)
+
+		if err != nil {
+			http.Error(rsp, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		filters := make([]rtreego.Filter, 0)
+
+		pt := req.URL.Query().Get("placetype")
+
+		if pt != "" {
+
+			if !geojson.IsValidPlacetype(pt) {
+				http.Error(rsp, fmt.Sprintf("Invalid placetype '%s'", pt), http.StatusBadRequest)
+				return
+			}
+
+			filters = append(filters, index.PlacetypeFilter(pt))
+		}
+
+		results := idx.GetByLatLon(lat, lon, filters...)
+
+		seen := make(map[int]bool)
+		places := make([]Place, 0)
+
+		for _, sp := range results {
+
+			if seen[sp.Id] {
+				continue
+			}
+
+			seen[sp.Id] = true
+			places = append(places, Place{sp.Id, sp.Name, sp.Placetype})
+		}
+
+		writeJSON(rsp, places)
+	}
+
+	hierarchy := func(rsp http.ResponseWriter, req *http.Request) {
+
+		lat, lon, err := parseLatLon(req)
+
+		if err != nil {
+			http.Error(rsp, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		writeJSON(rsp, idx.GetHierarchyByLatLon(lat, lon))
+	}
+
+	health := func(rsp http.ResponseWriter, req *http.Request) {
+
+		status := map[string]interface{}{
+			"status":   "ok",
+			"features": idx.Size(),
+		}
+
+		writeJSON(rsp, status)
+	}
+
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("/", instrument("pip", pip))
+	mux.HandleFunc("/hierarchy", instrument("hierarchy", hierarchy))
+	mux.HandleFunc("/health", health)
+	mux.Handle("/metrics", expvar.Handler())
+
+	address := fmt.Sprintf("%s:%d", *host, *port)
+
+	server := &http.Server{
+		Addr:    address,
+		Handler: mux,
+	}
+
+	done := make(chan bool)
+
+	go func() {
+
+		signals := make(chan os.Signal, 1)
+		signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+
+		<-signals
+
+		log.Println("shutting down")
+
+		ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+		defer cancel()
+
+		err := server.Shutdown(ctx)
+
+		if err != nil {
+			log.Printf("failed to shut down cleanly, because %s\n", err)
+		}
+
+		close(done)
+	}()
+
+	log.Printf("listening on %s\n", address)
+
+	err = server.ListenAndServe()
+
+	if err != http.ErrServerClosed {
+		log.Fatal(err)
+	}
+
+	<-done
+}
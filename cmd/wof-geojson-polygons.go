@@ -4,6 +4,7 @@ import (
 	"flag"
 	"fmt"
 	geojson "github.com/whosonfirst/go-whosonfirst-geojson"
+	"log"
 )
 
 func main() {
@@ -19,7 +20,12 @@ func main() {
 			panic(parse_err)
 		}
 
-		polys := f.GeomToPolygons()
+		polys, err := f.GeomToPolygonsWithError()
+
+		if err != nil {
+			log.Printf("%s %s\n", path, err)
+			continue
+		}
 
 		for _, p := range polys {
 			fmt.Printf("%d points\n", len(p.OuterRing.Points()))
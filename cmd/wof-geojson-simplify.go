@@ -0,0 +1,76 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	geojson "github.com/whosonfirst/go-whosonfirst-geojson"
+	"log"
+	"os"
+	"path/filepath"
+)
+
+func main() {
+
+	var algorithm = flag.String("algorithm", geojson.DouglasPeucker, "The simplification algorithm to use: douglas-peucker or visvalingam-whyatt")
+	var tolerance = flag.Float64("tolerance", 0.001, "The simplification tolerance, in degrees (or meters if -meters is set)")
+	var meters = flag.Bool("meters", false, "The tolerance is in meters rather than degrees")
+	var out = flag.String("out", "", "The directory to write simplified copies of each file to")
+
+	flag.Parse()
+	args := flag.Args()
+
+	info, err := os.Stat(*out)
+
+	if err != nil || !info.IsDir() {
+		log.Fatal(fmt.Sprintf("Invalid -out directory '%s'", *out))
+	}
+
+	opts := geojson.SimplifyOptions{
+		Algorithm: *algorithm,
+		Tolerance: *tolerance,
+		Meters:    *meters,
+	}
+
+	for _, path := range args {
+
+		f, parse_err := geojson.UnmarshalFile(path)
+
+		if parse_err != nil {
+			log.Printf("%s %s\n", path, parse_err)
+			continue
+		}
+
+		geom, err := f.Geometry()
+
+		if err != nil {
+			log.Printf("%s %s\n", path, err)
+			continue
+		}
+
+		simplified, err := geojson.SimplifyGeometry(geom, &opts)
+
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		// SetGeometry also takes care of the bbox and the geom: properties
+
+		err = f.SetGeometry(simplified)
+
+		if err != nil {
+			log.Printf("%s %s\n", path, err)
+			continue
+		}
+
+		simplified_path := filepath.Join(*out, filepath.Base(path))
+
+		err = geojson.WriteFile(simplified_path, f)
+
+		if err != nil {
+			log.Printf("failed to write %s, because %s\n", simplified_path, err)
+			continue
+		}
+
+		fmt.Printf("%s %d points, %s %d points\n", path, geojson.CountPositions(geom), simplified_path, geojson.CountPositions(simplified))
+	}
+}
@@ -1,58 +1,186 @@
 package main
 
 import (
+	"encoding/json"
 	"flag"
 	"fmt"
-	crawl "github.com/whosonfirst/go-whosonfirst-crawl"
 	geojson "github.com/whosonfirst/go-whosonfirst-geojson"
+	"github.com/whosonfirst/go-whosonfirst-geojson/validator"
+	"log"
 	"os"
 	"runtime"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
 func main() {
 
 	var source = flag.String("source", "", "Where to look for files")
-	var procs = flag.Int("processes", runtime.NumCPU()*2, "Number of concurrent processes to use")
+	var procs = flag.Int("processes", runtime.NumCPU()*2, "Number of files to validate concurrently")
+	var rules = flag.String("rules", "", "A comma-separated list of rules to validate with (the default is all of them)")
+	var format = flag.String("format", "text", "The format of the validation report: text or json")
+	var errors_path = flag.String("errors", "", "Write each file that fails validation (as a line of JSON) to this file")
+	var fail_fast = flag.Bool("fail-fast", false, "Stop as soon as a file fails validation")
+	var progress = flag.Duration("progress", 0, "Report progress (to STDERR) this often; 0 means never")
 
 	flag.Parse()
 
-	runtime.GOMAXPROCS(*procs)
+	info, err := os.Stat(*source)
 
-	// TO DO: ensure *source exists and is a directory
+	if err != nil || !info.IsDir() {
+		log.Fatal(fmt.Sprintf("Invalid -source directory '%s'", *source))
+	}
 
-	t1 := time.Now()
-	count := 0
+	if *format != "text" && *format != "json" {
+		log.Fatal("Invalid -format")
+	}
 
-	wg := new(sync.WaitGroup)
+	if *procs < 1 {
+		log.Fatal("Invalid -processes")
+	}
 
-	callback := func(source string, info os.FileInfo) error {
+	v := validator.NewValidator()
 
-		wg.Add(1)
-		defer wg.Done()
+	if *rules != "" {
 
-		if info.IsDir() {
-			return nil
+		r, err := validator.RulesByName(strings.Split(*rules, ",")...)
+
+		if err != nil {
+			log.Fatal(err)
 		}
 
-		count += 1
+		v = validator.NewValidator(r...)
+	}
+
+	var errors_fh *os.File
 
-		_, err := geojson.UnmarshalFile(source)
+	if *errors_path != "" {
+
+		fh, err := os.Create(*errors_path)
 
 		if err != nil {
-			fmt.Println(source, err)
+			log.Fatal(err)
+		}
+
+		errors_fh = fh
+	}
+
+	report := validator.NewReport()
+
+	var count int64
+	var failed int64
+	var stopped int32
+
+	t1 := time.Now()
+
+	results := make(chan *validator.Result)
+
+	// there is only ever one goroutine touching the report and the errors file so
+	// neither of them needs a lock
+
+	collector := new(sync.WaitGroup)
+	collector.Add(1)
+
+	go func() {
+
+		defer collector.Done()
+
+		enc := json.NewEncoder(errors_fh)
+
+		for result := range results {
+
+			atomic.AddInt64(&count, 1)
+			report.Add(result)
+
+			if result.OK() {
+				continue
+			}
+
+			atomic.AddInt64(&failed, 1)
+
+			if errors_fh != nil {
+
+				err := enc.Encode(result)
+
+				if err != nil {
+					log.Printf("failed to write error for %s, because %s\n", result.Path, err)
+				}
+			}
+
+			if *fail_fast {
+				atomic.StoreInt32(&stopped, 1)
+			}
+		}
+	}()
+
+	if *progress > 0 {
+
+		ticker := time.NewTicker(*progress)
+		defer ticker.Stop()
+
+		go func() {
+
+			for range ticker.C {
+				log.Printf("validated %d files (%d failed) in %v\n", atomic.LoadInt64(&count), atomic.LoadInt64(&failed), time.Since(t1))
+			}
+		}()
+	}
+
+	// WalkFeatures doesn't have a way to stop so we just ignore everything from here on
+	// out; unlike the crawler it does tell us if it couldn't see everything
+
+	callback := func(path string) error {
+
+		if atomic.LoadInt32(&stopped) == 1 {
+			return nil
 		}
 
+		results <- v.ValidateFile(path)
 		return nil
 	}
 
-	c := crawl.NewCrawler(*source)
-	_ = c.Crawl(callback)
+	walk_err := geojson.WalkFeatures(*source, *procs, callback)
+
+	close(results)
+	collector.Wait()
 
-	wg.Wait()
+	if errors_fh != nil {
+		errors_fh.Close()
+	}
 
 	t2 := time.Since(t1)
 
-	fmt.Printf("time to validate %d files: %v\n", count, t2)
+	if *format == "json" {
+		report.WriteJSON(os.Stdout)
+	} else {
+		report.WriteText(os.Stdout)
+		fmt.Printf("time to validate %d files: %v\n", count, t2)
+	}
+
+	if atomic.LoadInt32(&stopped) == 1 {
+		log.Println("stopped after the first failure (-fail-fast)")
+	}
+
+	// whatever we did validate is still worth reporting but the report isn't complete
+
+	if walk_err != nil {
+
+		e, ok := walk_err.(*geojson.WalkError)
+
+		if ok {
+
+			for _, err := range e.Errors {
+				log.Println(err)
+			}
+		}
+
+		log.Printf("failed to validate everything in %s, because %s\n", *source, walk_err)
+		os.Exit(1)
+	}
+
+	if failed > 0 {
+		os.Exit(1)
+	}
 }
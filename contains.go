@@ -0,0 +1,249 @@
+package geojson
+
+import (
+	"context"
+	geo "github.com/kellydunn/golang-geo"
+	"math"
+	"sync"
+	"sync/atomic"
+)
+
+/*
+
+Containment used to start a goroutine per polygon and per interior ring, all of which wrote to
+the same (unguarded) boolean and none of which could tell the others to stop. Now:
+
+- a polygon checks its outer ring and then each interior ring in turn, stopping as soon as the
+  answer is known (outside the outer ring or inside any hole)
+- a feature hands its polygons to a fixed number of workers and cancels the rest as soon as one
+  of them contains the point
+- the raycasting itself checks the context every so often so that very large rings can be
+  abandoned part way through
+- polygons that cross the antimeridian are tested using shifted longitudes (see antimeridian.go)
+
+The raycasting is the same algorithm that golang-geo uses (geo.Polygon.Contains), including its
+IsClosed check (a ring with fewer than three points contains nothing), so the answers are the
+same; we just need to be able to interrupt it.
+
+*/
+
+// how many edges to test between checks of ctx.Done()
+
+const containsCheckInterval = 1024
+
+// ContainsWithContext is the same as Contains but returns ctx.Err() if the context is cancelled
+// before an answer has been reached
+
+func (p *WOFPolygon) ContainsWithContext(ctx context.Context, latitude float64, longitude float64) (bool, error) {
+
+	rings, shifted := shiftedPolygonRings(p)
+
+	if shifted {
+		longitude = shiftLongitude(longitude)
+	}
+
+	pt := geo.NewPoint(latitude, longitude)
+
+	contains, err := ringContains(ctx, rings[0], pt)
+
+	if err != nil || !contains {
+		return false, err
+	}
+
+	for _, points := range rings[1:] {
+
+		in_hole, err := ringContains(ctx, points, pt)
+
+		if err != nil {
+			return false, err
+		}
+
+		if in_hole {
+			return false, nil
+		}
+	}
+
+	return true, nil
+}
+
+// ContainsWithContext tests each of the feature's simple geometries (see Flatten) using at most
+// workers goroutines (if workers is less than 2 they are tested one after the other in the
+// current goroutine). As soon as one of them contains the point the others are cancelled. If ctx
+// is cancelled before an answer has been reached ctx.Err() is returned.
+
+func (wof WOFFeature) ContainsWithContext(ctx context.Context, latitude float64, longitude float64, workers int) (bool, error) {
+
+	geom, err := wof.Geometry()
+
+	if err != nil {
+		return false, err
+	}
+
+	parts := Flatten(geom)
+
+	if workers < 2 || len(parts) < 2 {
+
+		for _, part := range parts {
+
+			contains, err := geometryContains(ctx, part, latitude, longitude)
+
+			if err != nil {
+				return false, err
+			}
+
+			if contains {
+				return true, nil
+			}
+		}
+
+		return false, nil
+	}
+
+	if workers > len(parts) {
+		workers = len(parts)
+	}
+
+	work_ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	queue := make(chan Geometry)
+	var found int32
+
+	wg := new(sync.WaitGroup)
+
+	for i := 0; i < workers; i++ {
+
+		wg.Add(1)
+
+		go func() {
+
+			defer wg.Done()
+
+			for part := range queue {
+
+				contains, err := geometryContains(work_ctx, part, latitude, longitude)
+
+				if err == nil && contains {
+					atomic.StoreInt32(&found, 1)
+					cancel()
+				}
+			}
+		}()
+	}
+
+feed:
+	for _, part := range parts {
+
+		select {
+		case queue <- part:
+			// pass
+		case <-work_ctx.Done():
+			break feed
+		}
+	}
+
+	close(queue)
+	wg.Wait()
+
+	if atomic.LoadInt32(&found) == 1 {
+		return true, nil
+	}
+
+	return false, ctx.Err()
+}
+
+func geometryContains(ctx context.Context, g Geometry, latitude float64, longitude float64) (bool, error) {
+
+	select {
+	case <-ctx.Done():
+		return false, ctx.Err()
+	default:
+		// pass
+	}
+
+	poly, ok := g.(*Polygon)
+
+	if ok {
+		return poly.ContainsWithContext(ctx, latitude, longitude)
+	}
+
+	return g.Contains(latitude, longitude), nil
+}
+
+func ringContains(ctx context.Context, points []*geo.Point, pt *geo.Point) (bool, error) {
+
+	count := len(points)
+
+	// this is geo.Polygon.IsClosed
+
+	if count < 3 {
+		return false, nil
+	}
+
+	contains := intersectsWithRaycast(pt, points[count-1], points[0])
+
+	for i := 1; i < count; i++ {
+
+		if i%containsCheckInterval == 0 {
+
+			select {
+			case <-ctx.Done():
+				return false, ctx.Err()
+			default:
+				// pass
+			}
+		}
+
+		if intersectsWithRaycast(pt, points[i-1], points[i]) {
+			contains = !contains
+		}
+	}
+
+	return contains, nil
+}
+
+// this is geo.Polygon.intersectsWithRaycast which isn't exported
+
+func intersectsWithRaycast(point *geo.Point, start *geo.Point, end *geo.Point) bool {
+
+	if start.Lng() > end.Lng() {
+		start, end = end, start
+	}
+
+	lat := point.Lat()
+	lng := point.Lng()
+
+	for lng == start.Lng() || lng == end.Lng() {
+		lng = math.Nextafter(lng, math.Inf(1))
+	}
+
+	if lng < start.Lng() || lng > end.Lng() {
+		return false
+	}
+
+	if start.Lat() > end.Lat() {
+
+		if lat > start.Lat() {
+			return false
+		}
+
+		if lat < end.Lat() {
+			return true
+		}
+
+	} else {
+
+		if lat > end.Lat() {
+			return false
+		}
+
+		if lat < start.Lat() {
+			return true
+		}
+	}
+
+	ray_slope := (lng - start.Lng()) / (lat - start.Lat())
+	diag_slope := (end.Lng() - start.Lng()) / (end.Lat() - start.Lat())
+
+	return ray_slope >= diag_slope
+}
@@ -0,0 +1,206 @@
+package geojson
+
+import (
+	"context"
+	"encoding/json"
+	geo "github.com/kellydunn/golang-geo"
+	"math"
+	"math/rand"
+	"testing"
+)
+
+// a MultiPolygon with polygons spiky enough that every band has edges in it, each with a
+// hole in the middle
+
+func largeMultiPolygon(t testing.TB, polygons int, vertices int) *WOFFeature {
+
+	r := rand.New(rand.NewSource(1))
+	coords := make([]interface{}, 0)
+
+	ring := func(cx float64, cy float64, radius float64, jitter float64, clockwise bool) []interface{} {
+
+		positions := make([]interface{}, 0)
+
+		for i := 0; i < vertices; i++ {
+
+			angle := 2.0 * math.Pi * float64(i) / float64(vertices)
+
+			if clockwise {
+				angle = -angle
+			}
+
+			d := radius * (1.0 - jitter*r.Float64())
+			positions = append(positions, []interface{}{cx + d*math.Cos(angle), cy + d*math.Sin(angle)})
+		}
+
+		return append(positions, positions[0])
+	}
+
+	for i := 0; i < polygons; i++ {
+
+		cx := -100.0 + float64(i)*3.0
+		cy := 45.0
+
+		outer := ring(cx, cy, 1.4, 0.3, false)
+		hole := ring(cx, cy, 0.5, 0.1, true)
+
+		coords = append(coords, []interface{}{outer, hole})
+	}
+
+	feature := map[string]interface{}{
+		"type": "Feature",
+		"properties": map[string]interface{}{
+			"wof:id": 1,
+		},
+		"geometry": map[string]interface{}{
+			"type":        "MultiPolygon",
+			"coordinates": coords,
+		},
+	}
+
+	body, err := json.Marshal(feature)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	f, err := UnmarshalFeature(body)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return f
+}
+
+type testPoint struct {
+	lat float64
+	lon float64
+}
+
+func randomPoints(f *WOFFeature, count int) []testPoint {
+
+	r := rand.New(rand.NewSource(2))
+
+	swlat, swlon := math.Inf(1), math.Inf(1)
+	nelat, nelon := math.Inf(-1), math.Inf(-1)
+
+	for _, p := range f.GeomToPolygons() {
+
+		for _, pt := range p.OuterRing.Points() {
+			swlat = math.Min(swlat, pt.Lat())
+			swlon = math.Min(swlon, pt.Lng())
+			nelat = math.Max(nelat, pt.Lat())
+			nelon = math.Max(nelon, pt.Lng())
+		}
+	}
+
+	points := make([]testPoint, count)
+
+	for i := range points {
+		points[i].lat = swlat + r.Float64()*(nelat-swlat)
+		points[i].lon = swlon + r.Float64()*(nelon-swlon)
+	}
+
+	return points
+}
+
+func TestContainsWithContextWorkers(t *testing.T) {
+
+	f := largeMultiPolygon(t, 6, 200)
+	points := randomPoints(f, 500)
+
+	polygons := f.GeomToPolygons()
+
+	for _, workers := range []int{0, 1, 2, 4, 16} {
+
+		for _, pt := range points {
+
+			expected := false
+
+			for _, p := range polygons {
+
+				if p.Contains(pt.lat, pt.lon) {
+					expected = true
+					break
+				}
+			}
+
+			contains, err := f.ContainsWithContext(context.Background(), pt.lat, pt.lon, workers)
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if contains != expected {
+				t.Errorf("expected ContainsWithContext(%v, %v) with %d workers to be %t", pt.lat, pt.lon, workers, expected)
+			}
+		}
+	}
+}
+
+func TestContainsMatchesGolangGeo(t *testing.T) {
+
+	// no holes, so golang-geo's answer is the right one
+
+	f := largeMultiPolygon(t, 1, 300)
+	p := f.GeomToPolygons()[0]
+
+	outer := p.OuterRing
+	simple := WOFPolygon{OuterRing: outer}
+
+	for _, pt := range randomPoints(f, 1000) {
+
+		contains, err := simple.ContainsWithContext(context.Background(), pt.lat, pt.lon)
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if contains != outer.Contains(geo.NewPoint(pt.lat, pt.lon)) {
+			t.Errorf("expected ContainsWithContext(%v, %v) to agree with golang-geo", pt.lat, pt.lon)
+		}
+	}
+
+	// golang-geo doesn't think that anything with fewer than three points is a ring
+
+	for _, points := range [][]*geo.Point{{}, {geo.NewPoint(0, 0), geo.NewPoint(0, 2)}} {
+
+		ring := geo.NewPolygon(points)
+		short := WOFPolygon{OuterRing: *ring}
+
+		contains, err := short.ContainsWithContext(context.Background(), 0, 1)
+
+		if err != nil || contains != ring.Contains(geo.NewPoint(0, 1)) {
+			t.Errorf("expected a ring with %d points to agree with golang-geo, got %t %v", len(points), contains, err)
+		}
+	}
+}
+
+func TestContainsWithContextCancelled(t *testing.T) {
+
+	f := largeMultiPolygon(t, 4, 5000)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	// somewhere that isn't in any of the polygons, so that there's no answer before the
+	// context is noticed
+
+	for _, workers := range []int{1, 4} {
+
+		contains, err := f.ContainsWithContext(ctx, 0.0, 0.0, workers)
+
+		if contains || err != context.Canceled {
+			t.Errorf("expected a cancelled context to be reported with %d workers, got %t %v", workers, contains, err)
+		}
+	}
+
+	p := f.GeomToPolygons()[0]
+
+	_, err := p.ContainsWithContext(ctx, 45.0, -100.0)
+
+	if err != context.Canceled {
+		t.Errorf("expected a cancelled context to interrupt a large ring, got %v", err)
+	}
+}
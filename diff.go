@@ -0,0 +1,260 @@
+package geojson
+
+import (
+	"bytes"
+	"sort"
+)
+
+/*
+
+Diff compares two versions of a feature the way a person reviewing a change to a WOF record
+would want them compared, rather than line by line:
+
+- properties (and any other top-level keys besides geometry and bbox) are compared key by key,
+  all the way down through dictionaries, and reported as added, removed or changed by path
+  (using the same notation as DecodeError, for example properties.wof:name). Lists are
+  compared as a whole. Numbers are compared by value, so 1 and 1.0 are the same number, and
+  everything else the way MarshalFeature would write it out.
+- geometries are summarized: the change in the number of positions, in (geodesic) area and in
+  each edge of the bounding box, and whether the two geometries are topologically equal (each
+  covers the other) even if their coordinates aren't identical - say, because the rings start
+  at a different position or a redundant position has been dropped.
+
+*/
+
+const (
+	ChangeAdded   = "added"
+	ChangeRemoved = "removed"
+	ChangeChanged = "changed"
+)
+
+// the properties that DiffOptions.IgnoreNoise ignores: they change every time a record is
+// exported whether anything interesting has happened or not
+
+var NoiseProperties = []string{
+	"wof:lastmodified",
+}
+
+type DiffOptions struct {
+	IgnoreProperties []string // property names to ignore
+	IgnoreNoise      bool     // ignore NoiseProperties
+}
+
+type Change struct {
+	Type string // one of the Change... constants
+	Path string
+	Old  interface{} // nil for ChangeAdded; numbers are json.Number (see parseJSON)
+	New  interface{} // nil for ChangeRemoved
+}
+
+type GeometryDiff struct {
+	Changed            bool // the coordinates (or type) are not identical
+	OldType            string
+	NewType            string
+	PositionDelta      int        // new - old
+	AreaDelta          float64    // new - old, in square meters
+	BoundsDelta        [4]float64 // new - old, in degrees, in the same order as BoundingBox.Array
+	TopologicallyEqual bool
+}
+
+type FeatureDiff struct {
+	Changes  []*Change
+	Geometry *GeometryDiff
+}
+
+// IsEmpty reports whether the two features are the same, as far as Diff is concerned
+
+func (d *FeatureDiff) IsEmpty() bool {
+	return len(d.Changes) == 0 && !d.Geometry.Changed
+}
+
+// Diff compares two features without ignoring anything (see DiffWithOptions)
+
+func Diff(a *WOFFeature, b *WOFFeature) (*FeatureDiff, error) {
+	return DiffWithOptions(a, b, &DiffOptions{})
+}
+
+// DiffWithOptions reports how b differs from a. The error is only ever about decoding one of
+// the geometries.
+
+func DiffWithOptions(a *WOFFeature, b *WOFFeature, opts *DiffOptions) (*FeatureDiff, error) {
+
+	ignore := make(map[string]bool)
+
+	for _, prop := range opts.IgnoreProperties {
+		ignore["properties."+prop] = true
+	}
+
+	if opts.IgnoreNoise {
+
+		for _, prop := range NoiseProperties {
+			ignore["properties."+prop] = true
+		}
+	}
+
+	old_body, _ := a.Parsed.Data().(map[string]interface{})
+	new_body, _ := b.Parsed.Data().(map[string]interface{})
+
+	changes := make([]*Change, 0)
+
+	for _, k := range unionKeys(old_body, new_body) {
+
+		if k == "geometry" || k == "bbox" {
+			continue
+		}
+
+		changes = diffValues(changes, k, k, old_body, new_body, ignore)
+	}
+
+	geom_diff, err := diffGeometries(a, b)
+
+	if err != nil {
+		return nil, err
+	}
+
+	d := FeatureDiff{
+		Changes:  changes,
+		Geometry: geom_diff,
+	}
+
+	return &d, nil
+}
+
+// diffValues compares old_dict[key] and new_dict[key], where path is the path to key
+
+func diffValues(changes []*Change, path string, key string, old_dict map[string]interface{}, new_dict map[string]interface{}, ignore map[string]bool) []*Change {
+
+	if ignore[path] {
+		return changes
+	}
+
+	old_value, old_ok := old_dict[key]
+	new_value, new_ok := new_dict[key]
+
+	if !old_ok {
+		return append(changes, &Change{Type: ChangeAdded, Path: path, New: new_value})
+	}
+
+	if !new_ok {
+		return append(changes, &Change{Type: ChangeRemoved, Path: path, Old: old_value})
+	}
+
+	old_child, old_is_dict := old_value.(map[string]interface{})
+	new_child, new_is_dict := new_value.(map[string]interface{})
+
+	if old_is_dict && new_is_dict {
+
+		for _, k := range unionKeys(old_child, new_child) {
+			changes = diffValues(changes, path+"."+k, k, old_child, new_child, ignore)
+		}
+
+		return changes
+	}
+
+	if !sameValue(old_value, new_value, path) {
+		changes = append(changes, &Change{Type: ChangeChanged, Path: path, Old: old_value, New: new_value})
+	}
+
+	return changes
+}
+
+func diffGeometries(a *WOFFeature, b *WOFFeature) (*GeometryDiff, error) {
+
+	old_geom, err := a.Geometry()
+
+	if err != nil {
+		return nil, err
+	}
+
+	new_geom, err := b.Geometry()
+
+	if err != nil {
+		return nil, err
+	}
+
+	d := GeometryDiff{
+		OldType:       old_geom.Type(),
+		NewType:       new_geom.Type(),
+		PositionDelta: CountPositions(new_geom) - CountPositions(old_geom),
+	}
+
+	d.Changed = !sameValue(a.Parsed.S("geometry").Data(), b.Parsed.S("geometry").Data(), "geometry")
+
+	if !d.Changed {
+		d.TopologicallyEqual = true
+		return &d, nil
+	}
+
+	old_area, err := a.GeodesicArea()
+
+	if err != nil {
+		return nil, err
+	}
+
+	new_area, err := b.GeodesicArea()
+
+	if err != nil {
+		return nil, err
+	}
+
+	d.AreaDelta = new_area - old_area
+
+	old_bb := ComputeBounds(old_geom)
+	new_bb := ComputeBounds(new_geom)
+
+	if !old_bb.IsEmpty() && !new_bb.IsEmpty() {
+
+		old_array := old_bb.Array()
+		new_array := new_bb.Array()
+
+		for i := range d.BoundsDelta {
+			d.BoundsDelta[i] = new_array[i] - old_array[i]
+		}
+	}
+
+	if old_bb.IsEmpty() || new_bb.IsEmpty() {
+		d.TopologicallyEqual = old_bb.IsEmpty() && new_bb.IsEmpty()
+	} else {
+		d.TopologicallyEqual = Covers(old_geom, new_geom) && Covers(new_geom, old_geom)
+	}
+
+	return &d, nil
+}
+
+// two values are the same if MarshalFeature would write them out the same way once all their
+// numbers have been turned in to float64, so that 1 and 1.0 are the same
+
+func sameValue(a interface{}, b interface{}, path string) bool {
+
+	var buf_a bytes.Buffer
+	var buf_b bytes.Buffer
+
+	err_a := writeCompact(&buf_a, numberValues(a), path)
+	err_b := writeCompact(&buf_b, numberValues(b), path)
+
+	if err_a != nil || err_b != nil {
+		return false
+	}
+
+	return bytes.Equal(buf_a.Bytes(), buf_b.Bytes())
+}
+
+func unionKeys(a map[string]interface{}, b map[string]interface{}) []string {
+
+	seen := make(map[string]bool)
+	keys := make([]string, 0)
+
+	for _, dict := range []map[string]interface{}{a, b} {
+
+		for k := range dict {
+
+			if !seen[k] {
+				seen[k] = true
+				keys = append(keys, k)
+			}
+		}
+	}
+
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,183 @@
+package geojson
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestDiffProperties(t *testing.T) {
+
+	point := `{"type":"Point","coordinates":[0,0]}`
+
+	tests := []struct {
+		name     string
+		old      string
+		new      string
+		opts     *DiffOptions
+		expected []string // type and path
+	}{
+		{"same", `{"wof:name":"x","wof:id":1}`, `{"wof:id":1,"wof:name":"x"}`, &DiffOptions{}, []string{}},
+		{"integral floats", `{"wof:id":1}`, `{"wof:id":1.0}`, &DiffOptions{}, []string{}},
+		{"added", `{}`, `{"wof:name":"x"}`, &DiffOptions{}, []string{"added properties.wof:name"}},
+		{"removed", `{"wof:name":"x"}`, `{}`, &DiffOptions{}, []string{"removed properties.wof:name"}},
+		{"changed", `{"wof:name":"x","wof:id":1}`, `{"wof:name":"y","wof:id":2}`, &DiffOptions{}, []string{"changed properties.wof:id", "changed properties.wof:name"}},
+		{"changed type", `{"wof:id":1}`, `{"wof:id":"1"}`, &DiffOptions{}, []string{"changed properties.wof:id"}},
+		{"nested", `{"a":{"b":1,"c":2}}`, `{"a":{"b":1,"c":3,"d":4}}`, &DiffOptions{}, []string{"changed properties.a.c", "added properties.a.d"}},
+		{"dictionary to list", `{"a":{"b":1}}`, `{"a":[1]}`, &DiffOptions{}, []string{"changed properties.a"}},
+		{"lists", `{"wof:supersedes":[1,2]}`, `{"wof:supersedes":[2,1]}`, &DiffOptions{}, []string{"changed properties.wof:supersedes"}},
+		{"noise", `{"wof:lastmodified":1}`, `{"wof:lastmodified":2}`, &DiffOptions{}, []string{"changed properties.wof:lastmodified"}},
+		{"ignore noise", `{"wof:lastmodified":1}`, `{"wof:lastmodified":2}`, &DiffOptions{IgnoreNoise: true}, []string{}},
+		{"ignore", `{"wof:name":"x","wof:id":1}`, `{"wof:name":"y","wof:id":2}`, &DiffOptions{IgnoreProperties: []string{"wof:name"}}, []string{"changed properties.wof:id"}},
+	}
+
+	for _, test := range tests {
+
+		a := testFeature(t, `{"type":"Feature","properties":`+test.old+`,"geometry":`+point+`}`)
+		b := testFeature(t, `{"type":"Feature","properties":`+test.new+`,"geometry":`+point+`}`)
+
+		d, err := DiffWithOptions(a, b, test.opts)
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		changes := make([]string, 0)
+
+		for _, c := range d.Changes {
+			changes = append(changes, c.Type+" "+c.Path)
+		}
+
+		if !sameStrings(changes, test.expected) {
+			t.Errorf("%s: expected %v, got %v", test.name, test.expected, changes)
+		}
+
+		if d.IsEmpty() != (len(test.expected) == 0) {
+			t.Errorf("%s: expected IsEmpty to be %t", test.name, len(test.expected) == 0)
+		}
+	}
+}
+
+func TestDiffValues(t *testing.T) {
+
+	a := testFeature(t, `{"type":"Feature","id":1,"properties":{"wof:name":"x"},"bbox":[0,0,0,0],"geometry":null}`)
+	b := testFeature(t, `{"type":"Feature","id":2,"properties":{"wof:name":"y","wof:id":2},"bbox":[1,1,1,1],"geometry":null}`)
+
+	d, err := Diff(a, b)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the bbox is ignored, everything else at the top level isn't
+
+	if len(d.Changes) != 3 {
+		t.Fatalf("expected 3 changes, got %d", len(d.Changes))
+	}
+
+	id := d.Changes[0]
+
+	if id.Path != "id" || id.Old != json.Number("1") || id.New != json.Number("2") {
+		t.Errorf("expected id to change from 1 to 2, got %v", id)
+	}
+
+	added := d.Changes[1]
+
+	if added.Type != ChangeAdded || added.Path != "properties.wof:id" || added.Old != nil || added.New != json.Number("2") {
+		t.Errorf("expected wof:id to be added, got %v", added)
+	}
+
+	changed := d.Changes[2]
+
+	if changed.Old != "x" || changed.New != "y" {
+		t.Errorf("expected wof:name to change from x to y, got %v", changed)
+	}
+}
+
+func TestDiffGeometry(t *testing.T) {
+
+	tests := []struct {
+		name      string
+		old       string
+		new       string
+		changed   bool
+		equal     bool
+		positions int
+		bounds    [4]float64
+		grew      int // the sign of the area delta
+	}{
+		{"same", squareGeometry(0, 0, 2, 2), squareGeometry(0, 0, 2, 2), false, true, 0, [4]float64{}, 0},
+		{"integral floats", `{"type":"Point","coordinates":[1,2]}`, `{"type":"Point","coordinates":[1.0,2.0]}`, false, true, 0, [4]float64{}, 0},
+		{"different start", squareGeometry(0, 0, 2, 2), `{"type":"Polygon","coordinates":[[[2,0],[2,2],[0,2],[0,0],[2,0]]]}`, true, true, 0, [4]float64{}, 0},
+		{"redundant position", squareGeometry(0, 0, 2, 2), `{"type":"Polygon","coordinates":[[[0,0],[1,0],[2,0],[2,2],[0,2],[0,0]]]}`, true, true, 1, [4]float64{}, 0},
+		{"bigger", squareGeometry(0, 0, 2, 2), squareGeometry(0, 0, 3, 2), true, false, 0, [4]float64{0, 0, 1, 0}, 1},
+		{"smaller", squareGeometry(0, 0, 2, 2), squareGeometry(0.5, 0.5, 2, 2), true, false, 0, [4]float64{0.5, 0.5, 0, 0}, -1},
+		{"point to polygon", `{"type":"Point","coordinates":[0,0]}`, squareGeometry(0, 0, 2, 2), true, false, 4, [4]float64{0, 0, 2, 2}, 1},
+		{"null to null", `null`, `null`, false, true, 0, [4]float64{}, 0},
+		{"null to point", `null`, `{"type":"Point","coordinates":[0,0]}`, true, false, 1, [4]float64{}, 0},
+	}
+
+	for _, test := range tests {
+
+		a := geometryFeature(t, test.old)
+		b := geometryFeature(t, test.new)
+
+		d, err := Diff(a, b)
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		g := d.Geometry
+
+		if g.Changed != test.changed {
+			t.Errorf("%s: expected Changed to be %t", test.name, test.changed)
+		}
+
+		if g.TopologicallyEqual != test.equal {
+			t.Errorf("%s: expected TopologicallyEqual to be %t", test.name, test.equal)
+		}
+
+		if g.PositionDelta != test.positions {
+			t.Errorf("%s: expected a position delta of %d, got %d", test.name, test.positions, g.PositionDelta)
+		}
+
+		for i := range g.BoundsDelta {
+
+			if !almostEqual(g.BoundsDelta[i], test.bounds[i], 1e-9) {
+				t.Errorf("%s: expected a bounds delta of %v, got %v", test.name, test.bounds, g.BoundsDelta)
+				break
+			}
+		}
+
+		grew := 0
+
+		if g.AreaDelta > 1.0 {
+			grew = 1
+		} else if g.AreaDelta < -1.0 {
+			grew = -1
+		}
+
+		if grew != test.grew {
+			t.Errorf("%s: expected the area delta to have a sign of %d, got %v", test.name, test.grew, g.AreaDelta)
+		}
+
+		if d.IsEmpty() == test.changed {
+			t.Errorf("%s: expected IsEmpty to be %t", test.name, !test.changed)
+		}
+	}
+
+	a := geometryFeature(t, squareGeometry(0, 0, 2, 2))
+	b := geometryFeature(t, `{"type":"Polygon","coordinates":"nope"}`)
+
+	_, err := Diff(a, b)
+
+	if err == nil {
+		t.Error("expected Diff to fail for a broken geometry")
+	}
+
+	_, err = Diff(b, a)
+
+	if err == nil {
+		t.Error("expected Diff to fail for a broken geometry")
+	}
+}
@@ -0,0 +1,174 @@
+package geojson
+
+import (
+	geo "github.com/kellydunn/golang-geo"
+	"math"
+)
+
+/*
+
+Distances from a point to a geometry, in meters. A point inside a polygon (or on a line) is 0
+meters away from it; otherwise the distance is to the nearest edge (or vertex), measured along
+a great circle using the same (spherical) earth as golang-geo's GreatCircleDistance.
+
+Edges are treated as great circle arcs, which for the length of edges you find in WOF records is
+indistinguishable from the straight lines used everywhere else.
+
+*/
+
+// EarthRadius is the radius, in meters, of the spherical earth that golang-geo (and so every
+// great circle distance in this package) uses
+
+const EarthRadius = geo.EARTH_RADIUS * 1000.0
+
+// MetersPerDegree is the length, in meters, of a degree along a great circle on that earth
+
+const MetersPerDegree = EarthRadius * math.Pi / 180.0
+
+// DistanceTo returns the distance, in meters, from the point to the nearest part of the
+// feature's geometry (0 if the point is inside it)
+
+func (wof WOFFeature) DistanceTo(latitude float64, longitude float64) (float64, error) {
+
+	geom, err := wof.Geometry()
+
+	if err != nil {
+		return 0.0, err
+	}
+
+	return DistanceToGeometry(geom, latitude, longitude), nil
+}
+
+// DistanceAtOffset returns the distance, in meters, from the point to the simple geometry at
+// offset (see ContainsAtOffset) or -1 if there isn't one
+
+func (pf *PreparedFeature) DistanceAtOffset(offset int, latitude float64, longitude float64) float64 {
+
+	if offset < 0 || offset >= len(pf.parts) {
+		return -1.0
+	}
+
+	if pf.parts[offset].contains(latitude, longitude) {
+		return 0.0
+	}
+
+	return DistanceToGeometry(pf.geometries[offset], latitude, longitude)
+}
+
+// DistanceToGeometry returns the distance, in meters, from the point to the nearest part of g
+// (0 if the point is inside it). A geometry without any coordinates is infinitely far away.
+
+func DistanceToGeometry(g Geometry, latitude float64, longitude float64) float64 {
+
+	pt := geo.NewPoint(latitude, longitude)
+	distance := math.Inf(1)
+
+	for _, part := range Flatten(g) {
+
+		switch geom := part.(type) {
+
+		case *Point:
+			distance = math.Min(distance, pt.GreatCircleDistance(geom.Coordinate)*1000.0)
+
+		case *LineString:
+			distance = math.Min(distance, pathDistance(pt, geom.Coordinates, false))
+
+		case *Polygon:
+
+			if geom.Contains(latitude, longitude) {
+				return 0.0
+			}
+
+			for _, r := range polygonRings(geom.WOFPolygon) {
+				distance = math.Min(distance, pathDistance(pt, r, true))
+			}
+		}
+
+		if distance == 0.0 {
+			return 0.0
+		}
+	}
+
+	return distance
+}
+
+func pathDistance(pt *geo.Point, points []*geo.Point, closed bool) float64 {
+
+	count := len(points)
+
+	if count == 0 {
+		return math.Inf(1)
+	}
+
+	if count == 1 {
+		return pt.GreatCircleDistance(points[0]) * 1000.0
+	}
+
+	px, py, pz := pointToVector(pt)
+	distance := math.Inf(1)
+
+	for i := 1; i < count; i++ {
+		distance = math.Min(distance, arcDistance(px, py, pz, points[i-1], points[i]))
+	}
+
+	if closed && !samePoint(points[0], points[count-1]) {
+		distance = math.Min(distance, arcDistance(px, py, pz, points[count-1], points[0]))
+	}
+
+	return distance
+}
+
+// the distance from a point (as a unit vector) to the great circle arc between a and b: the
+// distance to the great circle if the nearest point on it falls between a and b, or to the
+// nearer of a and b if it doesn't
+
+func arcDistance(px float64, py float64, pz float64, a *geo.Point, b *geo.Point) float64 {
+
+	ax, ay, az := pointToVector(a)
+	bx, by, bz := pointToVector(b)
+
+	angle := func(x float64, y float64, z float64) float64 {
+
+		cross := math.Sqrt(math.Pow(py*z-pz*y, 2) + math.Pow(pz*x-px*z, 2) + math.Pow(px*y-py*x, 2))
+		dot := px*x + py*y + pz*z
+
+		return math.Atan2(cross, dot)
+	}
+
+	endpoints := math.Min(angle(ax, ay, az), angle(bx, by, bz)) * EarthRadius
+
+	// the normal to the great circle through a and b
+
+	nx := ay*bz - az*by
+	ny := az*bx - ax*bz
+	nz := ax*by - ay*bx
+
+	length := math.Sqrt(nx*nx + ny*ny + nz*nz)
+
+	if length == 0.0 {
+		return endpoints
+	}
+
+	nx /= length
+	ny /= length
+	nz /= length
+
+	// the nearest point on the great circle (c) is p with the normal component taken away
+
+	dot := px*nx + py*ny + pz*nz
+
+	cx := px - dot*nx
+	cy := py - dot*ny
+	cz := pz - dot*nz
+
+	// c is between a and b if a x c and c x b both point the same way as the normal
+
+	ac := (ay*cz-az*cy)*nx + (az*cx-ax*cz)*ny + (ax*cy-ay*cx)*nz
+	cb := (cy*bz-cz*by)*nx + (cz*bx-cx*bz)*ny + (cx*by-cy*bx)*nz
+
+	if ac < 0.0 || cb < 0.0 {
+		return endpoints
+	}
+
+	return math.Asin(math.Min(1.0, math.Abs(dot))) * EarthRadius
+}
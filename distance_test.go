@@ -0,0 +1,98 @@
+package geojson
+
+import (
+	"math"
+	"testing"
+)
+
+func TestDistanceToGeometry(t *testing.T) {
+
+	degree := MetersPerDegree
+	donut := `{"type":"Polygon","coordinates":[[[-3,-3],[3,-3],[3,3],[-3,3],[-3,-3]],[[-1,-1],[-1,1],[1,1],[1,-1],[-1,-1]]]}`
+
+	tests := []struct {
+		name     string
+		geometry string
+		lat      float64
+		lon      float64
+		expected float64
+	}{
+		{"point", `{"type":"Point","coordinates":[1,0]}`, 0, 0, degree},
+		{"same point", `{"type":"Point","coordinates":[1,0]}`, 0, 1, 0},
+		{"points", `{"type":"MultiPoint","coordinates":[[5,0],[0,2],[0,-4]]}`, 0, 0, 2 * degree},
+		{"beside a line", `{"type":"LineString","coordinates":[[0,0],[2,0]]}`, 1, 1, degree},
+		{"beyond the end of a line", `{"type":"LineString","coordinates":[[0,0],[1,0]]}`, 0, 3, 2 * degree},
+		{"on a line", `{"type":"LineString","coordinates":[[0,0],[2,0]]}`, 0, 1.5, 0},
+		{"single point line", `{"type":"LineString","coordinates":[[0,0]]}`, 0, 1, degree},
+		{"inside a polygon", squareGeometry(0, 0, 2, 2), 1, 1, 0},
+		{"outside a polygon", squareGeometry(-2, -1, -1, 1), 0, 0, degree},
+		{"beside a polygon", squareGeometry(0, 0, 2, 2), 1, 3, math.Asin(math.Cos(math.Pi/180.0)*math.Sin(math.Pi/180.0)) * EarthRadius},
+		{"in the hole", donut, 0, 0, degree},
+		{"in the donut", donut, 2, 2, 0},
+		{"across the antimeridian", `{"type":"LineString","coordinates":[[179,0],[-179,0]]}`, 1, 180, degree},
+		{"multipolygon", `{"type":"MultiPolygon","coordinates":[[[[5,-1],[6,-1],[6,1],[5,1],[5,-1]]],[[[-2,-1],[-1,-1],[-1,1],[-2,1],[-2,-1]]]]}`, 0, 0, degree},
+		{"collection", `{"type":"GeometryCollection","geometries":[{"type":"Point","coordinates":[3,0]},` + squareGeometry(-1, -1, 1, 1) + `]}`, 0, 0, 0},
+		{"empty", `{"type":"GeometryCollection","geometries":[]}`, 0, 0, math.Inf(1)},
+	}
+
+	for _, test := range tests {
+
+		d := DistanceToGeometry(testGeometry(t, test.geometry), test.lat, test.lon)
+
+		if !(d == test.expected || almostEqual(d, test.expected, 0.001)) {
+			t.Errorf("%s: expected %v, got %v", test.name, test.expected, d)
+		}
+	}
+}
+
+func TestDistanceTo(t *testing.T) {
+
+	f := geometryFeature(t, squareGeometry(-2, -1, -1, 1))
+
+	d, err := f.DistanceTo(0, 0)
+
+	if err != nil || !almostEqual(d, MetersPerDegree, 0.001) {
+		t.Errorf("expected the distance to be one degree, got %v %v", d, err)
+	}
+
+	f = geometryFeature(t, `{"type":"Polygon","coordinates":"nope"}`)
+
+	_, err = f.DistanceTo(0, 0)
+
+	if err == nil {
+		t.Error("expected DistanceTo to fail for a broken geometry")
+	}
+}
+
+func TestDistanceAtOffset(t *testing.T) {
+
+	f := geometryFeature(t, `{"type":"MultiPolygon","coordinates":[[[[5,-1],[6,-1],[6,1],[5,1],[5,-1]]],[[[-2,-1],[-1,-1],[-1,1],[-2,1],[-2,-1]]]]}`)
+
+	pf, err := NewPreparedFeature(f)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		offset   int
+		lat      float64
+		lon      float64
+		expected float64
+	}{
+		{0, 0, 0, 5 * MetersPerDegree},
+		{1, 0, 0, MetersPerDegree},
+		{1, 0, -1.5, 0},
+		{-1, 0, 0, -1},
+		{2, 0, 0, -1},
+	}
+
+	for _, test := range tests {
+
+		d := pf.DistanceAtOffset(test.offset, test.lat, test.lon)
+
+		if !almostEqual(d, test.expected, 0.001) {
+			t.Errorf("expected the distance to %d from %v, %v to be %v, got %v", test.offset, test.lat, test.lon, test.expected, d)
+		}
+	}
+}
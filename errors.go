@@ -0,0 +1,90 @@
+package geojson
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// DecodeError is returned when some part of a feature isn't shaped the way we expect it to
+// be. Path is the location of the offending value relative to the feature itself, using the
+// same dotted notation as gabs plus [n] for list indices - for example:
+//
+//	geometry.coordinates[2][0][5][1]: expected number, got string
+//
+// The idea is that callers processing lots of files can skip (and report) the bad ones rather
+// than falling over.
+
+type DecodeError struct {
+	Path   string
+	Reason string
+}
+
+func (e *DecodeError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Path, e.Reason)
+}
+
+// ErrNoBoundingBox is returned by WOFFeature.BoundingBox for features without a "bbox" property
+
+var ErrNoBoundingBox = &DecodeError{Path: "bbox", Reason: "missing bounding box"}
+
+func expected(path string, want string, got interface{}) *DecodeError {
+
+	reason := fmt.Sprintf("expected %s, got %s", want, jsonType(got))
+	return &DecodeError{Path: path, Reason: reason}
+}
+
+func jsonType(v interface{}) string {
+
+	switch v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case float64, int, json.Number:
+		return "number"
+	case string:
+		return "string"
+	case []interface{}:
+		return "list"
+	case map[string]interface{}:
+		return "dictionary"
+	default:
+		return fmt.Sprintf("%T", v)
+	}
+}
+
+func indexPath(path string, idx int) string {
+	return fmt.Sprintf("%s[%d]", path, idx)
+}
+
+func decodeList(v interface{}, path string) ([]interface{}, error) {
+
+	list, ok := v.([]interface{})
+
+	if !ok {
+		return nil, expected(path, "list", v)
+	}
+
+	return list, nil
+}
+
+func decodeNumber(v interface{}, path string) (float64, error) {
+
+	switch n := v.(type) {
+	case float64:
+		return n, nil
+	case int:
+		return float64(n), nil
+	case json.Number:
+
+		f, err := n.Float64()
+
+		if err != nil {
+			return 0.0, &DecodeError{Path: path, Reason: fmt.Sprintf("invalid number '%s'", n)}
+		}
+
+		return f, nil
+	default:
+		return 0.0, expected(path, "number", v)
+	}
+}
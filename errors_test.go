@@ -0,0 +1,126 @@
+package geojson
+
+import (
+	"testing"
+)
+
+func TestDecodeErrors(t *testing.T) {
+
+	tests := []struct {
+		geometry string
+		expected string
+	}{
+		{`[]`, "geometry: expected dictionary, got list"},
+		{`{"coordinates":[1,2]}`, "geometry.type: expected string, got null"},
+		{`{"type":"Circle","coordinates":[1,2]}`, "geometry.type: unsupported geometry type 'Circle'"},
+		{`{"type":"Point"}`, "geometry.coordinates: expected list, got null"},
+		{`{"type":"Point","coordinates":[1]}`, "geometry.coordinates: expected at least 2 numbers, got 1"},
+		{`{"type":"Point","coordinates":[1,"2"]}`, "geometry.coordinates[1]: expected number, got string"},
+		{`{"type":"LineString","coordinates":[[1,2],3]}`, "geometry.coordinates[1]: expected list, got number"},
+		{`{"type":"MultiLineString","coordinates":[[[1,2],[3,4]],{}]}`, "geometry.coordinates[1]: expected list, got dictionary"},
+		{`{"type":"Polygon","coordinates":[]}`, "geometry.coordinates: polygon has no rings"},
+		{`{"type":"Polygon","coordinates":[[[0,0],[1,0],[1,1],[0,true]]]}`, "geometry.coordinates[0][3][1]: expected number, got boolean"},
+		{`{"type":"MultiPolygon","coordinates":[[[[0,0],[1,0],[1,1],[0,0]]],[[[5,5],[6,5],[6,null],[5,5]]]]}`, "geometry.coordinates[1][0][2][1]: expected number, got null"},
+		{`{"type":"GeometryCollection","geometries":[{"type":"Point","coordinates":[1,2]},{"type":"Point","coordinates":[]}]}`, "geometry.geometries[1].coordinates: expected at least 2 numbers, got 0"},
+		{`{"type":"GeometryCollection"}`, "geometry.geometries: expected list, got null"},
+	}
+
+	for _, test := range tests {
+
+		f := geometryFeature(t, test.geometry)
+
+		_, err := f.Geometry()
+
+		if err == nil {
+			t.Errorf("expected %s to fail to decode", test.geometry)
+			continue
+		}
+
+		if _, ok := err.(*DecodeError); !ok {
+			t.Errorf("expected a *DecodeError for %s, got %T", test.geometry, err)
+		}
+
+		if err.Error() != test.expected {
+			t.Errorf("expected the error for %s to be %q, got %q", test.geometry, test.expected, err.Error())
+		}
+
+		// none of these should panic, and the ones that can't complain give up quietly
+
+		_, err = f.GeomToPolygonsWithError()
+
+		if err == nil {
+			t.Errorf("expected GeomToPolygonsWithError to fail for %s", test.geometry)
+		}
+
+		if len(f.GeomToPolygons()) != 0 {
+			t.Errorf("expected GeomToPolygons to be empty for %s", test.geometry)
+		}
+
+		if f.Contains(0.5, 0.5) {
+			t.Errorf("expected nothing to be inside %s", test.geometry)
+		}
+
+		_, err = f.EnSpatializeGeom()
+
+		if err == nil {
+			t.Errorf("expected EnSpatializeGeom to fail for %s", test.geometry)
+		}
+	}
+}
+
+func TestBoundingBoxErrors(t *testing.T) {
+
+	tests := []struct {
+		bbox     string
+		expected string
+	}{
+		{`[0,0,1]`, "bbox: weird and freaky bounding box"},
+		{`"0,0,1,1"`, "bbox: weird and freaky bounding box"},
+		{`[0,0,"1",1]`, "bbox[2]: expected number, got string"},
+	}
+
+	for _, test := range tests {
+
+		f := testFeature(t, `{"type":"Feature","properties":{},"bbox":`+test.bbox+`,"geometry":null}`)
+
+		_, err := f.BoundingBox()
+
+		if err == nil {
+			t.Errorf("expected %s to fail", test.bbox)
+			continue
+		}
+
+		if err.Error() != test.expected {
+			t.Errorf("expected the error for %s to be %q, got %q", test.bbox, test.expected, err.Error())
+		}
+	}
+
+	f := geometryFeature(t, `null`)
+
+	_, err := f.BoundingBox()
+
+	if err != ErrNoBoundingBox {
+		t.Errorf("expected ErrNoBoundingBox, got %v", err)
+	}
+}
+
+func TestDumpMethodsDontPanic(t *testing.T) {
+
+	f := geometryFeature(t, `null`)
+
+	garbage := []interface{}{"x", []interface{}{1.0}, []interface{}{[]interface{}{0.0, "y"}}}
+
+	if len(f.DumpMultiPolygon(garbage)) != 0 {
+		t.Error("expected DumpMultiPolygon to skip the garbage")
+	}
+
+	if len(f.DumpPolygon(garbage).OuterRing.Points()) != 0 {
+		t.Error("expected DumpPolygon to return an empty polygon")
+	}
+
+	coords := f.DumpCoords(garbage)
+
+	if len(coords.Points()) != 0 {
+		t.Error("expected DumpCoords to skip the garbage")
+	}
+}
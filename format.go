@@ -0,0 +1,528 @@
+package geojson
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"math/big"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+/*
+
+MarshalFeature writes a feature out the same way the (Python) WOF export tools do, so that
+editing a record with a Go tool doesn't create a noisy diff in the data repositories:
+
+	{
+	  "id": 101736545,
+	  "type": "Feature",
+	  "properties": {
+	    "geom:area":0.040796,
+	    "wof:belongsto":[
+	      102191575,
+	      85633041
+	    ],
+	    "wof:name":"Montréal"
+	  },
+	  "bbox": [
+	    -73.974292,
+	    45.410076,
+	    -73.474295,
+	    45.70479
+	  ],
+	  "geometry": {"coordinates":[[[-73.6,45.5],...]],"type":"Polygon"}
+	}
+
+That is: the top-level keys come in the order id, type, properties, bbox, geometry (followed
+by anything else, sorted); properties are sorted by key and indented two spaces with no space
+after the colon; the geometry is compact, on one line. Strings are written as UTF-8 with
+nothing HTML-escaped.
+
+Numbers are written the way Python does after loading the file: an int is written as it is and
+a float is written the way Python's repr does it (the shortest representation that round-trips,
+always with a fractional part or an exponent). Numbers that were read from a file are decoded
+as json.Number (see parseJSON) so whether they are ints or floats is taken from how they were
+written, which means a latitude of 45.0 stays 45.0 and a record written by the export tools
+comes back out unchanged. Numbers that were set by hand (with SetProperty, say) are float64 by
+the time we see them so there's no telling whether 10 was meant to be an int or a float; those
+are written as ints if they have no fractional part.
+
+*/
+
+// the keys that always come first, in this order
+
+var canonicalKeys = []string{"id", "type", "properties", "bbox", "geometry"}
+
+// MarshalFeature returns the feature encoded as canonical WOF GeoJSON (see above)
+
+func MarshalFeature(f *WOFFeature) ([]byte, error) {
+
+	feature, ok := f.Parsed.Data().(map[string]interface{})
+
+	if !ok {
+		return nil, &DecodeError{Path: "", Reason: "feature is not a dictionary"}
+	}
+
+	keys := make([]string, 0)
+	known := make(map[string]bool)
+
+	for _, k := range canonicalKeys {
+
+		known[k] = true
+
+		if _, ok := feature[k]; ok {
+			keys = append(keys, k)
+		}
+	}
+
+	others := make([]string, 0)
+
+	for k := range feature {
+
+		if !known[k] {
+			others = append(others, k)
+		}
+	}
+
+	sort.Strings(others)
+	keys = append(keys, others...)
+
+	var buf bytes.Buffer
+	buf.WriteString("{\n")
+
+	for i, k := range keys {
+
+		buf.WriteString("  ")
+
+		err := writeString(&buf, k)
+
+		if err != nil {
+			return nil, err
+		}
+
+		buf.WriteString(": ")
+
+		switch k {
+		case "id", "type", "geometry":
+			err = writeCompact(&buf, feature[k], k)
+		default:
+			err = writeIndented(&buf, feature[k], k, "  ")
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		if i < len(keys)-1 {
+			buf.WriteString(",")
+		}
+
+		buf.WriteString("\n")
+	}
+
+	buf.WriteString("}\n")
+
+	return buf.Bytes(), nil
+}
+
+// WriteFile writes the feature to path as canonical WOF GeoJSON (see MarshalFeature)
+
+func WriteFile(path string, f *WOFFeature) error {
+
+	body, err := MarshalFeature(f)
+
+	if err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(path, body, 0644)
+}
+
+// Python's json.dumps(indent=2, separators=(',', ':')) with sorted keys; prefix is the
+// indentation of the line the value starts on
+
+func writeIndented(buf *bytes.Buffer, v interface{}, path string, prefix string) error {
+
+	v, err := normalizeValue(v, path)
+
+	if err != nil {
+		return err
+	}
+
+	switch value := v.(type) {
+
+	case map[string]interface{}:
+
+		if len(value) == 0 {
+			buf.WriteString("{}")
+			return nil
+		}
+
+		keys := make([]string, 0)
+
+		for k := range value {
+			keys = append(keys, k)
+		}
+
+		sort.Strings(keys)
+
+		buf.WriteString("{\n")
+
+		for i, k := range keys {
+
+			buf.WriteString(prefix + "  ")
+
+			err := writeString(buf, k)
+
+			if err != nil {
+				return err
+			}
+
+			buf.WriteString(":")
+
+			err = writeIndented(buf, value[k], path+"."+k, prefix+"  ")
+
+			if err != nil {
+				return err
+			}
+
+			if i < len(keys)-1 {
+				buf.WriteString(",")
+			}
+
+			buf.WriteString("\n")
+		}
+
+		buf.WriteString(prefix + "}")
+		return nil
+
+	case []interface{}:
+
+		if len(value) == 0 {
+			buf.WriteString("[]")
+			return nil
+		}
+
+		buf.WriteString("[\n")
+
+		for i, item := range value {
+
+			buf.WriteString(prefix + "  ")
+
+			err := writeIndented(buf, item, indexPath(path, i), prefix+"  ")
+
+			if err != nil {
+				return err
+			}
+
+			if i < len(value)-1 {
+				buf.WriteString(",")
+			}
+
+			buf.WriteString("\n")
+		}
+
+		buf.WriteString(prefix + "]")
+		return nil
+
+	default:
+		return writeScalar(buf, value, path)
+	}
+}
+
+// the same as writeIndented but all on one line with no spaces
+
+func writeCompact(buf *bytes.Buffer, v interface{}, path string) error {
+
+	v, err := normalizeValue(v, path)
+
+	if err != nil {
+		return err
+	}
+
+	switch value := v.(type) {
+
+	case map[string]interface{}:
+
+		keys := make([]string, 0)
+
+		for k := range value {
+			keys = append(keys, k)
+		}
+
+		sort.Strings(keys)
+
+		buf.WriteString("{")
+
+		for i, k := range keys {
+
+			if i > 0 {
+				buf.WriteString(",")
+			}
+
+			err := writeString(buf, k)
+
+			if err != nil {
+				return err
+			}
+
+			buf.WriteString(":")
+
+			err = writeCompact(buf, value[k], path+"."+k)
+
+			if err != nil {
+				return err
+			}
+		}
+
+		buf.WriteString("}")
+		return nil
+
+	case []interface{}:
+
+		buf.WriteString("[")
+
+		for i, item := range value {
+
+			if i > 0 {
+				buf.WriteString(",")
+			}
+
+			err := writeCompact(buf, item, indexPath(path, i))
+
+			if err != nil {
+				return err
+			}
+		}
+
+		buf.WriteString("]")
+		return nil
+
+	default:
+		return writeScalar(buf, value, path)
+	}
+}
+
+// normalizeValue turns anything that isn't what encoding/json would have decoded in to (say a
+// []string or an int that was put there with gabs' Set) in to something that is
+
+func normalizeValue(v interface{}, path string) (interface{}, error) {
+
+	switch value := v.(type) {
+
+	case nil, bool, string, float64, map[string]interface{}, []interface{}:
+		return v, nil
+
+	case int:
+		return float64(value), nil
+
+	case int64:
+		return float64(value), nil
+
+	case json.Number:
+
+		_, err := decodeNumber(value, path)
+
+		if err != nil {
+			return nil, err
+		}
+
+		return v, nil
+
+	default:
+
+		enc, err := json.Marshal(v)
+
+		if err != nil {
+			return nil, &DecodeError{Path: path, Reason: err.Error()}
+		}
+
+		var decoded interface{}
+
+		err = json.Unmarshal(enc, &decoded)
+
+		if err != nil {
+			return nil, &DecodeError{Path: path, Reason: err.Error()}
+		}
+
+		return decoded, nil
+	}
+}
+
+// numberValues turns every json.Number in v in to a float64, for comparing numbers that were read
+// from a file with ones that were set by hand (or read from somewhere else) by value
+
+func numberValues(v interface{}) interface{} {
+
+	switch value := v.(type) {
+
+	case json.Number:
+
+		f, err := value.Float64()
+
+		if err != nil {
+			return v
+		}
+
+		return f
+
+	case []interface{}:
+
+		list := make([]interface{}, len(value))
+
+		for i, item := range value {
+			list[i] = numberValues(item)
+		}
+
+		return list
+
+	case map[string]interface{}:
+
+		dict := make(map[string]interface{})
+
+		for k, item := range value {
+			dict[k] = numberValues(item)
+		}
+
+		return dict
+
+	default:
+		return v
+	}
+}
+
+func writeScalar(buf *bytes.Buffer, v interface{}, path string) error {
+
+	switch value := v.(type) {
+
+	case nil:
+		buf.WriteString("null")
+
+	case bool:
+		buf.WriteString(strconv.FormatBool(value))
+
+	case string:
+		return writeString(buf, value)
+
+	case float64:
+
+		s, err := formatNumber(value)
+
+		if err != nil {
+			return &DecodeError{Path: path, Reason: err.Error()}
+		}
+
+		buf.WriteString(s)
+
+	case json.Number:
+
+		s, err := formatDecodedNumber(value)
+
+		if err != nil {
+			return &DecodeError{Path: path, Reason: err.Error()}
+		}
+
+		buf.WriteString(s)
+
+	default:
+		return expected(path, "scalar", v)
+	}
+
+	return nil
+}
+
+func writeString(buf *bytes.Buffer, s string) error {
+
+	var enc bytes.Buffer
+
+	encoder := json.NewEncoder(&enc)
+	encoder.SetEscapeHTML(false)
+
+	err := encoder.Encode(s)
+
+	if err != nil {
+		return err
+	}
+
+	buf.Write(bytes.TrimRight(enc.Bytes(), "\n"))
+	return nil
+}
+
+// formatNumber is for numbers that were set by hand, rather than read from a file, where there's
+// no telling whether 10 was meant to be an int or a float: integral values are written as
+// integers and everything else the way Python's repr does (see formatFloat)
+
+func formatNumber(f float64) (string, error) {
+
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return "", fmt.Errorf("%v can not be encoded as JSON", f)
+	}
+
+	if f == math.Trunc(f) && math.Abs(f) < 1e16 {
+		return strconv.FormatInt(int64(f), 10), nil
+	}
+
+	return formatFloat(f)
+}
+
+// formatDecodedNumber writes a number that was read from a file the way Python would after
+// loading it: written with a fractional part or an exponent it's a float, so 10.0 stays 10.0
+// and 0.00001 becomes 1e-05, and otherwise it's an int and stays exactly as it is
+
+func formatDecodedNumber(n json.Number) (string, error) {
+
+	text := string(n)
+
+	if !strings.ContainsAny(text, ".eE") {
+
+		i, ok := new(big.Int).SetString(text, 10)
+
+		if !ok {
+			return "", fmt.Errorf("invalid number '%s'", text)
+		}
+
+		return i.String(), nil
+	}
+
+	f, err := n.Float64()
+
+	if err != nil {
+		return "", fmt.Errorf("invalid number '%s'", text)
+	}
+
+	if math.IsInf(f, 0) {
+		return "", fmt.Errorf("%s can not be encoded as JSON", text)
+	}
+
+	return formatFloat(f)
+}
+
+// formatFloat is Python's repr for a float: the shortest representation that round-trips,
+// switching to an exponent below 1e-4 and from 1e16 up, and always with a fractional part or
+// an exponent
+
+func formatFloat(f float64) (string, error) {
+
+	s := strconv.FormatFloat(f, 'e', -1, 64)
+	exp, err := strconv.Atoi(s[strings.Index(s, "e")+1:])
+
+	if err != nil {
+		return "", err
+	}
+
+	if exp < -4 || exp >= 16 {
+		return s, nil
+	}
+
+	s = strconv.FormatFloat(f, 'f', -1, 64)
+
+	if !strings.Contains(s, ".") {
+		s = s + ".0"
+	}
+
+	return s, nil
+}
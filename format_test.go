@@ -0,0 +1,324 @@
+package geojson
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"math"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// a record the way the export tools write it
+
+const canonicalFeature = `{
+  "id": 101736545,
+  "type": "Feature",
+  "properties": {
+    "edtf:cessation":"uuuu",
+    "geom:area":0.040796,
+    "geom:latitude":45.536118,
+    "misc:html":"<b>Tom & Jerry</b>",
+    "wof:belongsto":[
+      102191575,
+      85633041
+    ],
+    "wof:concordances":{},
+    "wof:hierarchy":[
+      {
+        "country_id":85633041,
+        "locality_id":101736545
+      }
+    ],
+    "wof:name":"Montréal",
+    "wof:superseded_by":[]
+  },
+  "bbox": [
+    -73.974292,
+    45.410076,
+    -73.474295,
+    45.70479
+  ],
+  "geometry": {"coordinates":[[[-73.974292,45.410076],[-73.474295,45.410076],[-73.474295,45.70479],[-73.974292,45.410076]]],"type":"Polygon"},
+  "a": 1,
+  "z": null
+}
+`
+
+func TestMarshalFeatureCanonical(t *testing.T) {
+
+	f := testFeature(t, canonicalFeature)
+
+	body, err := MarshalFeature(f)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(body) != canonicalFeature {
+		t.Errorf("expected the export tools' formatting to be left alone, got:\n%s", body)
+	}
+}
+
+// a record the way the export tools write it, with floats that have no fractional part
+
+const integralFloatsFeature = `{
+  "id": 85632997,
+  "type": "Feature",
+  "properties": {
+    "geom:area":10.0,
+    "geom:latitude":45.0,
+    "geom:longitude":-73.0,
+    "lbl:latitude":45.25,
+    "wof:id":85632997,
+    "wof:name":"x",
+    "wof:population":12000
+  },
+  "bbox": [
+    -74.0,
+    44.0,
+    -72.0,
+    46.0
+  ],
+  "geometry": {"coordinates":[[[-74.0,44.0],[-72.0,44.0],[-72.0,46.0],[-74.0,46.0],[-74.0,44.0]]],"type":"Polygon"}
+}
+`
+
+func TestMarshalFeatureIntegralFloats(t *testing.T) {
+
+	dir, err := ioutil.TempDir("", "format")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "85632997.geojson")
+
+	err = ioutil.WriteFile(path, []byte(integralFloatsFeature), 0644)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	f, err := UnmarshalFile(path)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	body, err := MarshalFeature(f)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(body) != integralFloatsFeature {
+		t.Errorf("expected UnmarshalFile and MarshalFeature to round-trip, got:\n%s", body)
+	}
+
+	r := NewReader(strings.NewReader(integralFloatsFeature))
+
+	f, err = r.Next()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	body, err = MarshalFeature(f)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(body) != integralFloatsFeature {
+		t.Errorf("expected Reader and MarshalFeature to round-trip, got:\n%s", body)
+	}
+
+	// the numbers are still numbers as far as everything else is concerned
+
+	if f.Id() != 85632997 {
+		t.Errorf("expected an ID of 85632997, got %d", f.Id())
+	}
+
+	bb, err := f.BoundingBox()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(bb.Array(), []float64{-74, 44, -72, 46}) {
+		t.Errorf("unexpected bounding box %v", bb.Array())
+	}
+}
+
+func TestMarshalFeatureIsStable(t *testing.T) {
+
+	// written any old how; floats are written the way Python would write them whether or not
+	// they have a fractional part (see format.go)
+
+	messy := `{"geometry":{"type":"Point","coordinates":[-73.0,45.50]},"properties":{"wof:name":"x","wof:id":1.0,"b":[1.5e-7,2e16]},"type":"Feature","id":1}`
+
+	f := testFeature(t, messy)
+
+	first, err := MarshalFeature(f)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `{
+  "id": 1,
+  "type": "Feature",
+  "properties": {
+    "b":[
+      1.5e-07,
+      2e+16
+    ],
+    "wof:id":1.0,
+    "wof:name":"x"
+  },
+  "geometry": {"coordinates":[-73.0,45.5],"type":"Point"}
+}
+`
+
+	if string(first) != expected {
+		t.Errorf("unexpected output:\n%s", first)
+	}
+
+	second, err := MarshalFeature(testFeature(t, string(first)))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(second) != string(first) {
+		t.Errorf("expected MarshalFeature's output to come back out unchanged, got:\n%s", second)
+	}
+}
+
+func TestMarshalFeatureSetValues(t *testing.T) {
+
+	// things put there with gabs' Set aren't what encoding/json would have decoded
+
+	f := geometryFeature(t, `null`)
+
+	f.Parsed.Set([]string{"a", "b"}, "properties", "strings")
+	f.Parsed.Set(int64(12), "properties", "int64")
+	f.Parsed.Set([]float64{1.25, 2}, "properties", "floats")
+
+	body, err := MarshalFeature(f)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, expected := range []string{`"strings":[
+      "a",
+      "b"
+    ]`, `"int64":12`, `"floats":[
+      1.25,
+      2
+    ]`} {
+
+		if !strings.Contains(string(body), expected) {
+			t.Errorf("expected output to contain %s, got:\n%s", expected, body)
+		}
+	}
+
+	f.Parsed.Set(math.NaN(), "properties", "nan")
+
+	_, err = MarshalFeature(f)
+
+	if err == nil {
+		t.Error("expected NaN to be an error")
+	}
+}
+
+func TestFormatNumber(t *testing.T) {
+
+	tests := []struct {
+		value    float64
+		expected string
+	}{
+		{0.0, "0"},
+		{45.0, "45"},
+		{-73.0, "-73"},
+		{102191575.0, "102191575"},
+		{0.1, "0.1"},
+		{-73.974292, "-73.974292"},
+		{1.0 / 3.0, "0.3333333333333333"},
+		{0.0001, "0.0001"},
+		{0.00001, "1e-05"},
+		{1.5e-7, "1.5e-07"},
+		{1e16, "1e+16"},
+		{1234567890123456.5, "1234567890123456.5"},
+	}
+
+	for _, test := range tests {
+
+		s, err := formatNumber(test.value)
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if s != test.expected {
+			t.Errorf("expected %v to be written as %s, got %s", test.value, test.expected, s)
+		}
+	}
+
+	for _, value := range []float64{math.NaN(), math.Inf(1), math.Inf(-1)} {
+
+		_, err := formatNumber(value)
+
+		if err == nil {
+			t.Errorf("expected %v to be an error", value)
+		}
+	}
+}
+
+func TestFormatDecodedNumber(t *testing.T) {
+
+	tests := []struct {
+		value    string
+		expected string
+	}{
+		{"0", "0"},
+		{"-0", "0"},
+		{"102191575", "102191575"},
+		{"123456789012345678901234567890", "123456789012345678901234567890"},
+		{"10.0", "10.0"},
+		{"-73.0", "-73.0"},
+		{"45.50", "45.5"},
+		{"-0.0", "-0.0"},
+		{"0.00001", "1e-05"},
+		{"1e2", "100.0"},
+		{"2E16", "2e+16"},
+	}
+
+	for _, test := range tests {
+
+		s, err := formatDecodedNumber(json.Number(test.value))
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if s != test.expected {
+			t.Errorf("expected %s to be written as %s, got %s", test.value, test.expected, s)
+		}
+	}
+
+	for _, value := range []string{"", "1x", "1e999"} {
+
+		_, err := formatDecodedNumber(json.Number(value))
+
+		if err == nil {
+			t.Errorf("expected '%s' to be an error", value)
+		}
+	}
+}
@@ -1,15 +1,18 @@
 package geojson
 
 import (
+	"bytes"
+	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	rtreego "github.com/dhconnelly/rtreego"
 	gabs "github.com/jeffail/gabs"
 	geo "github.com/kellydunn/golang-geo"
+	"io"
 	ioutil "io/ioutil"
 	"log"
 	"strconv"
-	"sync"
 )
 
 /*
@@ -17,7 +20,7 @@ import (
 - gabs is what handles marshaling a random bag of GeoJSON
 - rtreego is imported to convert a WOFFeature in to a handy rtreego.Spatial object for indexing by go-whosonfirst-pip
 - geo is imported to convert a WOFFeature geometry into a list of geo.Polygon objects for doing containment checks in go-whosonfirst-pip
-  (only Polygons and MultiPolygons are supported at the moment)
+  (see geometry.go for the typed Geometry model that all of this is built on)
 
 */
 
@@ -56,45 +59,12 @@ func (p *WOFPolygon) CountPoints() int {
 	return count
 }
 
-func (p *WOFPolygon) Contains(latitude float64, longitude float64) bool {
-
-	pt := geo.NewPoint(latitude, longitude)
-	contains := false
-
-	if p.OuterRing.Contains(pt) {
-		contains = true
-	}
-
-	if contains && len(p.InteriorRings) > 0 {
-
-		wg := new(sync.WaitGroup)
-
-		for _, r := range p.InteriorRings {
-
-			wg.Add(1)
-
-			go func(poly geo.Polygon, point *geo.Point) {
-
-				defer wg.Done()
-
-				/*
+// Contains reports whether the point falls inside the outer ring and outside all of the
+// interior rings. See also ContainsWithContext (in contains.go)
 
-					File under yak-shaving: Some way to send an intercept to poly.Contains
-					to stop the raycasting if any one of these goroutines gets the answer
-					it needs independent the results of the others. Like I said... yaks.
-					(20151028/thisisaaronland)
-				*/
-
-				if poly.Contains(point) {
-					contains = false
-				}
-
-			}(r, pt)
-		}
-
-		wg.Wait()
-	}
+func (p *WOFPolygon) Contains(latitude float64, longitude float64) bool {
 
+	contains, _ := p.ContainsWithContext(context.Background(), latitude, longitude)
 	return contains
 }
 
@@ -106,7 +76,18 @@ func (sp WOFSpatial) Bounds() *rtreego.Rect {
 // (201251207/thisisaaronland)
 
 type WOFFeature struct {
-	Parsed *gabs.Container
+	Parsed   *gabs.Container
+	geometry *geometryCache
+}
+
+func NewWOFFeature(parsed *gabs.Container) *WOFFeature {
+
+	f := WOFFeature{
+		Parsed:   parsed,
+		geometry: new(geometryCache),
+	}
+
+	return &f
 }
 
 func (wof WOFFeature) Body() *gabs.Container {
@@ -142,13 +123,7 @@ func (wof WOFFeature) Id() int {
 
 func (wof WOFFeature) Name() string {
 
-	name, ok := wof.name("properties.wof:name")
-
-	if ok {
-		return name
-	}
-
-	name, ok = wof.name("properties.name")
+	name, ok := wof.DefaultName()
 
 	if ok {
 		return name
@@ -204,9 +179,9 @@ func (wof WOFFeature) Superseded() bool {
 
 	body := wof.Body()
 
-	pointers := body.Path("properties.wof:superseded_by").Data()
+	pointers, ok := body.Path("properties.wof:superseded_by").Data().([]interface{})
 
-	if len(pointers.([]interface{})) != 0 {
+	if ok && len(pointers) != 0 {
 		return true
 	}
 
@@ -252,6 +227,23 @@ func (wof WOFFeature) Hierarchy() []map[string]int {
 				}
 			}
 
+			if !ok {
+
+				var id_number json.Number
+				id_number, ok = v.Data().(json.Number)
+
+				if ok {
+
+					id_float, err := decodeNumber(id_number, k)
+
+					if err == nil {
+						id = int(id_float)
+					} else {
+						ok = false
+					}
+				}
+			}
+
 			if !ok {
 
 				// See this. It's important. We are pairing it with `ok` below
@@ -279,7 +271,7 @@ func (wof WOFFeature) Hierarchy() []map[string]int {
 			}
 
 			if !ok {
-				log.Println("Failed to type cast (%s = %s) in to a WOF ID", k, v)
+				log.Printf("Failed to type cast (%s = %s) in to a WOF ID\n", k, v)
 				id = -1
 			}
 
@@ -341,6 +333,26 @@ func (wof WOFFeature) IntValue(path string) (int, bool) {
 		id, ok = body.Path(path).Data().(int)
 	}
 
+	// numbers are decoded as json.Number now (see parseJSON) so this is the one that
+	// usually matters
+
+	if !ok {
+
+		var id_number json.Number
+		id_number, ok = body.Path(path).Data().(json.Number)
+
+		if ok {
+
+			id_float, err := decodeNumber(id_number, path)
+
+			if err != nil {
+				ok = false
+			} else {
+				id = int(id_float)
+			}
+		}
+	}
+
 	// But wait... there's more (20151028/thisisaaronland)
 
 	if !ok {
@@ -377,34 +389,19 @@ func (wof WOFFeature) EnSpatialize() (*WOFSpatial, error) {
 	deprecated := wof.Deprecated()
 	superseded := wof.Superseded()
 
-	body := wof.Body()
-
-	var swlon float64
-	var swlat float64
-	var nelon float64
-	var nelat float64
+	// trust the bbox if there is one but if there isn't work it out from the geometry
 
-	children, _ := body.S("bbox").Children()
+	bb, err := wof.BoundingBox()
 
-	if len(children) != 4 {
-		return nil, errors.New("weird and freaky bounding box")
+	if err == ErrNoBoundingBox {
+		bb, err = wof.ComputeBounds()
 	}
 
-	swlon = children[0].Data().(float64)
-	swlat = children[1].Data().(float64)
-	nelon = children[2].Data().(float64)
-	nelat = children[3].Data().(float64)
-
-	llat := nelat - swlat
-	llon := nelon - swlon
-
-	/*
-		fmt.Printf("%f - %f = %f\n", nelat, swlat, llat)
-		fmt.Printf("%f - %f = %f\n", nelon, swlon, llon)
-	*/
+	if err != nil {
+		return nil, err
+	}
 
-	pt := rtreego.Point{swlon, swlat}
-	rect, err := rtreego.NewRect(pt, []float64{llon, llat})
+	rect, err := bb.Rect()
 
 	if err != nil {
 		return nil, err
@@ -424,82 +421,65 @@ func (wof WOFFeature) EnSpatializeGeom() ([]*WOFSpatial, error) {
 	deprecated := wof.Deprecated()
 	superseded := wof.Superseded()
 
-	spatial := make([]*WOFSpatial, 0)
-	polygons := wof.GeomToPolygons()
-
-	for offset, poly := range polygons {
-
-		swlat := 0.0
-		swlon := 0.0
-		nelat := 0.0
-		nelon := 0.0
+	geom, err := wof.Geometry()
 
-		for _, pt := range poly.OuterRing.Points() {
+	if err != nil {
+		return nil, err
+	}
 
-			lat := pt.Lat()
-			lon := pt.Lng()
+	spatial := make([]*WOFSpatial, 0)
 
-			if swlat == 0.0 || swlat > lat {
-				swlat = lat
-			}
+	for offset, part := range Flatten(geom) {
 
-			if swlon == 0.0 || swlon > lon {
-				swlon = lon
-			}
+		bb := ComputeBounds(part)
 
-			if nelat == 0.0 || nelat < lat {
-				nelat = lat
-			}
-
-			if nelon == 0.0 || nelon < lon {
-				nelon = lon
-			}
+		// a polygon whose outer ring has no positions doesn't have anywhere to go
 
-			// fmt.Println(lat, lon, swlat, swlon, nelat, nelon)
+		if bb.IsEmpty() {
+			continue
 		}
 
-		llat := nelat - swlat
-		llon := nelon - swlon
+		// geometries that cross the antimeridian get one WOFSpatial thing-y
+		// on either side of it, both with the same offset
 
-		pt := rtreego.Point{swlon, swlat}
-		rect, err := rtreego.NewRect(pt, []float64{llon, llat})
+		rects, err := bb.Rects()
 
 		if err != nil {
 			return nil, err
 		}
 
-		sp := WOFSpatial{rect, id, name, placetype, offset, deprecated, superseded}
-		spatial = append(spatial, &sp)
+		for _, rect := range rects {
+			sp := WOFSpatial{rect, id, name, placetype, offset, deprecated, superseded}
+			spatial = append(spatial, &sp)
+		}
 	}
 
 	return spatial, nil
 }
 
-func (wof WOFFeature) Contains(latitude float64, longitude float64) bool {
-
-	polygons := wof.GeomToPolygons()
-	contains := false
-
-	wg := new(sync.WaitGroup)
+// Contains tests each of the feature's simple geometries (see Flatten) in turn and stops as
+// soon as one of them contains the point. See also ContainsWithContext (in contains.go)
 
-	for _, p := range polygons {
-
-		wg.Add(1)
+func (wof WOFFeature) Contains(latitude float64, longitude float64) bool {
 
-		go func(poly *WOFPolygon, lat float64, lon float64) {
+	contains, _ := wof.ContainsWithContext(context.Background(), latitude, longitude, 1)
+	return contains
+}
 
-			defer wg.Done()
+// Geometry returns the typed version of the feature's geometry. It is decoded the first
+// time it is asked for and cached on the feature after that.
 
-			if poly.Contains(lat, lon) {
-				contains = true
-			}
+func (wof WOFFeature) Geometry() (Geometry, error) {
 
-		}(p, latitude, longitude)
+	raw := func() interface{} {
+		return wof.Body().S("geometry").Data()
 	}
 
-	wg.Wait()
+	if wof.geometry == nil {
+		return DecodeGeometry(raw())
+	}
 
-	return contains
+	return wof.geometry.get(raw)
 }
 
 // sudo make me a package function and accept an interface... maybe?
@@ -507,48 +487,55 @@ func (wof WOFFeature) Contains(latitude float64, longitude float64) bool {
 
 func (wof WOFFeature) GeomToPolygons() []*WOFPolygon {
 
-	body := wof.Body()
-
-	var geom_type string
+	polygons, err := wof.GeomToPolygonsWithError()
 
-	geom_type, _ = body.Path("geometry.type").Data().(string)
-	children, _ := body.S("geometry").ChildrenMap()
+	if err != nil {
+		return make([]*WOFPolygon, 0)
+	}
 
-	polygons := make([]*WOFPolygon, 0)
+	return polygons
+}
 
-	for key, child := range children {
+// GeomToPolygonsWithError is the same as GeomToPolygons except that it tells you when the
+// geometry couldn't be decoded (as a *DecodeError) rather than returning an empty list
 
-		if key != "coordinates" {
-			continue
-		}
+func (wof WOFFeature) GeomToPolygonsWithError() ([]*WOFPolygon, error) {
 
-		var coordinates []interface{}
-		coordinates, _ = child.Data().([]interface{})
+	geom, err := wof.Geometry()
 
-		if geom_type == "Polygon" {
-			polygons = append(polygons, wof.DumpPolygon(coordinates))
-		} else if geom_type == "MultiPolygon" {
-			polygons = wof.DumpMultiPolygon(coordinates)
-		} else {
-			// pass
-		}
+	if err != nil {
+		return nil, err
 	}
 
-	return polygons
+	return geom.Polygons(), nil
 }
 
 // sudo these don't need to be public methods
 // (20151207/thisisaaronland)
 
+// The Dump* methods are left over from before DecodeGeometry and are only kept around for
+// backwards compatibility. They no longer panic when they are handed garbage but they don't
+// tell you about it either - anything that can't be decoded is simply left out.
+
 func (wof WOFFeature) DumpMultiPolygon(coordinates []interface{}) []*WOFPolygon {
 
 	polygons := make([]*WOFPolygon, 0)
 
-	for _, ipolys := range coordinates {
+	for i, ipolys := range coordinates {
 
-		polys := ipolys.([]interface{})
+		path := indexPath("coordinates", i)
+		polys, err := decodeList(ipolys, path)
+
+		if err != nil {
+			continue
+		}
+
+		polygon, err := decodePolygon(polys, path)
+
+		if err != nil {
+			continue
+		}
 
-		polygon := wof.DumpPolygon(polys)
 		polygons = append(polygons, polygon)
 	}
 
@@ -560,19 +547,17 @@ func (wof WOFFeature) DumpMultiPolygon(coordinates []interface{}) []*WOFPolygon
 
 func (wof WOFFeature) DumpPolygon(coordinates []interface{}) *WOFPolygon {
 
-	polygons := make([]geo.Polygon, 0)
+	polygon, err := decodePolygon(coordinates, "coordinates")
 
-	for _, ipoly := range coordinates {
+	if err != nil {
 
-		poly := ipoly.([]interface{})
-		polygon := wof.DumpCoords(poly)
-		polygons = append(polygons, polygon)
+		return &WOFPolygon{
+			OuterRing:     geo.Polygon{},
+			InteriorRings: make([]geo.Polygon, 0),
+		}
 	}
 
-	return &WOFPolygon{
-		OuterRing:     polygons[0],
-		InteriorRings: polygons[1:],
-	}
+	return polygon
 }
 
 // sudo these don't need to be public methods
@@ -582,14 +567,21 @@ func (wof WOFFeature) DumpCoords(poly []interface{}) geo.Polygon {
 
 	polygon := geo.Polygon{}
 
-	for _, icoords := range poly {
+	for i, icoords := range poly {
+
+		path := indexPath("coordinates", i)
+		coords, err := decodeList(icoords, path)
+
+		if err != nil {
+			continue
+		}
 
-		coords := icoords.([]interface{})
+		pt, err := decodePosition(coords, path)
 
-		lon := coords[0].(float64)
-		lat := coords[1].(float64)
+		if err != nil {
+			continue
+		}
 
-		pt := geo.NewPoint(lat, lon)
 		polygon.Add(pt)
 	}
 
@@ -609,66 +601,14 @@ func UnmarshalFile(path string) (*WOFFeature, error) {
 	return UnmarshalFeature(body)
 }
 
-// this is disabled for now even though it (or something like it) is
-// probably the new new; note the way we end up parsing the JSON body
-// twice... we should not do that (20151207/thisisaaronland)
-
-/*
-func UnmarshalFileMulti(path string) ([]*WOFFeature, error) {
-
-	body, read_err := ioutil.ReadFile(path)
-
-	if read_err != nil {
-		return nil, read_err
-	}
-
-	parsed, parse_err := gabs.ParseJSON(body)
-
-	if parse_err != nil {
-		return nil, parse_err
-	}
-
-	isa, ok := parsed.Path("type").Data().(string)
-
-	if !ok {
-		return nil, errors.New("failed to determine type")
-	}
-
-	features := make([]*WOFFeature, 0)
-
-	if isa == "Feature" {
-
-		f, err := UnmarshalFeature(body)
-
-		if err != nil {
-			return nil, err
-		}
-
-		features = append(features, f)
-
-	} else if isa == "FeatureCollection" {
-
-		collection, err := UnmarshalFeatureCollection(body)
-
-		if err != nil {
-			return nil, err
-		}
-
-		features = collection
-	} else {
-		return nil, errors.New("unknown type")
-	}
-
-	return features, nil
-}
-*/
+// see reader.go for UnmarshalFileMulti and the streaming Reader it is built on
 
 // see above inre passing bytes or an already parsed thing-y
 // (20151207/thisisaaronland)
 
 func UnmarshalFeatureCollection(raw []byte) ([]*WOFFeature, error) {
 
-	parsed, parse_err := gabs.ParseJSON(raw)
+	parsed, parse_err := parseJSON(raw)
 
 	if parse_err != nil {
 		return nil, parse_err
@@ -680,11 +620,7 @@ func UnmarshalFeatureCollection(raw []byte) ([]*WOFFeature, error) {
 
 	for _, child := range children {
 
-		f := WOFFeature{
-			Parsed: child,
-		}
-
-		collection = append(collection, &f)
+		collection = append(collection, NewWOFFeature(child))
 	}
 
 	return collection, nil
@@ -695,15 +631,38 @@ func UnmarshalFeatureCollection(raw []byte) ([]*WOFFeature, error) {
 
 func UnmarshalFeature(raw []byte) (*WOFFeature, error) {
 
-	parsed, parse_err := gabs.ParseJSON(raw)
+	parsed, parse_err := parseJSON(raw)
 
 	if parse_err != nil {
 		return nil, parse_err
 	}
 
-	rsp := WOFFeature{
-		Parsed: parsed,
+	return NewWOFFeature(parsed), nil
+}
+
+// numbers are decoded as json.Number rather than float64 so that they can be written back out
+// exactly as they were read (see MarshalFeature); anything that wants the value of a number
+// should go through decodeNumber or IntValue rather than asserting a float64
+
+func parseJSON(raw []byte) (*gabs.Container, error) {
+
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	decoder.UseNumber()
+
+	parsed, err := gabs.ParseJSONDecoder(decoder)
+
+	if err != nil {
+		return nil, err
+	}
+
+	// unlike json.Unmarshal a decoder is happy to stop after the first value so make sure
+	// there's nothing else
+
+	_, err = decoder.Token()
+
+	if err != io.EOF {
+		return nil, errors.New("invalid JSON, unexpected data after the top-level value")
 	}
 
-	return &rsp, nil
+	return parsed, nil
 }
@@ -0,0 +1,655 @@
+package geojson
+
+import (
+	"fmt"
+	geo "github.com/kellydunn/golang-geo"
+	"math"
+	"sync"
+)
+
+/*
+
+Geometry is the typed version of a GeoJSON "geometry" dictionary. Features are decoded
+once (see WOFFeature.Geometry) and everything else - GeomToPolygons, Contains, EnSpatializeGeom -
+is built on top of the result rather than poking at the raw gabs container.
+
+Simple geometries (Point, LineString, Polygon) are the things we actually index and test
+against. Multi-geometries and GeometryCollections are just lists of simple geometries and
+can be flattened in to them (see Flatten).
+
+*/
+
+type Geometry interface {
+	Type() string
+	Polygons() []*WOFPolygon
+	Contains(latitude float64, longitude float64) bool
+}
+
+type Point struct {
+	Coordinate *geo.Point
+}
+
+func (g *Point) Type() string {
+	return "Point"
+}
+
+func (g *Point) Polygons() []*WOFPolygon {
+	return make([]*WOFPolygon, 0)
+}
+
+func (g *Point) Contains(latitude float64, longitude float64) bool {
+	return g.Coordinate.Lat() == latitude && g.Coordinate.Lng() == longitude
+}
+
+type MultiPoint struct {
+	Coordinates []*geo.Point
+}
+
+func (g *MultiPoint) Type() string {
+	return "MultiPoint"
+}
+
+func (g *MultiPoint) Polygons() []*WOFPolygon {
+	return make([]*WOFPolygon, 0)
+}
+
+func (g *MultiPoint) Contains(latitude float64, longitude float64) bool {
+
+	for _, pt := range g.Coordinates {
+
+		if pt.Lat() == latitude && pt.Lng() == longitude {
+			return true
+		}
+	}
+
+	return false
+}
+
+type LineString struct {
+	Coordinates []*geo.Point
+}
+
+func (g *LineString) Type() string {
+	return "LineString"
+}
+
+func (g *LineString) Polygons() []*WOFPolygon {
+	return make([]*WOFPolygon, 0)
+}
+
+// a line "contains" a point if the point falls on one of its segments
+
+func (g *LineString) Contains(latitude float64, longitude float64) bool {
+
+	count := len(g.Coordinates)
+
+	if count == 1 {
+		pt := g.Coordinates[0]
+		return pt.Lat() == latitude && pt.Lng() == longitude
+	}
+
+	for i := 1; i < count; i++ {
+
+		if onSegment(latitude, longitude, g.Coordinates[i-1], g.Coordinates[i]) {
+			return true
+		}
+	}
+
+	return false
+}
+
+type MultiLineString struct {
+	LineStrings []*LineString
+}
+
+func (g *MultiLineString) Type() string {
+	return "MultiLineString"
+}
+
+func (g *MultiLineString) Polygons() []*WOFPolygon {
+	return make([]*WOFPolygon, 0)
+}
+
+func (g *MultiLineString) Contains(latitude float64, longitude float64) bool {
+
+	for _, l := range g.LineStrings {
+
+		if l.Contains(latitude, longitude) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Contains, CountPoints and the rings themselves come along for free
+// with the embedded WOFPolygon
+
+type Polygon struct {
+	*WOFPolygon
+}
+
+func (g *Polygon) Type() string {
+	return "Polygon"
+}
+
+func (g *Polygon) Polygons() []*WOFPolygon {
+	return []*WOFPolygon{g.WOFPolygon}
+}
+
+type MultiPolygon struct {
+	WOFPolygons []*WOFPolygon
+}
+
+func (g *MultiPolygon) Type() string {
+	return "MultiPolygon"
+}
+
+func (g *MultiPolygon) Polygons() []*WOFPolygon {
+	return g.WOFPolygons
+}
+
+func (g *MultiPolygon) Contains(latitude float64, longitude float64) bool {
+
+	for _, p := range g.WOFPolygons {
+
+		if p.Contains(latitude, longitude) {
+			return true
+		}
+	}
+
+	return false
+}
+
+type GeometryCollection struct {
+	Geometries []Geometry
+}
+
+func (g *GeometryCollection) Type() string {
+	return "GeometryCollection"
+}
+
+func (g *GeometryCollection) Polygons() []*WOFPolygon {
+
+	polygons := make([]*WOFPolygon, 0)
+
+	for _, child := range g.Geometries {
+		polygons = append(polygons, child.Polygons()...)
+	}
+
+	return polygons
+}
+
+func (g *GeometryCollection) Contains(latitude float64, longitude float64) bool {
+
+	for _, child := range g.Geometries {
+
+		if child.Contains(latitude, longitude) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Flatten returns the simple geometries (Point, LineString and Polygon) that make up g
+// in document order. This is the list that the Offset property of the WOFSpatial objects
+// returned by EnSpatializeGeom indexes in to. For Polygons and MultiPolygons that means
+// Offset is the same as the index in to GeomToPolygons, which is what it has always been.
+
+func Flatten(g Geometry) []Geometry {
+
+	simple := make([]Geometry, 0)
+
+	switch geom := g.(type) {
+
+	case *MultiPoint:
+
+		for _, pt := range geom.Coordinates {
+			simple = append(simple, &Point{Coordinate: pt})
+		}
+
+	case *MultiLineString:
+
+		for _, l := range geom.LineStrings {
+			simple = append(simple, l)
+		}
+
+	case *MultiPolygon:
+
+		for _, p := range geom.WOFPolygons {
+			simple = append(simple, &Polygon{p})
+		}
+
+	case *GeometryCollection:
+
+		for _, child := range geom.Geometries {
+			simple = append(simple, Flatten(child)...)
+		}
+
+	case nil:
+		// pass
+
+	default:
+		simple = append(simple, g)
+	}
+
+	return simple
+}
+
+// CountPositions returns the total number of positions in g, including the ones in holes
+
+func CountPositions(g Geometry) int {
+
+	count := 0
+
+	for _, part := range Flatten(g) {
+
+		switch geom := part.(type) {
+		case *Point:
+			count += 1
+		case *LineString:
+			count += len(geom.Coordinates)
+		case *Polygon:
+			count += geom.CountPoints()
+		}
+	}
+
+	return count
+}
+
+// the points we care about when calculating the bounding box for a simple geometry
+// (for polygons that means the outer ring)
+
+func boundingPoints(g Geometry) []*geo.Point {
+
+	switch geom := g.(type) {
+	case *Point:
+		return []*geo.Point{geom.Coordinate}
+	case *LineString:
+		return geom.Coordinates
+	case *Polygon:
+		return geom.OuterRing.Points()
+	default:
+
+		points := make([]*geo.Point, 0)
+
+		for _, child := range Flatten(g) {
+			points = append(points, boundingPoints(child)...)
+		}
+
+		return points
+	}
+}
+
+func onSegment(latitude float64, longitude float64, start *geo.Point, end *geo.Point) bool {
+
+	epsilon := 1e-12
+
+	if longitude < math.Min(start.Lng(), end.Lng())-epsilon || longitude > math.Max(start.Lng(), end.Lng())+epsilon {
+		return false
+	}
+
+	if latitude < math.Min(start.Lat(), end.Lat())-epsilon || latitude > math.Max(start.Lat(), end.Lat())+epsilon {
+		return false
+	}
+
+	cross := (end.Lng()-start.Lng())*(latitude-start.Lat()) - (end.Lat()-start.Lat())*(longitude-start.Lng())
+	return math.Abs(cross) <= epsilon
+}
+
+// geometryCache is shared by all the copies of a WOFFeature (they are passed around by value)
+// so that the geometry is only ever decoded once
+
+type geometryCache struct {
+	mu       sync.Mutex
+	decoded  bool
+	geometry Geometry
+	err      error
+}
+
+func (c *geometryCache) get(raw func() interface{}) (Geometry, error) {
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if !c.decoded {
+		c.geometry, c.err = DecodeGeometry(raw())
+		c.decoded = true
+	}
+
+	return c.geometry, c.err
+}
+
+// reset throws away the decoded geometry so that it will be decoded again the next time it
+// is asked for (say, because it has been replaced)
+
+func (c *geometryCache) reset() {
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.decoded = false
+	c.geometry = nil
+	c.err = nil
+}
+
+// DecodeGeometry converts the (JSON-decoded) value of a GeoJSON "geometry" dictionary in to
+// a Geometry. A null geometry is treated as an empty GeometryCollection. Anything that can't
+// be decoded is reported as a *DecodeError whose Path is relative to the feature itself
+// (for example "geometry.coordinates[2][0][5][1]").
+
+func DecodeGeometry(raw interface{}) (Geometry, error) {
+
+	return decodeGeometry(raw, "geometry")
+}
+
+func decodeGeometry(raw interface{}, path string) (Geometry, error) {
+
+	if raw == nil {
+		return &GeometryCollection{Geometries: make([]Geometry, 0)}, nil
+	}
+
+	dict, ok := raw.(map[string]interface{})
+
+	if !ok {
+		return nil, expected(path, "dictionary", raw)
+	}
+
+	type_path := path + ".type"
+	geom_type, ok := dict["type"].(string)
+
+	if !ok {
+		return nil, expected(type_path, "string", dict["type"])
+	}
+
+	if geom_type == "GeometryCollection" {
+
+		children_path := path + ".geometries"
+		children, err := decodeList(dict["geometries"], children_path)
+
+		if err != nil {
+			return nil, err
+		}
+
+		geometries := make([]Geometry, 0)
+
+		for i, child := range children {
+
+			g, err := decodeGeometry(child, indexPath(children_path, i))
+
+			if err != nil {
+				return nil, err
+			}
+
+			geometries = append(geometries, g)
+		}
+
+		return &GeometryCollection{Geometries: geometries}, nil
+	}
+
+	coords_path := path + ".coordinates"
+	coordinates, err := decodeList(dict["coordinates"], coords_path)
+
+	if err != nil {
+		return nil, err
+	}
+
+	switch geom_type {
+
+	case "Point":
+
+		pt, err := decodePosition(coordinates, coords_path)
+
+		if err != nil {
+			return nil, err
+		}
+
+		return &Point{Coordinate: pt}, nil
+
+	case "MultiPoint":
+
+		points, err := decodePositions(coordinates, coords_path)
+
+		if err != nil {
+			return nil, err
+		}
+
+		return &MultiPoint{Coordinates: points}, nil
+
+	case "LineString":
+
+		points, err := decodePositions(coordinates, coords_path)
+
+		if err != nil {
+			return nil, err
+		}
+
+		return &LineString{Coordinates: points}, nil
+
+	case "MultiLineString":
+
+		lines := make([]*LineString, 0)
+
+		for i, icoords := range coordinates {
+
+			line_path := indexPath(coords_path, i)
+			coords, err := decodeList(icoords, line_path)
+
+			if err != nil {
+				return nil, err
+			}
+
+			points, err := decodePositions(coords, line_path)
+
+			if err != nil {
+				return nil, err
+			}
+
+			lines = append(lines, &LineString{Coordinates: points})
+		}
+
+		return &MultiLineString{LineStrings: lines}, nil
+
+	case "Polygon":
+
+		poly, err := decodePolygon(coordinates, coords_path)
+
+		if err != nil {
+			return nil, err
+		}
+
+		return &Polygon{poly}, nil
+
+	case "MultiPolygon":
+
+		polygons := make([]*WOFPolygon, 0)
+
+		for i, ipoly := range coordinates {
+
+			poly_path := indexPath(coords_path, i)
+			rings, err := decodeList(ipoly, poly_path)
+
+			if err != nil {
+				return nil, err
+			}
+
+			poly, err := decodePolygon(rings, poly_path)
+
+			if err != nil {
+				return nil, err
+			}
+
+			polygons = append(polygons, poly)
+		}
+
+		return &MultiPolygon{WOFPolygons: polygons}, nil
+
+	default:
+		return nil, &DecodeError{Path: type_path, Reason: fmt.Sprintf("unsupported geometry type '%s'", geom_type)}
+	}
+}
+
+func decodePolygon(coordinates []interface{}, path string) (*WOFPolygon, error) {
+
+	if len(coordinates) == 0 {
+		return nil, &DecodeError{Path: path, Reason: "polygon has no rings"}
+	}
+
+	rings := make([]geo.Polygon, 0)
+
+	for i, iring := range coordinates {
+
+		ring_path := indexPath(path, i)
+		coords, err := decodeList(iring, ring_path)
+
+		if err != nil {
+			return nil, err
+		}
+
+		points, err := decodePositions(coords, ring_path)
+
+		if err != nil {
+			return nil, err
+		}
+
+		rings = append(rings, *geo.NewPolygon(points))
+	}
+
+	poly := WOFPolygon{
+		OuterRing:     rings[0],
+		InteriorRings: rings[1:],
+	}
+
+	return &poly, nil
+}
+
+func decodePositions(coordinates []interface{}, path string) ([]*geo.Point, error) {
+
+	points := make([]*geo.Point, 0)
+
+	for i, icoords := range coordinates {
+
+		pt_path := indexPath(path, i)
+		coords, err := decodeList(icoords, pt_path)
+
+		if err != nil {
+			return nil, err
+		}
+
+		pt, err := decodePosition(coords, pt_path)
+
+		if err != nil {
+			return nil, err
+		}
+
+		points = append(points, pt)
+	}
+
+	return points, nil
+}
+
+func decodePosition(coords []interface{}, path string) (*geo.Point, error) {
+
+	if len(coords) < 2 {
+		return nil, &DecodeError{Path: path, Reason: fmt.Sprintf("expected at least 2 numbers, got %d", len(coords))}
+	}
+
+	lon, err := decodeNumber(coords[0], indexPath(path, 0))
+
+	if err != nil {
+		return nil, err
+	}
+
+	lat, err := decodeNumber(coords[1], indexPath(path, 1))
+
+	if err != nil {
+		return nil, err
+	}
+
+	return geo.NewPoint(lat, lon), nil
+}
+
+// EncodeGeometry is the opposite of DecodeGeometry and returns something that can be handed to
+// gabs (or encoding/json) and written back in to a feature's "geometry" property
+
+func EncodeGeometry(g Geometry) map[string]interface{} {
+
+	encoded := map[string]interface{}{
+		"type": g.Type(),
+	}
+
+	switch geom := g.(type) {
+
+	case *Point:
+		encoded["coordinates"] = encodePosition(geom.Coordinate)
+
+	case *MultiPoint:
+		encoded["coordinates"] = encodePositions(geom.Coordinates)
+
+	case *LineString:
+		encoded["coordinates"] = encodePositions(geom.Coordinates)
+
+	case *MultiLineString:
+
+		lines := make([]interface{}, 0)
+
+		for _, l := range geom.LineStrings {
+			lines = append(lines, encodePositions(l.Coordinates))
+		}
+
+		encoded["coordinates"] = lines
+
+	case *Polygon:
+		encoded["coordinates"] = encodePolygon(geom.WOFPolygon)
+
+	case *MultiPolygon:
+
+		polygons := make([]interface{}, 0)
+
+		for _, p := range geom.WOFPolygons {
+			polygons = append(polygons, encodePolygon(p))
+		}
+
+		encoded["coordinates"] = polygons
+
+	case *GeometryCollection:
+
+		geometries := make([]interface{}, 0)
+
+		for _, child := range geom.Geometries {
+			geometries = append(geometries, EncodeGeometry(child))
+		}
+
+		encoded["geometries"] = geometries
+	}
+
+	return encoded
+}
+
+func encodePolygon(p *WOFPolygon) []interface{} {
+
+	rings := make([]interface{}, 0)
+	rings = append(rings, encodePositions(p.OuterRing.Points()))
+
+	for _, r := range p.InteriorRings {
+		rings = append(rings, encodePositions(r.Points()))
+	}
+
+	return rings
+}
+
+func encodePositions(points []*geo.Point) []interface{} {
+
+	coords := make([]interface{}, 0)
+
+	for _, pt := range points {
+		coords = append(coords, encodePosition(pt))
+	}
+
+	return coords
+}
+
+func encodePosition(pt *geo.Point) []interface{} {
+	return []interface{}{pt.Lng(), pt.Lat()}
+}
@@ -0,0 +1,181 @@
+package geojson
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestDecodeGeometry(t *testing.T) {
+
+	tests := []struct {
+		geometry  string
+		type_     string
+		positions int
+		polygons  int
+		simple    []string
+	}{
+		{`{"type":"Point","coordinates":[1,2]}`, "Point", 1, 0, []string{"Point"}},
+		{`{"type":"Point","coordinates":[1,2,30]}`, "Point", 1, 0, []string{"Point"}},
+		{`{"type":"MultiPoint","coordinates":[[1,2],[3,4]]}`, "MultiPoint", 2, 0, []string{"Point", "Point"}},
+		{`{"type":"LineString","coordinates":[[1,2],[3,4],[5,6]]}`, "LineString", 3, 0, []string{"LineString"}},
+		{`{"type":"MultiLineString","coordinates":[[[1,2],[3,4]],[[5,6],[7,8]]]}`, "MultiLineString", 4, 0, []string{"LineString", "LineString"}},
+		{`{"type":"Polygon","coordinates":[[[0,0],[4,0],[4,4],[0,4],[0,0]],[[1,1],[1,2],[2,2],[2,1],[1,1]]]}`, "Polygon", 10, 1, []string{"Polygon"}},
+		{`{"type":"MultiPolygon","coordinates":[[[[0,0],[1,0],[1,1],[0,0]]],[[[5,5],[6,5],[6,6],[5,5]]]]}`, "MultiPolygon", 8, 2, []string{"Polygon", "Polygon"}},
+		{`{"type":"GeometryCollection","geometries":[{"type":"Point","coordinates":[1,2]},{"type":"MultiPolygon","coordinates":[[[[0,0],[1,0],[1,1],[0,0]]]]},{"type":"GeometryCollection","geometries":[{"type":"LineString","coordinates":[[1,2],[3,4]]}]}]}`, "GeometryCollection", 7, 1, []string{"Point", "Polygon", "LineString"}},
+		{`null`, "GeometryCollection", 0, 0, []string{}},
+	}
+
+	for _, test := range tests {
+
+		g := testGeometry(t, test.geometry)
+
+		if g.Type() != test.type_ {
+			t.Errorf("expected %s to be a %s, got %s", test.geometry, test.type_, g.Type())
+		}
+
+		if CountPositions(g) != test.positions {
+			t.Errorf("expected %s to have %d positions, got %d", test.geometry, test.positions, CountPositions(g))
+		}
+
+		if len(g.Polygons()) != test.polygons {
+			t.Errorf("expected %s to have %d polygons, got %d", test.geometry, test.polygons, len(g.Polygons()))
+		}
+
+		simple := make([]string, 0)
+
+		for _, part := range Flatten(g) {
+			simple = append(simple, part.Type())
+		}
+
+		if !reflect.DeepEqual(simple, test.simple) {
+			t.Errorf("expected %s to flatten to %v, got %v", test.geometry, test.simple, simple)
+		}
+	}
+}
+
+func TestEncodeGeometry(t *testing.T) {
+
+	for _, geometry := range []string{
+		`{"coordinates":[1.5,-2],"type":"Point"}`,
+		`{"coordinates":[[1,2],[3,4]],"type":"MultiPoint"}`,
+		`{"coordinates":[[1,2],[3,4]],"type":"LineString"}`,
+		`{"coordinates":[[[1,2],[3,4]],[[5,6],[7,8]]],"type":"MultiLineString"}`,
+		`{"coordinates":[[[0,0],[4,0],[4,4],[0,0]],[[1,1],[1,2],[2,2],[1,1]]],"type":"Polygon"}`,
+		`{"coordinates":[[[[0,0],[1,0],[1,1],[0,0]]],[[[5,5],[6,5],[6,6],[5,5]]]],"type":"MultiPolygon"}`,
+		`{"geometries":[{"coordinates":[1,2],"type":"Point"}],"type":"GeometryCollection"}`,
+	} {
+
+		g := testGeometry(t, geometry)
+
+		encoded, err := json.Marshal(EncodeGeometry(g))
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if string(encoded) != geometry {
+			t.Errorf("expected %s to be encoded as itself, got %s", geometry, encoded)
+		}
+	}
+}
+
+func TestGeometryContains(t *testing.T) {
+
+	tests := []struct {
+		geometry string
+		lat      float64
+		lon      float64
+		expected bool
+	}{
+		{`{"type":"Point","coordinates":[1,2]}`, 2, 1, true},
+		{`{"type":"Point","coordinates":[1,2]}`, 1, 2, false},
+		{`{"type":"MultiPoint","coordinates":[[1,2],[3,4]]}`, 4, 3, true},
+		{`{"type":"LineString","coordinates":[[0,0],[2,2]]}`, 1, 1, true},
+		{`{"type":"LineString","coordinates":[[0,0],[2,2]]}`, 1, 1.1, false},
+		{`{"type":"LineString","coordinates":[[0,0],[2,2]]}`, 3, 3, false},
+		{`{"type":"MultiLineString","coordinates":[[[0,0],[2,0]],[[0,5],[2,5]]]}`, 5, 1, true},
+		{`{"type":"Polygon","coordinates":[[[0,0],[4,0],[4,4],[0,4],[0,0]],[[1,1],[1,2],[2,2],[2,1],[1,1]]]}`, 3, 3, true},
+		{`{"type":"Polygon","coordinates":[[[0,0],[4,0],[4,4],[0,4],[0,0]],[[1,1],[1,2],[2,2],[2,1],[1,1]]]}`, 1.5, 1.5, false},
+		{`{"type":"Polygon","coordinates":[[[0,0],[4,0],[4,4],[0,4],[0,0]]]}`, 5, 5, false},
+		{`{"type":"MultiPolygon","coordinates":[[[[0,0],[1,0],[1,1],[0,1],[0,0]]],[[[5,5],[6,5],[6,6],[5,6],[5,5]]]]}`, 5.5, 5.5, true},
+		{`{"type":"GeometryCollection","geometries":[{"type":"Point","coordinates":[9,9]},{"type":"Polygon","coordinates":[[[0,0],[1,0],[1,1],[0,1],[0,0]]]}]}`, 0.5, 0.5, true},
+		{`null`, 0, 0, false},
+	}
+
+	for _, test := range tests {
+
+		f := geometryFeature(t, test.geometry)
+
+		g, err := f.Geometry()
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if g.Contains(test.lat, test.lon) != test.expected {
+			t.Errorf("expected %s Contains(%v, %v) to be %t", test.geometry, test.lat, test.lon, test.expected)
+		}
+
+		if f.Contains(test.lat, test.lon) != test.expected {
+			t.Errorf("expected the feature for %s Contains(%v, %v) to be %t", test.geometry, test.lat, test.lon, test.expected)
+		}
+	}
+}
+
+func TestGeometryIsCached(t *testing.T) {
+
+	f := geometryFeature(t, `{"type":"Point","coordinates":[1,2]}`)
+
+	a, err := f.Geometry()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// WOFFeature methods have value receivers so copies need to share the cache
+
+	dupe := *f
+
+	b, err := dupe.Geometry()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if a != b {
+		t.Error("expected the geometry to only be decoded once")
+	}
+}
+
+func TestEnSpatializeGeom(t *testing.T) {
+
+	f := testFeature(t, `{"type":"Feature","id":1,"properties":{"wof:id":1,"wof:name":"x","wof:placetype":"region","wof:superseded_by":[]},"geometry":{"type":"GeometryCollection","geometries":[
+		{"type":"Point","coordinates":[1,2]},
+		{"type":"Polygon","coordinates":[[[177,-20],[-178,-20],[-178,-15],[177,-15],[177,-20]]]},
+		{"type":"LineString","coordinates":[[0,0],[3,4]]}
+	]}}`)
+
+	spatial, err := f.EnSpatializeGeom()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the polygon crosses the antimeridian so it gets two
+
+	offsets := make([]int, 0)
+
+	for _, sp := range spatial {
+
+		offsets = append(offsets, sp.Offset)
+
+		if sp.Id != 1 || sp.Name != "x" || sp.Placetype != "region" {
+			t.Errorf("unexpected WOFSpatial %v", sp)
+		}
+	}
+
+	if !reflect.DeepEqual(offsets, []int{0, 1, 1, 2}) {
+		t.Errorf("expected offsets [0 1 1 2], got %v", offsets)
+	}
+}
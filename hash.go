@@ -0,0 +1,73 @@
+package geojson
+
+import (
+	"bytes"
+	"crypto/md5"
+	"encoding/hex"
+)
+
+/*
+
+Geometry hashes, as stored in the geom:hash (and in older records wof:geomhash) property. The
+hash is the MD5 of the geometry encoded as JSON the way the WOF export tools do it, which is
+Python's json.dumps(geom, sort_keys=True, separators=(',', ':')): no whitespace, keys sorted
+and numbers written the way MarshalFeature writes them.
+
+GeometryHash works from the numbers as they were written in the file (see parseJSON) so a
+coordinate written as 45.0 is hashed as 45.0, the same as the export tools do. HashGeometry
+works from a decoded Geometry, where it's a float64 and there's no telling 45.0 apart from 45,
+so it's hashed as 45; use GeometryHash to check a stored hash.
+
+*/
+
+// the properties a geometry hash may be stored in, preferred one first
+
+var geometryHashProperties = []string{
+	"geom:hash",
+	"wof:geomhash",
+}
+
+// HashGeometry returns the hash of g, as it would be written out by EncodeGeometry (so
+// coordinates with no fractional part are hashed as integers)
+
+func HashGeometry(g Geometry) (string, error) {
+	return hashRawGeometry(EncodeGeometry(g))
+}
+
+// GeometryHash returns the hash of the feature's geometry exactly as it is, rather than as
+// it's been decoded, which is what you want when checking a stored hash
+
+func (wof WOFFeature) GeometryHash() (string, error) {
+	return hashRawGeometry(wof.Parsed.S("geometry").Data())
+}
+
+// StoredGeometryHash returns the value of the geom:hash property, or wof:geomhash if there
+// isn't one
+
+func (wof WOFFeature) StoredGeometryHash() (string, bool) {
+
+	for _, prop := range geometryHashProperties {
+
+		hash, ok := wof.StringProperty(prop)
+
+		if ok {
+			return hash, true
+		}
+	}
+
+	return "", false
+}
+
+func hashRawGeometry(geom interface{}) (string, error) {
+
+	var buf bytes.Buffer
+
+	err := writeCompact(&buf, geom, "geometry")
+
+	if err != nil {
+		return "", err
+	}
+
+	sum := md5.Sum(buf.Bytes())
+	return hex.EncodeToString(sum[:]), nil
+}
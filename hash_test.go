@@ -0,0 +1,98 @@
+package geojson
+
+import (
+	"testing"
+)
+
+// the expected hashes were computed with the same json.dumps call the export tools use
+
+var hashTests = []struct {
+	geometry string
+	expected string
+}{
+	{`{"type":"Polygon","coordinates":[[[0,0],[2,0],[2,2],[0,2],[0,0]]]}`, "48a788eb00a53820409c2bbf2f126517"},
+	{`{"type": "Point", "coordinates": [-73.574292, 45.510076]}`, "ce0892d39e99daf852d857676afb2186"},
+	{`{"coordinates":[[0.00001,-0.5],[179.999999,1e-7]],"type":"LineString"}`, "75c98ab6f7d1280b250cc6e554ba7084"},
+}
+
+func TestHashGeometry(t *testing.T) {
+
+	for _, test := range hashTests {
+
+		g := testGeometry(t, test.geometry)
+
+		hash, err := HashGeometry(g)
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if hash != test.expected {
+			t.Errorf("expected HashGeometry(%s) to be %s, got %s", test.geometry, test.expected, hash)
+		}
+
+		f := geometryFeature(t, test.geometry)
+
+		hash, err = f.GeometryHash()
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if hash != test.expected {
+			t.Errorf("expected GeometryHash for %s to be %s, got %s", test.geometry, test.expected, hash)
+		}
+	}
+}
+
+func TestGeometryHashIntegralFloats(t *testing.T) {
+
+	tests := []struct {
+		geometry string
+		expected string
+	}{
+		{`{"type":"Point","coordinates":[-73.0,45.0]}`, "ec1c25b3ed637f32b2ceeaf55d721f93"},
+		{`{"type":"Point","coordinates":[-73,45]}`, "c04fcf267696170d32f1484f88ea3a3d"},
+		{`{"type":"Polygon","coordinates":[[[0.0,0.0],[2.0,0.0],[2.0,2.0],[0.0,2.0],[0.0,0.0]]]}`, "5c2d0e0d6a26e12be7fc7daee9e032a5"},
+	}
+
+	for _, test := range tests {
+
+		f := geometryFeature(t, test.geometry)
+
+		hash, err := f.GeometryHash()
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if hash != test.expected {
+			t.Errorf("expected GeometryHash for %s to be %s, got %s", test.geometry, test.expected, hash)
+		}
+	}
+}
+
+func TestStoredGeometryHash(t *testing.T) {
+
+	tests := []struct {
+		properties string
+		expected   string
+		ok         bool
+	}{
+		{`{"geom:hash":"a","wof:geomhash":"b"}`, "a", true},
+		{`{"wof:geomhash":"b"}`, "b", true},
+		{`{"geom:hash":1}`, "", false},
+		{`{}`, "", false},
+	}
+
+	for _, test := range tests {
+
+		f := testFeature(t, `{"type":"Feature","properties":`+test.properties+`,"geometry":null}`)
+
+		hash, ok := f.StoredGeometryHash()
+
+		if ok != test.ok || hash != test.expected {
+			t.Errorf("expected StoredGeometryHash for %s to be %q (%t), got %q (%t)", test.properties, test.expected, test.ok, hash, ok)
+		}
+	}
+}
@@ -0,0 +1,48 @@
+package geojson
+
+import (
+	"testing"
+)
+
+// helpers shared by the tests in this package
+
+func testFeature(t testing.TB, body string) *WOFFeature {
+
+	f, err := UnmarshalFeature([]byte(body))
+
+	if err != nil {
+		t.Fatalf("failed to parse test feature, because %s", err)
+	}
+
+	return f
+}
+
+// geometryFeature returns a feature with no properties and the given geometry
+
+func geometryFeature(t testing.TB, geometry string) *WOFFeature {
+	return testFeature(t, `{"type":"Feature","properties":{},"geometry":`+geometry+`}`)
+}
+
+func testGeometry(t testing.TB, body string) Geometry {
+
+	f := geometryFeature(t, body)
+
+	g, err := f.Geometry()
+
+	if err != nil {
+		t.Fatalf("failed to decode test geometry, because %s", err)
+	}
+
+	return g
+}
+
+func almostEqual(a float64, b float64, tolerance float64) bool {
+
+	diff := a - b
+
+	if diff < 0.0 {
+		diff = -diff
+	}
+
+	return diff <= tolerance
+}
@@ -0,0 +1,186 @@
+package index
+
+import (
+	rtreego "github.com/dhconnelly/rtreego"
+	geojson "github.com/whosonfirst/go-whosonfirst-geojson"
+)
+
+/*
+
+Filters for GetByLatLon. They are plain rtreego.Filter functions so anything rtreego provides
+(like rtreego.LimitFilter) works too. Passing more than one filter means a record has to get
+past all of them; use AnyFilter and NotFilter to build other combinations. For example, "which
+current localities contain this point?" is:
+
+	idx.GetByLatLon(lat, lon, index.PlacetypeFilter("locality"), idx.IsCurrentFilter(1))
+
+Filters are applied to candidates before the (expensive) exact containment test and results
+only ever contains records that have passed it, so rtreego.LimitFilter limits the number of
+records that are actually returned.
+
+Filters that need to look at the feature itself (IsCurrentFilter, FeatureFilter) are methods on
+the Index they are used with.
+
+*/
+
+// SpatialFilter returns a filter that refuses any record for which fn returns false
+
+func SpatialFilter(fn func(sp *geojson.WOFSpatial) bool) rtreego.Filter {
+
+	return func(results []rtreego.Spatial, object rtreego.Spatial) (bool, bool) {
+
+		sp, ok := object.(*geojson.WOFSpatial)
+
+		if !ok {
+			return true, false
+		}
+
+		return !fn(sp), false
+	}
+}
+
+// PlacetypeFilter only allows records with one of the given placetypes
+
+func PlacetypeFilter(placetypes ...string) rtreego.Filter {
+
+	allowed := make(map[string]bool)
+
+	for _, pt := range placetypes {
+		allowed[pt] = true
+	}
+
+	return SpatialFilter(func(sp *geojson.WOFSpatial) bool {
+		return allowed[sp.Placetype]
+	})
+}
+
+// ExcludePlacetypeFilter refuses records with any of the given placetypes
+
+func ExcludePlacetypeFilter(placetypes ...string) rtreego.Filter {
+	return NotFilter(PlacetypeFilter(placetypes...))
+}
+
+func NotDeprecatedFilter() rtreego.Filter {
+
+	return SpatialFilter(func(sp *geojson.WOFSpatial) bool {
+		return !sp.Deprecated
+	})
+}
+
+func NotSupersededFilter() rtreego.Filter {
+
+	return SpatialFilter(func(sp *geojson.WOFSpatial) bool {
+		return !sp.Superseded
+	})
+}
+
+// IsCurrentFilter only allows records whose mz:is_current property is one of states (1 means
+// current, 0 means not current and -1 means we don't know). Records without an mz:is_current
+// property are treated as -1.
+
+func (idx *Index) IsCurrentFilter(states ...int) rtreego.Filter {
+
+	allowed := make(map[int]bool)
+
+	for _, s := range states {
+		allowed[s] = true
+	}
+
+	return idx.FeatureFilter(func(f *geojson.WOFFeature) bool {
+
+		current, ok := f.IntProperty("mz:is_current")
+
+		if !ok {
+			current = -1
+		}
+
+		return allowed[current]
+	})
+}
+
+// FeatureFilter returns a filter that refuses any record whose feature fn returns false for
+
+func (idx *Index) FeatureFilter(fn func(f *geojson.WOFFeature) bool) rtreego.Filter {
+
+	return SpatialFilter(func(sp *geojson.WOFSpatial) bool {
+
+		// Note that we don't (and can't) lock idx.mu here because filters are
+		// only ever called from inside GetByLatLon which is already holding it
+
+		prepared, ok := idx.features[sp.Id]
+
+		if !ok {
+			return false
+		}
+
+		return fn(prepared.Feature)
+	})
+}
+
+// AnyFilter allows a record if any one of filters does. It aborts only if they all do.
+
+func AnyFilter(filters ...rtreego.Filter) rtreego.Filter {
+
+	return func(results []rtreego.Spatial, object rtreego.Spatial) (bool, bool) {
+
+		if len(filters) == 0 {
+			return false, false
+		}
+
+		refuse := true
+		abort := true
+
+		for _, fn := range filters {
+
+			ref, abt := fn(results, object)
+
+			if !ref {
+				refuse = false
+			}
+
+			if !abt {
+				abort = false
+			}
+		}
+
+		return refuse, abort
+	}
+}
+
+// NotFilter allows whatever filter refuses (and vice versa). It never aborts.
+
+func NotFilter(filter rtreego.Filter) rtreego.Filter {
+
+	return func(results []rtreego.Spatial, object rtreego.Spatial) (bool, bool) {
+
+		refuse, _ := filter(results, object)
+		return !refuse, false
+	}
+}
+
+// the same consensus rules as rtreego uses internally
+
+func applyFilters(results []rtreego.Spatial, object rtreego.Spatial, filters []rtreego.Filter) (bool, bool) {
+
+	refuse := false
+	abort := false
+
+	for _, fn := range filters {
+
+		ref, abt := fn(results, object)
+
+		if ref {
+			refuse = true
+		}
+
+		if abt {
+			abort = true
+		}
+
+		if refuse && abort {
+			break
+		}
+	}
+
+	return refuse, abort
+}
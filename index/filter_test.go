@@ -0,0 +1,90 @@
+package index
+
+import (
+	rtreego "github.com/dhconnelly/rtreego"
+	geojson "github.com/whosonfirst/go-whosonfirst-geojson"
+	"testing"
+)
+
+// everything contains the point (0.5, 0.5)
+
+func filterIndex(t *testing.T) *Index {
+
+	unit := square(0, 0, 1, 1)
+
+	return testIndex(t,
+		testFeature(t, 1, "country", `,"mz:is_current":1`, unit),
+		testFeature(t, 2, "region", `,"mz:is_current":0,"edtf:deprecated":"2017-01-01"`, unit),
+		testFeature(t, 3, "locality", `,"mz:is_current":1,"edtf:deprecated":"uuuu"`, unit),
+		testFeature(t, 4, "locality", `,"wof:superseded_by":[5]`, unit),
+		testFeature(t, 5, "locality", `,"mz:is_current":"1","edtf:superseded":"2018-02-03"`, unit),
+		testFeature(t, 6, "neighbourhood", `,"mz:is_current":-1`, unit),
+	)
+}
+
+func TestFilters(t *testing.T) {
+
+	idx := filterIndex(t)
+
+	tests := []struct {
+		name     string
+		filters  []rtreego.Filter
+		expected []int
+	}{
+		{"none", []rtreego.Filter{}, []int{1, 2, 3, 4, 5, 6}},
+		{"placetype", []rtreego.Filter{PlacetypeFilter("locality")}, []int{3, 4, 5}},
+		{"placetypes", []rtreego.Filter{PlacetypeFilter("country", "region")}, []int{1, 2}},
+		{"exclude placetype", []rtreego.Filter{ExcludePlacetypeFilter("locality", "country")}, []int{2, 6}},
+		{"not deprecated", []rtreego.Filter{NotDeprecatedFilter()}, []int{1, 3, 4, 5, 6}},
+		{"not superseded", []rtreego.Filter{NotSupersededFilter()}, []int{1, 2, 3, 6}},
+		{"current", []rtreego.Filter{idx.IsCurrentFilter(1)}, []int{1, 3, 5}},
+		{"not current", []rtreego.Filter{idx.IsCurrentFilter(0)}, []int{2}},
+		{"unknown", []rtreego.Filter{idx.IsCurrentFilter(-1)}, []int{4, 6}},
+		{"current or unknown", []rtreego.Filter{idx.IsCurrentFilter(1, -1)}, []int{1, 3, 4, 5, 6}},
+		{"all of", []rtreego.Filter{PlacetypeFilter("locality"), NotSupersededFilter(), idx.IsCurrentFilter(1)}, []int{3}},
+		{"any of", []rtreego.Filter{AnyFilter(PlacetypeFilter("country"), NotFilter(NotDeprecatedFilter()))}, []int{1, 2}},
+		{"any of nothing", []rtreego.Filter{AnyFilter()}, []int{1, 2, 3, 4, 5, 6}},
+		{"not", []rtreego.Filter{NotFilter(PlacetypeFilter("locality"))}, []int{1, 2, 6}},
+		{"feature", []rtreego.Filter{idx.FeatureFilter(func(f *geojson.WOFFeature) bool { return f.Id()%2 == 0 })}, []int{2, 4, 6}},
+		{"spatial", []rtreego.Filter{SpatialFilter(func(sp *geojson.WOFSpatial) bool { return sp.Id > 4 })}, []int{5, 6}},
+	}
+
+	for _, test := range tests {
+
+		ids := spatialIds(idx.GetByLatLon(0.5, 0.5, test.filters...))
+
+		if !sameIds(ids, test.expected) {
+			t.Errorf("%s: expected %v, got %v", test.name, test.expected, ids)
+		}
+	}
+}
+
+func TestLimitFilter(t *testing.T) {
+
+	idx := filterIndex(t)
+
+	// the limit applies to records that have passed the containment test and every other
+	// filter, not to the candidates that come out of the Rtree
+
+	tests := []struct {
+		filters  []rtreego.Filter
+		expected int
+	}{
+		{[]rtreego.Filter{rtreego.LimitFilter(2)}, 2},
+		{[]rtreego.Filter{PlacetypeFilter("locality"), rtreego.LimitFilter(2)}, 2},
+		{[]rtreego.Filter{PlacetypeFilter("region"), rtreego.LimitFilter(2)}, 1},
+	}
+
+	for _, test := range tests {
+
+		results := idx.GetByLatLon(0.5, 0.5, test.filters...)
+
+		if len(results) != test.expected {
+			t.Errorf("expected %d results, got %d", test.expected, len(results))
+		}
+	}
+
+	if len(idx.GetByLatLon(5.0, 5.0, rtreego.LimitFilter(2))) != 0 {
+		t.Error("expected nothing outside the features")
+	}
+}
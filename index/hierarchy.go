@@ -0,0 +1,109 @@
+package index
+
+import (
+	"fmt"
+	rtreego "github.com/dhconnelly/rtreego"
+	geojson "github.com/whosonfirst/go-whosonfirst-geojson"
+	"sort"
+)
+
+// GetHierarchyByLatLon finds every feature containing the point (that gets past filters) and
+// assembles the results in to a wof:hierarchy - that is a list of dictionaries mapping keys
+// like "locality_id" and "region_id" to WOF IDs, which is the same shape as WOFFeature.Hierarchy.
+// When more than one feature with the same placetype contains the point the most specific one
+// (the one with the smallest bounding box for the polygon that matched) wins. Records whose
+// placetype isn't a valid placetype (see geojson.IsValidPlacetype) are left out.
+//
+// This returns an empty list if nothing contains the point and a list with one hierarchy in
+// it otherwise.
+
+func (idx *Index) GetHierarchyByLatLon(latitude float64, longitude float64, filters ...rtreego.Filter) []map[string]int {
+
+	hierarchies := make([]map[string]int, 0)
+
+	results := idx.GetByLatLon(latitude, longitude, filters...)
+
+	if len(results) == 0 {
+		return hierarchies
+	}
+
+	// sort the matches so that, for any given placetype, the most specific record
+	// comes first; ties are broken on ID so that the answer is always the same
+
+	sort.Slice(results, func(i, j int) bool {
+
+		a := results[i]
+		b := results[j]
+
+		if a.Placetype != b.Placetype {
+			return a.Placetype < b.Placetype
+		}
+
+		area_a := boundsArea(a.Bounds())
+		area_b := boundsArea(b.Bounds())
+
+		if area_a != area_b {
+			return area_a < area_b
+		}
+
+		return a.Id < b.Id
+	})
+
+	hier := make(map[string]int)
+
+	for _, sp := range results {
+
+		if !geojson.IsValidPlacetype(sp.Placetype) {
+			continue
+		}
+
+		key := fmt.Sprintf("%s_id", sp.Placetype)
+
+		_, ok := hier[key]
+
+		if ok {
+			continue
+		}
+
+		hier[key] = sp.Id
+	}
+
+	hierarchies = append(hierarchies, hier)
+	return hierarchies
+}
+
+// GetParentByLatLon returns the most specific record containing the point (according to
+// geojson.PlacetypeRank) or false if nothing does. Records whose placetype isn't part of the
+// hierarchy (see geojson.HierarchyPlacetypes), like timezones and postal codes, are ignored.
+
+func (idx *Index) GetParentByLatLon(latitude float64, longitude float64, filters ...rtreego.Filter) (*geojson.WOFSpatial, bool) {
+
+	var parent *geojson.WOFSpatial
+	parent_rank := -1
+
+	for _, sp := range idx.GetByLatLon(latitude, longitude, filters...) {
+
+		rank, ok := geojson.PlacetypeRank(sp.Placetype)
+
+		if !ok {
+			continue
+		}
+
+		if rank < parent_rank {
+			continue
+		}
+
+		if rank == parent_rank && boundsArea(sp.Bounds()) >= boundsArea(parent.Bounds()) {
+			continue
+		}
+
+		parent = sp
+		parent_rank = rank
+	}
+
+	return parent, parent != nil
+}
+
+func boundsArea(rect *rtreego.Rect) float64 {
+	return rect.LengthsCoord(0) * rect.LengthsCoord(1)
+}
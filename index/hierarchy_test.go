@@ -0,0 +1,121 @@
+package index
+
+import (
+	"reflect"
+	"testing"
+)
+
+func hierarchyIndex(t *testing.T) *Index {
+
+	return testIndex(t,
+		testFeature(t, 1, "country", "", square(0, 0, 10, 10)),
+		testFeature(t, 2, "region", "", square(0, 0, 5, 10)),
+		testFeature(t, 3, "region", "", square(0, 0, 8, 10)), // overlaps 2 but is bigger
+		testFeature(t, 4, "locality", "", square(1, 1, 2, 2)),
+		testFeature(t, 5, "neighbourhood", "", square(1, 1, 1.5, 1.5)),
+		testFeature(t, 6, "neighbourhood", "", square(1, 1, 1.5, 1.5)), // the same size as 5
+		testFeature(t, 7, "microhood", `,"mz:is_current":0`, square(1.1, 1.1, 1.2, 1.2)),
+		testFeature(t, 8, "nonsense", "", square(0, 0, 10, 10)),
+		testFeature(t, 9, "timezone", "", square(0, 0, 10, 10)),
+		testFeature(t, 10, "postalcode", "", square(1.7, 1.7, 1.9, 1.9)), // inside 4 but smaller
+	)
+}
+
+func TestGetHierarchyByLatLon(t *testing.T) {
+
+	idx := hierarchyIndex(t)
+
+	tests := []struct {
+		lat      float64
+		lon      float64
+		expected []map[string]int
+	}{
+		{1.15, 1.15, []map[string]int{{
+			"country_id":       1,
+			"region_id":        2,
+			"locality_id":      4,
+			"neighbourhood_id": 5,
+			"microhood_id":     7,
+			"timezone_id":      9,
+		}}},
+		{1.8, 1.8, []map[string]int{{
+			"country_id":    1,
+			"region_id":     2,
+			"locality_id":   4,
+			"postalcode_id": 10,
+			"timezone_id":   9,
+		}}},
+		{1.8, 6, []map[string]int{{
+			"country_id":  1,
+			"region_id":   3,
+			"timezone_id": 9,
+		}}},
+		{20, 20, []map[string]int{}},
+	}
+
+	for _, test := range tests {
+
+		hier := idx.GetHierarchyByLatLon(test.lat, test.lon)
+
+		if !reflect.DeepEqual(hier, test.expected) {
+			t.Errorf("expected the hierarchy for %v, %v to be %v, got %v", test.lat, test.lon, test.expected, hier)
+		}
+	}
+
+	// filters are applied before the hierarchy is put together
+
+	hier := idx.GetHierarchyByLatLon(1.15, 1.15, idx.IsCurrentFilter(-1))
+
+	if len(hier) != 1 || hier[0]["microhood_id"] != 0 || hier[0]["locality_id"] != 4 {
+		t.Errorf("expected the non-current microhood to be filtered out, got %v", hier)
+	}
+}
+
+func TestGetParentByLatLon(t *testing.T) {
+
+	idx := hierarchyIndex(t)
+
+	tests := []struct {
+		lat      float64
+		lon      float64
+		expected int
+		ok       bool
+	}{
+		{1.15, 1.15, 7, true},
+		{1.3, 1.3, 5, true},
+		{1.8, 1.8, 4, true},
+		{1.8, 6, 3, true},
+		{20, 20, 0, false},
+	}
+
+	for _, test := range tests {
+
+		parent, ok := idx.GetParentByLatLon(test.lat, test.lon)
+
+		if ok != test.ok {
+			t.Errorf("expected GetParentByLatLon(%v, %v) to return %t", test.lat, test.lon, test.ok)
+			continue
+		}
+
+		if ok && parent.Id != test.expected {
+			t.Errorf("expected the parent of %v, %v to be %d, got %d", test.lat, test.lon, test.expected, parent.Id)
+		}
+	}
+
+	parent, ok := idx.GetParentByLatLon(1.15, 1.15, PlacetypeFilter("country", "region"))
+
+	if !ok || parent.Id != 2 {
+		t.Errorf("expected the most specific region to be the parent, got %v", parent)
+	}
+
+	// timezones and postal codes overlap the hierarchy but aren't part of it
+
+	for _, pt := range []string{"nonsense", "timezone", "postalcode"} {
+
+		_, ok = idx.GetParentByLatLon(1.8, 1.8, PlacetypeFilter(pt))
+
+		if ok {
+			t.Errorf("expected records with the placetype %s to be ignored", pt)
+		}
+	}
+}
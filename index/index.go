@@ -0,0 +1,182 @@
+package index
+
+import (
+	rtreego "github.com/dhconnelly/rtreego"
+	geojson "github.com/whosonfirst/go-whosonfirst-geojson"
+	"sync"
+)
+
+/*
+
+Index is a point-in-polygon index for WOF features. It is the glue that every consumer of
+WOFSpatial used to have to write for themselves:
+
+- each feature is EnSpatializeGeom-ed and the resulting WOFSpatial thing-ies (one per simple
+  geometry) are stored in an rtreego.Rtree
+- a lat/lon query does a bounding box search on the Rtree and then an exact containment test
+  against the simple geometry at each candidate's Offset (using a PreparedFeature)
+
+It is safe to query an index from multiple goroutines while features are being added to it.
+
+*/
+
+// how big a box to draw around a point when searching the Rtree; rtreego doesn't consider
+// rectangles that only touch to intersect so we need something with a little area
+
+const searchTolerance = 0.00001
+
+type Index struct {
+	rtree    *rtreego.Rtree
+	mu       *sync.RWMutex
+	features map[int]*geojson.PreparedFeature
+	spatials map[int][]*geojson.WOFSpatial
+}
+
+func NewIndex() *Index {
+
+	idx := Index{
+		rtree:    rtreego.NewTree(2, 25, 50),
+		mu:       new(sync.RWMutex),
+		features: make(map[int]*geojson.PreparedFeature),
+		spatials: make(map[int][]*geojson.WOFSpatial),
+	}
+
+	return &idx
+}
+
+// IndexFeature adds a feature to the index. If a feature with the same ID has already been
+// indexed it is replaced. Features without an ID are rejected (with a geojson.DecodeError)
+// since they would all replace one another.
+
+func (idx *Index) IndexFeature(f *geojson.WOFFeature) error {
+
+	id := f.Id()
+
+	if id < 0 {
+		return &geojson.DecodeError{Path: "properties.wof:id", Reason: "missing WOF ID"}
+	}
+
+	spatials, err := f.EnSpatializeGeom()
+
+	if err != nil {
+		return err
+	}
+
+	prepared, err := geojson.NewPreparedFeature(f)
+
+	if err != nil {
+		return err
+	}
+
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	for _, sp := range idx.spatials[id] {
+		idx.rtree.Delete(sp)
+	}
+
+	for _, sp := range spatials {
+		idx.rtree.Insert(sp)
+	}
+
+	idx.features[id] = prepared
+	idx.spatials[id] = spatials
+
+	return nil
+}
+
+func (idx *Index) IndexFile(path string) error {
+
+	f, err := geojson.UnmarshalFile(path)
+
+	if err != nil {
+		return err
+	}
+
+	return idx.IndexFeature(f)
+}
+
+// IndexDirectory indexes every .geojson file below root, using procs goroutines. A file that
+// can't be indexed, or a directory that can't be read, doesn't stop everything else from being
+// indexed but they are all reported at the end (see geojson.WalkFeatures).
+
+func (idx *Index) IndexDirectory(root string, procs int) error {
+	return geojson.WalkFeatures(root, procs, idx.IndexFile)
+}
+
+// Feature returns the (original) feature for a given ID
+
+func (idx *Index) Feature(id int) (*geojson.WOFFeature, bool) {
+
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+
+	prepared, ok := idx.features[id]
+
+	if !ok {
+		return nil, false
+	}
+
+	return prepared.Feature, true
+}
+
+// Size returns the number of features (not WOFSpatial thing-ies) in the index
+
+func (idx *Index) Size() int {
+
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+
+	return len(idx.features)
+}
+
+// GetByLatLon returns the WOFSpatial thing-ies whose geometry contains the point and which
+// get past all of the (optional) filters. A feature with more than one simple geometry (a
+// MultiPolygon, say) may appear more than once if its geometries overlap; the Offset property
+// tells you which one matched. See filter.go for details about filters.
+
+func (idx *Index) GetByLatLon(latitude float64, longitude float64, filters ...rtreego.Filter) []*geojson.WOFSpatial {
+
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+
+	pt := rtreego.Point{longitude, latitude}
+	candidates := idx.rtree.SearchIntersect(pt.ToRect(searchTolerance))
+
+	matches := make([]rtreego.Spatial, 0)
+	results := make([]*geojson.WOFSpatial, 0)
+
+	// geometries that cross the antimeridian are indexed twice (once on either side of
+	// it) so make sure they only get counted once
+
+	seen := make(map[[2]int]bool)
+
+	for _, c := range candidates {
+
+		sp := c.(*geojson.WOFSpatial)
+		key := [2]int{sp.Id, sp.Offset}
+
+		if seen[key] {
+			continue
+		}
+
+		refuse, abort := applyFilters(matches, c, filters)
+
+		if !refuse {
+
+			prepared, ok := idx.features[sp.Id]
+
+			if ok && prepared.ContainsAtOffset(sp.Offset, latitude, longitude) {
+				seen[key] = true
+				matches = append(matches, sp)
+				results = append(results, sp)
+			}
+		}
+
+		if abort {
+			break
+		}
+	}
+
+	return results
+}
@@ -0,0 +1,368 @@
+package index
+
+import (
+	"fmt"
+	geojson "github.com/whosonfirst/go-whosonfirst-geojson"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"testing"
+)
+
+// helpers shared by the tests in this package
+
+func square(minx float64, miny float64, maxx float64, maxy float64) string {
+	return fmt.Sprintf(`{"type":"Polygon","coordinates":[[[%v,%v],[%v,%v],[%v,%v],[%v,%v],[%v,%v]]]}`, minx, miny, maxx, miny, maxx, maxy, minx, maxy, minx, miny)
+}
+
+// testFeature returns a feature with the given ID, placetype and geometry; properties is
+// anything else to add to the properties dictionary, starting with a comma
+
+func testFeature(t testing.TB, id int, placetype string, properties string, geometry string) *geojson.WOFFeature {
+
+	body := fmt.Sprintf(`{"type":"Feature","id":%d,"properties":{"wof:id":%d,"wof:name":"%d","wof:placetype":"%s"%s},"geometry":%s}`, id, id, id, placetype, properties, geometry)
+
+	f, err := geojson.UnmarshalFeature([]byte(body))
+
+	if err != nil {
+		t.Fatalf("failed to parse test feature %d, because %s", id, err)
+	}
+
+	return f
+}
+
+func testIndex(t testing.TB, features ...*geojson.WOFFeature) *Index {
+
+	idx := NewIndex()
+
+	for _, f := range features {
+
+		err := idx.IndexFeature(f)
+
+		if err != nil {
+			t.Fatalf("failed to index %d, because %s", f.Id(), err)
+		}
+	}
+
+	return idx
+}
+
+// the IDs of results, sorted and with duplicates left in
+
+func spatialIds(results []*geojson.WOFSpatial) []int {
+
+	ids := make([]int, 0)
+
+	for _, sp := range results {
+		ids = append(ids, sp.Id)
+	}
+
+	sort.Ints(ids)
+	return ids
+}
+
+func sameIds(a []int, b []int) bool {
+
+	if len(a) != len(b) {
+		return false
+	}
+
+	for i := range a {
+
+		if a[i] != b[i] {
+			return false
+		}
+	}
+
+	return true
+}
+
+// a country, two regions, a locality with a hole in it, a MultiPolygon and something that
+// crosses the antimeridian
+
+func worldIndex(t testing.TB) *Index {
+
+	return testIndex(t,
+		testFeature(t, 1, "country", "", square(0, 0, 10, 10)),
+		testFeature(t, 2, "region", "", square(0, 0, 5, 10)),
+		testFeature(t, 3, "region", "", square(5, 0, 10, 10)),
+		testFeature(t, 4, "locality", "", `{"type":"Polygon","coordinates":[[[1,1],[4,1],[4,4],[1,4],[1,1]],[[2,2],[2,3],[3,3],[3,2],[2,2]]]}`),
+		testFeature(t, 5, "locality", "", `{"type":"MultiPolygon","coordinates":[[[[6,6],[7,6],[7,7],[6,7],[6,6]]],[[[8,8],[9,8],[9,9],[8,9],[8,8]]]]}`),
+		testFeature(t, 6, "country", "", `{"type":"Polygon","coordinates":[[[177,-20],[-178,-20],[-178,-15],[177,-15],[177,-20]]]}`),
+	)
+}
+
+func TestGetByLatLon(t *testing.T) {
+
+	idx := worldIndex(t)
+
+	if idx.Size() != 6 {
+		t.Errorf("expected 6 features, got %d", idx.Size())
+	}
+
+	tests := []struct {
+		lat      float64
+		lon      float64
+		expected []int
+	}{
+		{1.5, 1.5, []int{1, 2, 4}},
+		{2.5, 2.5, []int{1, 2}}, // in the hole
+		{6.5, 6.5, []int{1, 3, 5}},
+		{8.5, 8.5, []int{1, 3, 5}},
+		{7.5, 7.5, []int{1, 3}},
+		{20, 20, []int{}},
+		{-17, 179, []int{6}},
+		{-17, -179, []int{6}},
+		{-17, 176, []int{}},
+	}
+
+	for _, test := range tests {
+
+		ids := spatialIds(idx.GetByLatLon(test.lat, test.lon))
+
+		if !sameIds(ids, test.expected) {
+			t.Errorf("expected GetByLatLon(%v, %v) to be %v, got %v", test.lat, test.lon, test.expected, ids)
+		}
+	}
+
+	// the offset says which of the MultiPolygon's polygons matched
+
+	for _, sp := range idx.GetByLatLon(8.5, 8.5) {
+
+		if sp.Id == 5 && sp.Offset != 1 {
+			t.Errorf("expected the second polygon to match, got offset %d", sp.Offset)
+		}
+	}
+}
+
+func TestIndexFeatureReplaces(t *testing.T) {
+
+	idx := worldIndex(t)
+
+	err := idx.IndexFeature(testFeature(t, 4, "locality", "", square(20, 20, 21, 21)))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if idx.Size() != 6 {
+		t.Errorf("expected replacing a feature not to change the size of the index, got %d", idx.Size())
+	}
+
+	if ids := spatialIds(idx.GetByLatLon(1.5, 1.5)); !sameIds(ids, []int{1, 2}) {
+		t.Errorf("expected the old geometry to be gone, got %v", ids)
+	}
+
+	if ids := spatialIds(idx.GetByLatLon(20.5, 20.5)); !sameIds(ids, []int{4}) {
+		t.Errorf("expected the new geometry to be indexed, got %v", ids)
+	}
+
+	f, ok := idx.Feature(4)
+
+	if !ok || f.Placetype() != "locality" {
+		t.Error("expected Feature to return the new feature")
+	}
+
+	_, ok = idx.Feature(99)
+
+	if ok {
+		t.Error("expected Feature to return false for something that isn't there")
+	}
+}
+
+func TestIndexFile(t *testing.T) {
+
+	dir, err := ioutil.TempDir("", "index")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "1.geojson")
+
+	f := testFeature(t, 1, "country", "", square(0, 0, 1, 1))
+
+	err = ioutil.WriteFile(path, f.Body().Bytes(), 0644)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	idx := NewIndex()
+
+	err = idx.IndexFile(path)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if ids := spatialIds(idx.GetByLatLon(0.5, 0.5)); !sameIds(ids, []int{1}) {
+		t.Errorf("expected the file to be indexed, got %v", ids)
+	}
+
+	if idx.IndexFile(filepath.Join(dir, "nope.geojson")) == nil {
+		t.Error("expected indexing a missing file to fail")
+	}
+}
+
+// features without an ID (say, files given to wof-geojson-contains in batch mode that aren't
+// WOF records) used to all be stored under -1, each one replacing the last
+
+func TestIndexFileWithoutId(t *testing.T) {
+
+	dir, err := ioutil.TempDir("", "index")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer os.RemoveAll(dir)
+
+	idx := NewIndex()
+
+	for i, geom := range []string{square(0, 0, 1, 1), square(5, 5, 6, 6)} {
+
+		path := filepath.Join(dir, fmt.Sprintf("%d.geojson", i))
+		body := `{"type":"Feature","properties":{"name":"no id"},"geometry":` + geom + `}`
+
+		err := ioutil.WriteFile(path, []byte(body), 0644)
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		err = idx.IndexFile(path)
+
+		if _, ok := err.(*geojson.DecodeError); !ok {
+			t.Errorf("expected a feature without an ID to be rejected, got %v", err)
+		}
+	}
+
+	if idx.Size() != 0 || len(idx.GetByLatLon(0.5, 0.5)) != 0 {
+		t.Errorf("expected nothing to be indexed, got %d features", idx.Size())
+	}
+}
+
+func TestIndexDirectory(t *testing.T) {
+
+	dir, err := ioutil.TempDir("", "index")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer os.RemoveAll(dir)
+
+	files := map[string]string{
+		"1.geojson":        testFeature(t, 1, "country", "", square(0, 0, 1, 1)).Body().String(),
+		"a/2.geojson":      testFeature(t, 2, "region", "", square(0, 0, 1, 1)).Body().String(),
+		"b/3.geojson":      testFeature(t, 3, "region", "", square(0, 0, 1, 1)).Body().String(),
+		"broken.geojson":   "{",
+		"not-geojson.json": "{",
+	}
+
+	for name, body := range files {
+
+		path := filepath.Join(dir, name)
+
+		err := os.MkdirAll(filepath.Dir(path), 0755)
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		err = ioutil.WriteFile(path, []byte(body), 0644)
+
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// a directory that can't be read (which is something root can always do)
+
+	expected_ids := []int{1, 2, 3}
+	expected_errors := 1
+
+	if os.Geteuid() != 0 {
+
+		unreadable := filepath.Join(dir, "b")
+
+		err := os.Chmod(unreadable, 0)
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		defer os.Chmod(unreadable, 0755)
+
+		expected_ids = []int{1, 2}
+		expected_errors = 2
+	}
+
+	idx := NewIndex()
+	err = idx.IndexDirectory(dir, 4)
+
+	if ids := spatialIds(idx.GetByLatLon(0.5, 0.5)); !sameIds(ids, expected_ids) {
+		t.Errorf("expected %v to be indexed, got %v", expected_ids, ids)
+	}
+
+	walk_err, ok := err.(*geojson.WalkError)
+
+	if !ok || len(walk_err.Errors) != expected_errors {
+		t.Errorf("expected %d errors, got %v", expected_errors, err)
+	}
+}
+
+func TestIndexConcurrently(t *testing.T) {
+
+	idx := worldIndex(t)
+	wg := new(sync.WaitGroup)
+
+	venues := make([]*geojson.WOFFeature, 0)
+
+	for id := 100; id < 300; id++ {
+		venues = append(venues, testFeature(t, id, "venue", "", square(1.1, 1.1, 1.2, 1.2)))
+	}
+
+	for i := 0; i < 4; i++ {
+
+		wg.Add(2)
+
+		go func(features []*geojson.WOFFeature) {
+
+			defer wg.Done()
+
+			for _, f := range features {
+
+				err := idx.IndexFeature(f)
+
+				if err != nil {
+					t.Error(err)
+				}
+			}
+		}(venues[i*50 : (i+1)*50])
+
+		go func() {
+
+			defer wg.Done()
+
+			for j := 0; j < 200; j++ {
+				idx.GetByLatLon(1.15, 1.15)
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	if idx.Size() != 206 {
+		t.Errorf("expected 206 features, got %d", idx.Size())
+	}
+
+	if len(idx.GetByLatLon(1.15, 1.15)) != 203 {
+		t.Errorf("expected 203 features to contain the point, got %d", len(idx.GetByLatLon(1.15, 1.15)))
+	}
+}
@@ -0,0 +1,196 @@
+package index
+
+import (
+	rtreego "github.com/dhconnelly/rtreego"
+	geojson "github.com/whosonfirst/go-whosonfirst-geojson"
+	"math"
+	"sort"
+)
+
+/*
+
+Nearest finds the k features closest to a point. The Rtree only knows about bounding boxes (in
+degrees) so it is used to pick candidates which are then ranked by their true (great circle)
+distance to the geometry itself - see geojson.DistanceToGeometry. A point inside a feature is 0
+meters away from it.
+
+A box that is close in degrees isn't necessarily close in meters (degrees of longitude shrink
+towards the poles) so if the furthest candidate's box might still be nearer than the k-th best
+result we go back to the Rtree and ask for twice as many candidates, until the answer can't
+change or we've looked at everything.
+
+*/
+
+// the fewest candidates to ask the Rtree for
+
+const minNearestCandidates = 16
+
+type Neighbour struct {
+	Spatial  *geojson.WOFSpatial
+	Distance float64 // meters
+}
+
+// Nearest returns (up to) k features ordered by their distance from the point, nearest first.
+// Each feature appears once, with the WOFSpatial thing-y for whichever of its simple geometries
+// is closest. Filters are applied to candidates before they are ranked; see filter.go.
+
+func (idx *Index) Nearest(latitude float64, longitude float64, k int, filters ...rtreego.Filter) []*Neighbour {
+
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+
+	if k <= 0 {
+		return make([]*Neighbour, 0)
+	}
+
+	// the Rtree doesn't know that the world is round so we also ask it about the same point
+	// on the other side of the antimeridian
+
+	wrapped := longitude - 360.0
+
+	if longitude < 0.0 {
+		wrapped = longitude + 360.0
+	}
+
+	queries := []rtreego.Point{
+		rtreego.Point{longitude, latitude},
+		rtreego.Point{wrapped, latitude},
+	}
+
+	size := idx.rtree.Size()
+	count := k * 4
+
+	if count < minNearestCandidates {
+		count = minNearestCandidates
+	}
+
+	for {
+
+		neighbours, furthest := idx.nearestCandidates(queries, latitude, longitude, count, filters)
+
+		if count >= size {
+
+			if len(neighbours) > k {
+				neighbours = neighbours[0:k]
+			}
+
+			return neighbours
+		}
+
+		// anything the Rtree didn't give us is at least furthest degrees away, which is
+		// fewer meters than you'd think the further you get from the equator
+
+		lat := math.Min(math.Abs(latitude)+furthest, 90.0)
+		bound := furthest * geojson.MetersPerDegree * math.Cos(lat*math.Pi/180.0)
+
+		if len(neighbours) >= k && neighbours[k-1].Distance <= bound {
+			return neighbours[0:k]
+		}
+
+		count = count * 2
+	}
+}
+
+// nearestCandidates returns the ranked (and filtered) features for the count nearest Rtree
+// records and how far (in degrees) the furthest of those records is from the query
+
+func (idx *Index) nearestCandidates(queries []rtreego.Point, latitude float64, longitude float64, count int, filters []rtreego.Filter) ([]*Neighbour, float64) {
+
+	candidates := make([]*geojson.WOFSpatial, 0)
+	seen := make(map[*geojson.WOFSpatial]bool)
+
+	furthest := math.Inf(1)
+
+	for _, q := range queries {
+
+		query_furthest := 0.0
+
+		for _, c := range idx.rtree.NearestNeighbors(count, q) {
+
+			// rtreego pads the results with nils if the tree is smaller than count
+
+			if c == nil {
+				continue
+			}
+
+			sp := c.(*geojson.WOFSpatial)
+			query_furthest = math.Max(query_furthest, rectDistance(q, sp.Bounds()))
+
+			if seen[sp] {
+				continue
+			}
+
+			seen[sp] = true
+			candidates = append(candidates, sp)
+		}
+
+		furthest = math.Min(furthest, query_furthest)
+	}
+
+	matches := make([]rtreego.Spatial, 0)
+	nearest := make(map[int]*Neighbour)
+
+	for _, sp := range candidates {
+
+		refuse, abort := applyFilters(matches, sp, filters)
+
+		if !refuse {
+
+			prepared, ok := idx.features[sp.Id]
+
+			if ok {
+
+				distance := prepared.DistanceAtOffset(sp.Offset, latitude, longitude)
+				n, ok := nearest[sp.Id]
+
+				if distance >= 0.0 && (!ok || distance < n.Distance) {
+					nearest[sp.Id] = &Neighbour{Spatial: sp, Distance: distance}
+				}
+
+				matches = append(matches, sp)
+			}
+		}
+
+		if abort {
+			break
+		}
+	}
+
+	neighbours := make([]*Neighbour, 0)
+
+	for _, n := range nearest {
+		neighbours = append(neighbours, n)
+	}
+
+	sort.Slice(neighbours, func(i, j int) bool {
+
+		if neighbours[i].Distance != neighbours[j].Distance {
+			return neighbours[i].Distance < neighbours[j].Distance
+		}
+
+		return neighbours[i].Spatial.Id < neighbours[j].Spatial.Id
+	})
+
+	return neighbours, furthest
+}
+
+// the distance (in degrees) between a point and the nearest edge of a rectangle
+
+func rectDistance(pt rtreego.Point, r *rtreego.Rect) float64 {
+
+	sum := 0.0
+
+	for i, coord := range pt {
+
+		min := r.PointCoord(i)
+		max := min + r.LengthsCoord(i)
+
+		if coord < min {
+			sum += (min - coord) * (min - coord)
+		} else if coord > max {
+			sum += (coord - max) * (coord - max)
+		}
+	}
+
+	return math.Sqrt(sum)
+}
@@ -0,0 +1,107 @@
+package index
+
+import (
+	rtreego "github.com/dhconnelly/rtreego"
+	geojson "github.com/whosonfirst/go-whosonfirst-geojson"
+	"testing"
+)
+
+func neighbourIds(neighbours []*Neighbour) []int {
+
+	ids := make([]int, 0)
+
+	for _, n := range neighbours {
+		ids = append(ids, n.Spatial.Id)
+	}
+
+	return ids
+}
+
+func TestNearest(t *testing.T) {
+
+	idx := testIndex(t,
+		testFeature(t, 1, "locality", "", square(0, 0, 1, 1)),
+		testFeature(t, 2, "locality", "", square(2, 0, 3, 1)),
+		testFeature(t, 3, "locality", "", square(5, 0, 6, 1)),
+		testFeature(t, 4, "region", "", square(-10, -10, 10, 10)),
+		testFeature(t, 5, "locality", "", `{"type":"MultiPolygon","coordinates":[[[[20,0],[21,0],[21,1],[20,1],[20,0]]],[[[3.5,0],[4,0],[4,1],[3.5,1],[3.5,0]]]]}`),
+		testFeature(t, 6, "locality", "", square(179, 0, 180, 1)),
+		testFeature(t, 7, "locality", "", square(-178, 0, -177, 1)),
+	)
+
+	tests := []struct {
+		name     string
+		lat      float64
+		lon      float64
+		k        int
+		filters  []rtreego.Filter
+		expected []int
+	}{
+		{"inside", 0.5, 0.5, 3, nil, []int{1, 4, 2}},
+		{"between", 0.5, 1.6, 3, nil, []int{4, 2, 1}},
+		{"multipolygon", 0.5, 4.4, 3, []rtreego.Filter{PlacetypeFilter("locality")}, []int{5, 3, 2}},
+		{"everything", 0.5, 0.5, 100, []rtreego.Filter{PlacetypeFilter("locality")}, []int{1, 2, 5, 3, 7, 6}},
+		{"nothing", 0.5, 0.5, 0, nil, []int{}},
+		{"antimeridian", 0.5, -179.5, 2, nil, []int{6, 7}},
+		{"other side of the antimeridian", 0.5, 179.5, 2, nil, []int{6, 7}},
+	}
+
+	for _, test := range tests {
+
+		neighbours := idx.Nearest(test.lat, test.lon, test.k, test.filters...)
+		ids := neighbourIds(neighbours)
+
+		if !sameIds(ids, test.expected) {
+			t.Errorf("%s: expected %v, got %v", test.name, test.expected, ids)
+		}
+
+		for i := 1; i < len(neighbours); i++ {
+
+			if neighbours[i].Distance < neighbours[i-1].Distance {
+				t.Errorf("%s: expected the neighbours to be ordered by distance, got %v", test.name, ids)
+			}
+		}
+	}
+
+	// each feature appears once, with its nearest part and the distance to it
+
+	neighbours := idx.Nearest(0.5, 4.4, 1, PlacetypeFilter("locality"))
+
+	if len(neighbours) != 1 || neighbours[0].Spatial.Offset != 1 || !(neighbours[0].Distance > 0.0) {
+		t.Errorf("expected the second polygon to be nearest, got %v", neighbours[0])
+	}
+
+	neighbours = idx.Nearest(0.5, 0.5, 1)
+
+	if neighbours[0].Distance != 0.0 {
+		t.Errorf("expected a feature containing the point to be 0 meters away, got %v", neighbours[0].Distance)
+	}
+}
+
+func TestNearestHighLatitude(t *testing.T) {
+
+	// near the pole a degree of longitude is a lot shorter than a degree of latitude so the
+	// boxes nearest to the point (in degrees) aren't the nearest features (in meters)
+
+	features := []*geojson.WOFFeature{
+		testFeature(t, 1, "venue", "", square(5, 80, 6, 80.1)),
+	}
+
+	for i := 0; i < 30; i++ {
+		lon := -1.0 + float64(i)*0.05
+		features = append(features, testFeature(t, 100+i, "venue", "", square(lon, 83, lon+0.01, 83.01)))
+	}
+
+	idx := testIndex(t, features...)
+	neighbours := idx.Nearest(80, 0, 1)
+
+	if len(neighbours) != 1 || neighbours[0].Spatial.Id != 1 {
+		t.Errorf("expected 1 to be the nearest feature, got %v", neighbourIds(neighbours))
+	}
+
+	neighbours = idx.Nearest(80, 0, 1, PlacetypeFilter("locality"))
+
+	if len(neighbours) != 0 {
+		t.Errorf("expected nothing to get past the filter, got %v", neighbourIds(neighbours))
+	}
+}
@@ -0,0 +1,277 @@
+package geojson
+
+import (
+	"container/heap"
+	"fmt"
+	geo "github.com/kellydunn/golang-geo"
+	"math"
+)
+
+/*
+
+LabelPoint finds the "visual center" of a polygon - the point inside it that is furthest from
+any of its edges (the pole of inaccessibility) - which is where you want to put a label. Unlike
+a centroid it is always inside the polygon, even for things shaped like a crescent or with a
+big lake in the middle.
+
+This is the polylabel algorithm from Mapbox (https://github.com/mapbox/polylabel): the bounding
+box is covered in square cells which are then subdivided, most promising first, until no cell
+can possibly hold a point that is more than precision (degrees) better than the best one found
+so far. Distances are planar, in degrees, which is what polylabel does too.
+
+*/
+
+// a safety valve for degenerate polygons; real polygons need nothing like this many cells
+
+const maxLabelCells = 1000000
+
+// the smallest precision WOFPolygon.LabelPoint will try for (about a millimeter); anything
+// smaller, including zero or a negative number, would never stop subdividing
+
+const MinLabelPrecision = 1e-8
+
+type labelCell struct {
+	x   float64 // cell center (longitude)
+	y   float64 // cell center (latitude)
+	h   float64 // half the cell size
+	d   float64 // distance from the cell center to the polygon (negative if outside)
+	max float64 // the furthest any point in the cell could be from the polygon
+}
+
+func newLabelCell(x float64, y float64, h float64, rings [][]*geo.Point) *labelCell {
+
+	d := pointToPolygonDistance(x, y, rings)
+
+	c := labelCell{
+		x:   x,
+		y:   y,
+		h:   h,
+		d:   d,
+		max: d + h*math.Sqrt2,
+	}
+
+	return &c
+}
+
+// a max-heap of cells, ordered by max
+
+type labelQueue []*labelCell
+
+func (q labelQueue) Len() int {
+	return len(q)
+}
+
+func (q labelQueue) Less(i, j int) bool {
+	return q[i].max > q[j].max
+}
+
+func (q labelQueue) Swap(i, j int) {
+	q[i], q[j] = q[j], q[i]
+}
+
+func (q *labelQueue) Push(x interface{}) {
+	*q = append(*q, x.(*labelCell))
+}
+
+func (q *labelQueue) Pop() interface{} {
+
+	old := *q
+	count := len(old)
+
+	c := old[count-1]
+	*q = old[0 : count-1]
+
+	return c
+}
+
+// LabelPoint returns the polygon's pole of inaccessibility, to within precision degrees (or
+// MinLabelPrecision if precision is smaller than that). If the polygon has no area the answer
+// is the average of its outer ring's positions.
+
+func (p *WOFPolygon) LabelPoint(precision float64) *geo.Point {
+
+	// written this way round so that NaN gets clamped too
+
+	if !(precision >= MinLabelPrecision) {
+		precision = MinLabelPrecision
+	}
+
+	rings, _ := shiftedPolygonRings(p)
+	outer := rings[0]
+
+	if len(outer) < 3 || p.Area() == 0.0 {
+		return averagePoint(p.OuterRing.Points())
+	}
+
+	minx := math.Inf(1)
+	miny := math.Inf(1)
+	maxx := math.Inf(-1)
+	maxy := math.Inf(-1)
+
+	for _, pt := range outer {
+		minx = math.Min(minx, pt.Lng())
+		miny = math.Min(miny, pt.Lat())
+		maxx = math.Max(maxx, pt.Lng())
+		maxy = math.Max(maxy, pt.Lat())
+	}
+
+	width := maxx - minx
+	height := maxy - miny
+
+	size := math.Min(width, height)
+
+	if size == 0.0 {
+		return averagePoint(p.OuterRing.Points())
+	}
+
+	h := size / 2.0
+
+	queue := make(labelQueue, 0)
+
+	for x := minx; x < maxx; x += size {
+
+		for y := miny; y < maxy; y += size {
+			heap.Push(&queue, newLabelCell(x+h, y+h, h, rings))
+		}
+	}
+
+	// start with the centroid and the middle of the bounding box, whichever is better
+
+	lat, lon, _ := p.planarCentroid()
+	best := newLabelCell(lon, lat, 0.0, rings)
+
+	middle := newLabelCell(minx+width/2.0, miny+height/2.0, 0.0, rings)
+
+	if middle.d > best.d {
+		best = middle
+	}
+
+	cells := 0
+
+	for queue.Len() > 0 && cells < maxLabelCells {
+
+		c := heap.Pop(&queue).(*labelCell)
+		cells += 1
+
+		if c.d > best.d {
+			best = c
+		}
+
+		// stop subdividing once this cell can't do any better than what we've already
+		// got but only once we've actually found somewhere inside the polygon
+
+		if c.max-best.d <= precision && best.d > 0.0 {
+			continue
+		}
+
+		if c.max <= 0.0 {
+			continue
+		}
+
+		h = c.h / 2.0
+
+		heap.Push(&queue, newLabelCell(c.x-h, c.y-h, h, rings))
+		heap.Push(&queue, newLabelCell(c.x+h, c.y-h, h, rings))
+		heap.Push(&queue, newLabelCell(c.x-h, c.y+h, h, rings))
+		heap.Push(&queue, newLabelCell(c.x+h, c.y+h, h, rings))
+	}
+
+	return geo.NewPoint(best.y, unshiftLongitude(best.x))
+}
+
+// LabelPoint returns the label point (see WOFPolygon.LabelPoint) for the feature's largest
+// polygon. Features without any polygons get the same answer as Centroid. It is an error for
+// precision not to be a positive number.
+
+func (wof WOFFeature) LabelPoint(precision float64) (*geo.Point, error) {
+
+	if !(precision > 0.0) || math.IsInf(precision, 0) {
+		return nil, fmt.Errorf("Invalid label precision %v", precision)
+	}
+
+	polygons, err := wof.GeomToPolygonsWithError()
+
+	if err != nil {
+		return nil, err
+	}
+
+	var largest *WOFPolygon
+	largest_area := -1.0
+
+	for _, p := range polygons {
+
+		area := p.Area()
+
+		if area > largest_area {
+			largest = p
+			largest_area = area
+		}
+	}
+
+	if largest == nil {
+		return wof.Centroid()
+	}
+
+	return largest.LabelPoint(precision), nil
+}
+
+// the signed distance from a point to the edges of a polygon: positive if the point is inside
+// the polygon and negative if it isn't
+
+func pointToPolygonDistance(x float64, y float64, rings [][]*geo.Point) float64 {
+
+	inside := false
+	min_dist_sq := math.Inf(1)
+
+	for _, points := range rings {
+
+		count := len(points)
+
+		for i, j := 0, count-1; i < count; j, i = i, i+1 {
+
+			a := points[i]
+			b := points[j]
+
+			if (a.Lat() > y) != (b.Lat() > y) && x < (b.Lng()-a.Lng())*(y-a.Lat())/(b.Lat()-a.Lat())+a.Lng() {
+				inside = !inside
+			}
+
+			min_dist_sq = math.Min(min_dist_sq, segmentDistanceSq(x, y, a, b))
+		}
+	}
+
+	dist := math.Sqrt(min_dist_sq)
+
+	if !inside {
+		return -dist
+	}
+
+	return dist
+}
+
+func segmentDistanceSq(x float64, y float64, a *geo.Point, b *geo.Point) float64 {
+
+	px := a.Lng()
+	py := a.Lat()
+
+	dx := b.Lng() - px
+	dy := b.Lat() - py
+
+	if dx != 0.0 || dy != 0.0 {
+
+		t := ((x-px)*dx + (y-py)*dy) / (dx*dx + dy*dy)
+
+		if t > 1.0 {
+			px = b.Lng()
+			py = b.Lat()
+		} else if t > 0.0 {
+			px += dx * t
+			py += dy * t
+		}
+	}
+
+	dx = x - px
+	dy = y - py
+
+	return dx*dx + dy*dy
+}
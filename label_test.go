@@ -0,0 +1,110 @@
+package geojson
+
+import (
+	"math"
+	"testing"
+)
+
+func TestLabelPoint(t *testing.T) {
+
+	tests := []struct {
+		geometry string
+		distance float64 // how far the label point should be from the nearest edge
+	}{
+		// a square
+		{`{"type":"Polygon","coordinates":[[[0,0],[10,0],[10,10],[0,10],[0,0]]]}`, 5.0},
+		// a square with a square hole in the middle, so the centroid is no good; the best
+		// places are in the corners, where the circle touching both sides and the corner of
+		// the hole has a radius of 4 - 2√2
+		{`{"type":"Polygon","coordinates":[[[0,0],[10,0],[10,10],[0,10],[0,0]],[[2,2],[2,8],[8,8],[8,2],[2,2]]]}`, 4.0 - 2.0*math.Sqrt2},
+		// an L, whose centroid is outside it, which is the same thing as one corner of the above
+		{`{"type":"Polygon","coordinates":[[[0,0],[10,0],[10,2],[2,2],[2,10],[0,10],[0,0]]]}`, 4.0 - 2.0*math.Sqrt2},
+		// the largest of a multipolygon's polygons wins
+		{`{"type":"MultiPolygon","coordinates":[[[[0,0],[1,0],[1,1],[0,1],[0,0]]],[[[20,20],[24,20],[24,24],[20,24],[20,20]]]]}`, 2.0},
+	}
+
+	for _, test := range tests {
+
+		f := geometryFeature(t, test.geometry)
+
+		pt, err := f.LabelPoint(0.0001)
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if !f.Contains(pt.Lat(), pt.Lng()) {
+			t.Errorf("expected the label point for %s to be inside it, got %f,%f", test.geometry, pt.Lat(), pt.Lng())
+			continue
+		}
+
+		distance := math.Inf(1)
+
+		for _, p := range f.GeomToPolygons() {
+
+			rings, _ := shiftedPolygonRings(p)
+			distance = math.Min(distance, math.Abs(pointToPolygonDistance(pt.Lng(), pt.Lat(), rings)))
+		}
+
+		if !almostEqual(distance, test.distance, 0.001) {
+			t.Errorf("expected the label point for %s to be %f from the nearest edge, got %f (%f,%f)", test.geometry, test.distance, distance, pt.Lat(), pt.Lng())
+		}
+	}
+}
+
+func TestLabelPointPrecision(t *testing.T) {
+
+	f := geometryFeature(t, `{"type":"Polygon","coordinates":[[[0,0],[10,0],[10,2],[2,2],[2,10],[0,10],[0,0]]]}`)
+
+	for _, precision := range []float64{0.0, -1.0, math.NaN(), math.Inf(1)} {
+
+		_, err := f.LabelPoint(precision)
+
+		if err == nil {
+			t.Errorf("expected a precision of %v to be an error", precision)
+		}
+	}
+
+	// the polygon method has no way to complain so it clamps the precision instead, rather
+	// than subdividing forever
+
+	p := f.GeomToPolygons()[0]
+
+	for _, precision := range []float64{0.0, -1.0, math.NaN()} {
+
+		pt := p.LabelPoint(precision)
+
+		if !p.Contains(pt.Lat(), pt.Lng()) {
+			t.Errorf("expected the label point with a precision of %v to be inside the polygon, got %f,%f", precision, pt.Lat(), pt.Lng())
+		}
+	}
+}
+
+func TestLabelPointDegenerate(t *testing.T) {
+
+	tests := []struct {
+		geometry string
+		lat      float64
+		lon      float64
+	}{
+		// no area, so the average of the positions
+		{`{"type":"Polygon","coordinates":[[[0,0],[2,2],[4,4],[0,0]]]}`, 1.5, 1.5},
+		// no polygons, so the centroid
+		{`{"type":"Point","coordinates":[3,4]}`, 4.0, 3.0},
+	}
+
+	for _, test := range tests {
+
+		f := geometryFeature(t, test.geometry)
+
+		pt, err := f.LabelPoint(0.0001)
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if !almostEqual(pt.Lat(), test.lat, 1e-9) || !almostEqual(pt.Lng(), test.lon, 1e-9) {
+			t.Errorf("expected the label point for %s to be %f,%f, got %f,%f", test.geometry, test.lat, test.lon, pt.Lat(), pt.Lng())
+		}
+	}
+}
@@ -0,0 +1,697 @@
+package geojson
+
+import (
+	geo "github.com/kellydunn/golang-geo"
+	"math"
+)
+
+/*
+
+Area, perimeter and centroid calculations. Each one comes in two flavours:
+
+- planar, which treats longitude and latitude as x and y and so is measured in degrees (or
+  square degrees). This is what shapely does and so it is what the "geom:area" and
+  "geom:latitude" / "geom:longitude" properties in WOF records are.
+
+- geodesic, which is measured in meters (or square meters) on the WGS84 ellipsoid. Areas are
+  calculated on a sphere with the same surface area as the ellipsoid using authalic latitudes
+  (which makes them equal-area), perimeters use Vincenty's formulae and centroids are the
+  area-weighted average of the (spherical) triangles that make up the polygon.
+
+Holes are subtracted from areas and centroids and counted in perimeters (again, like shapely).
+Polygons that cross the antimeridian are measured using shifted longitudes (see antimeridian.go).
+
+*/
+
+const (
+	wgs84A = 6378137.0
+	wgs84F = 1.0 / 298.257223563
+	wgs84B = wgs84A * (1.0 - wgs84F)
+)
+
+// Area returns the planar area of the polygon, in square degrees
+
+func (p *WOFPolygon) Area() float64 {
+
+	rings, _ := shiftedPolygonRings(p)
+	area := math.Abs(ringArea(rings[0]))
+
+	for _, r := range rings[1:] {
+		area -= math.Abs(ringArea(r))
+	}
+
+	return area
+}
+
+// GeodesicArea returns the area of the polygon on the WGS84 ellipsoid, in square meters
+
+func (p *WOFPolygon) GeodesicArea() float64 {
+
+	rings, _ := shiftedPolygonRings(p)
+	area := math.Abs(geodesicRingArea(rings[0]))
+
+	for _, r := range rings[1:] {
+		area -= math.Abs(geodesicRingArea(r))
+	}
+
+	return area
+}
+
+// Perimeter returns the planar length of all of the polygon's rings, in degrees
+
+func (p *WOFPolygon) Perimeter() float64 {
+
+	rings, _ := shiftedPolygonRings(p)
+	length := 0.0
+
+	for _, r := range rings {
+		length += planarLength(r, true)
+	}
+
+	return length
+}
+
+// GeodesicPerimeter returns the length of all of the polygon's rings on the WGS84 ellipsoid,
+// in meters
+
+func (p *WOFPolygon) GeodesicPerimeter() float64 {
+
+	length := 0.0
+
+	for _, r := range polygonRings(p) {
+		length += geodesicLength(r, true)
+	}
+
+	return length
+}
+
+// Centroid returns the planar centroid of the polygon. If the polygon has no area the centroid
+// is the average of the outer ring's positions.
+
+func (p *WOFPolygon) Centroid() *geo.Point {
+
+	lat, lon, area := p.planarCentroid()
+
+	if area == 0.0 {
+		return averagePoint(p.OuterRing.Points())
+	}
+
+	return geo.NewPoint(lat, unshiftLongitude(lon))
+}
+
+// GeodesicCentroid returns the centroid of the polygon on the sphere. If the polygon has no area
+// the centroid is the same as Centroid.
+
+func (p *WOFPolygon) GeodesicCentroid() *geo.Point {
+
+	x, y, z, area := p.sphericalCentroid()
+
+	if area == 0.0 {
+		return p.Centroid()
+	}
+
+	return vectorToPoint(x, y, z)
+}
+
+// Area returns the planar area of all the feature's polygons, in square degrees (points and
+// lines don't have any area)
+
+func (wof WOFFeature) Area() (float64, error) {
+
+	polygons, err := wof.GeomToPolygonsWithError()
+
+	if err != nil {
+		return 0.0, err
+	}
+
+	area := 0.0
+
+	for _, p := range polygons {
+		area += p.Area()
+	}
+
+	return area, nil
+}
+
+// GeodesicArea returns the area of all the feature's polygons on the WGS84 ellipsoid, in square
+// meters
+
+func (wof WOFFeature) GeodesicArea() (float64, error) {
+
+	polygons, err := wof.GeomToPolygonsWithError()
+
+	if err != nil {
+		return 0.0, err
+	}
+
+	area := 0.0
+
+	for _, p := range polygons {
+		area += p.GeodesicArea()
+	}
+
+	return area, nil
+}
+
+// Perimeter returns the planar length of the feature's polygons or, if it doesn't have any, its
+// lines, in degrees
+
+func (wof WOFFeature) Perimeter() (float64, error) {
+
+	geom, err := wof.Geometry()
+
+	if err != nil {
+		return 0.0, err
+	}
+
+	length := 0.0
+
+	for _, p := range geom.Polygons() {
+		length += p.Perimeter()
+	}
+
+	if len(geom.Polygons()) > 0 {
+		return length, nil
+	}
+
+	for _, l := range featureLines(geom) {
+
+		points := l.Coordinates
+
+		if crossesAntimeridian(points) {
+			points = shiftPoints(points)
+		}
+
+		length += planarLength(points, false)
+	}
+
+	return length, nil
+}
+
+// GeodesicPerimeter returns the length of the feature's polygons or, if it doesn't have any, its
+// lines on the WGS84 ellipsoid, in meters
+
+func (wof WOFFeature) GeodesicPerimeter() (float64, error) {
+
+	geom, err := wof.Geometry()
+
+	if err != nil {
+		return 0.0, err
+	}
+
+	length := 0.0
+
+	for _, p := range geom.Polygons() {
+		length += p.GeodesicPerimeter()
+	}
+
+	if len(geom.Polygons()) > 0 {
+		return length, nil
+	}
+
+	for _, l := range featureLines(geom) {
+		length += geodesicLength(l.Coordinates, false)
+	}
+
+	return length, nil
+}
+
+// Centroid returns the planar centroid of the feature: the area-weighted centroid of its
+// polygons or, if it doesn't have any, the length-weighted centroid of its lines or, failing
+// that, the average of its points.
+
+func (wof WOFFeature) Centroid() (*geo.Point, error) {
+
+	geom, err := wof.Geometry()
+
+	if err != nil {
+		return nil, err
+	}
+
+	bb := ComputeBounds(geom)
+
+	if bb.IsEmpty() {
+		return nil, &DecodeError{Path: "geometry", Reason: "geometry has no coordinates"}
+	}
+
+	// if the geometry as a whole crosses the antimeridian then the polygons on either
+	// side of it need to be averaged using shifted longitudes too
+
+	shift := bb.CrossesAntimeridian()
+
+	sum_lat := 0.0
+	sum_lon := 0.0
+	sum_weight := 0.0
+
+	for _, p := range geom.Polygons() {
+
+		lat, lon, area := p.planarCentroid()
+
+		if area == 0.0 {
+			continue
+		}
+
+		if shift {
+			lon = shiftLongitude(unshiftLongitude(lon))
+		}
+
+		sum_lat += lat * area
+		sum_lon += lon * area
+		sum_weight += area
+	}
+
+	if sum_weight == 0.0 {
+
+		for _, l := range featureLines(geom) {
+
+			points := l.Coordinates
+
+			if shift {
+				points = shiftPoints(points)
+			}
+
+			for i := 1; i < len(points); i++ {
+
+				a := points[i-1]
+				b := points[i]
+
+				length := math.Hypot(b.Lng()-a.Lng(), b.Lat()-a.Lat())
+
+				sum_lat += (a.Lat() + b.Lat()) / 2.0 * length
+				sum_lon += (a.Lng() + b.Lng()) / 2.0 * length
+				sum_weight += length
+			}
+		}
+	}
+
+	if sum_weight == 0.0 {
+
+		points := boundingPoints(geom)
+
+		if shift {
+			points = shiftPoints(points)
+		}
+
+		for _, pt := range points {
+			sum_lat += pt.Lat()
+			sum_lon += pt.Lng()
+			sum_weight += 1.0
+		}
+	}
+
+	return geo.NewPoint(sum_lat/sum_weight, unshiftLongitude(sum_lon/sum_weight)), nil
+}
+
+// GeodesicCentroid returns the area-weighted centroid of the feature's polygons on the sphere.
+// Features without any polygons get the same answer as Centroid.
+
+func (wof WOFFeature) GeodesicCentroid() (*geo.Point, error) {
+
+	polygons, err := wof.GeomToPolygonsWithError()
+
+	if err != nil {
+		return nil, err
+	}
+
+	sum_x := 0.0
+	sum_y := 0.0
+	sum_z := 0.0
+	sum_area := 0.0
+
+	for _, p := range polygons {
+
+		x, y, z, area := p.sphericalCentroid()
+
+		sum_x += x
+		sum_y += y
+		sum_z += z
+		sum_area += area
+	}
+
+	if sum_area == 0.0 {
+		return wof.Centroid()
+	}
+
+	return vectorToPoint(sum_x, sum_y, sum_z), nil
+}
+
+// the area-weighted centroid of the polygon (using shifted longitudes if it crosses the
+// antimeridian) and its area
+
+func (p *WOFPolygon) planarCentroid() (float64, float64, float64) {
+
+	rings, _ := shiftedPolygonRings(p)
+
+	sum_lat := 0.0
+	sum_lon := 0.0
+	sum_area := 0.0
+
+	for i, r := range rings {
+
+		lat, lon, area := ringCentroid(r)
+
+		// make sure the outer ring counts for and the holes count against
+
+		area = math.Abs(area)
+
+		if i > 0 {
+			area = -area
+		}
+
+		sum_lat += lat * area
+		sum_lon += lon * area
+		sum_area += area
+	}
+
+	if sum_area == 0.0 {
+		return 0.0, 0.0, 0.0
+	}
+
+	return sum_lat / sum_area, sum_lon / sum_area, sum_area
+}
+
+func ringCentroid(points []*geo.Point) (float64, float64, float64) {
+
+	count := len(points)
+
+	if count < 3 {
+		return 0.0, 0.0, 0.0
+	}
+
+	// relative to the first point, to keep the numbers small
+
+	x0 := points[0].Lng()
+	y0 := points[0].Lat()
+
+	cx := 0.0
+	cy := 0.0
+	area := 0.0
+
+	for i := 0; i < count; i++ {
+
+		a := points[i]
+		b := points[(i+1)%count]
+
+		ax := a.Lng() - x0
+		ay := a.Lat() - y0
+		bx := b.Lng() - x0
+		by := b.Lat() - y0
+
+		cross := ax*by - bx*ay
+
+		area += cross
+		cx += (ax + bx) * cross
+		cy += (ay + by) * cross
+	}
+
+	area = area / 2.0
+
+	if area == 0.0 {
+		return 0.0, 0.0, 0.0
+	}
+
+	return y0 + cy/(6.0*area), x0 + cx/(6.0*area), area
+}
+
+// the sum of the (unnormalized) centroids of the spherical triangles that make up the polygon,
+// weighted by their area, and the polygon's area on the unit sphere
+
+func (p *WOFPolygon) sphericalCentroid() (float64, float64, float64, float64) {
+
+	sum_x := 0.0
+	sum_y := 0.0
+	sum_z := 0.0
+	sum_area := 0.0
+
+	for i, r := range polygonRings(p) {
+
+		count := len(r)
+
+		if count < 3 {
+			continue
+		}
+
+		ring_x := 0.0
+		ring_y := 0.0
+		ring_z := 0.0
+		ring_area := 0.0
+
+		ax, ay, az := pointToVector(r[0])
+
+		for j := 1; j < count-1; j++ {
+
+			bx, by, bz := pointToVector(r[j])
+			cx, cy, cz := pointToVector(r[j+1])
+
+			area := sphericalTriangleArea(ax, ay, az, bx, by, bz, cx, cy, cz)
+
+			ring_x += (ax + bx + cx) / 3.0 * area
+			ring_y += (ay + by + cy) / 3.0 * area
+			ring_z += (az + bz + cz) / 3.0 * area
+			ring_area += area
+		}
+
+		// triangles are signed so flip the whole ring if it's wound the "wrong" way
+		// and then make the holes count against
+
+		sign := 1.0
+
+		if ring_area < 0.0 {
+			sign = -1.0
+		}
+
+		if i > 0 {
+			sign = -sign
+		}
+
+		sum_x += ring_x * sign
+		sum_y += ring_y * sign
+		sum_z += ring_z * sign
+		sum_area += ring_area * sign
+	}
+
+	return sum_x, sum_y, sum_z, sum_area
+}
+
+// the signed area of a spherical triangle on the unit sphere (Van Oosterom and Strackee)
+
+func sphericalTriangleArea(ax float64, ay float64, az float64, bx float64, by float64, bz float64, cx float64, cy float64, cz float64) float64 {
+
+	triple := ax*(by*cz-bz*cy) - ay*(bx*cz-bz*cx) + az*(bx*cy-by*cx)
+	denom := 1.0 + (ax*bx + ay*by + az*bz) + (bx*cx + by*cy + bz*cz) + (cx*ax + cy*ay + cz*az)
+
+	return 2.0 * math.Atan2(triple, denom)
+}
+
+func pointToVector(pt *geo.Point) (float64, float64, float64) {
+
+	lat := pt.Lat() * math.Pi / 180.0
+	lon := pt.Lng() * math.Pi / 180.0
+
+	return math.Cos(lat) * math.Cos(lon), math.Cos(lat) * math.Sin(lon), math.Sin(lat)
+}
+
+func vectorToPoint(x float64, y float64, z float64) *geo.Point {
+
+	lat := math.Atan2(z, math.Hypot(x, y)) * 180.0 / math.Pi
+	lon := math.Atan2(y, x) * 180.0 / math.Pi
+
+	return geo.NewPoint(lat, lon)
+}
+
+func averagePoint(points []*geo.Point) *geo.Point {
+
+	if len(points) == 0 {
+		return geo.NewPoint(0.0, 0.0)
+	}
+
+	if crossesAntimeridian(points) {
+		points = shiftPoints(points)
+	}
+
+	lat := 0.0
+	lon := 0.0
+
+	for _, pt := range points {
+		lat += pt.Lat()
+		lon += pt.Lng()
+	}
+
+	count := float64(len(points))
+	return geo.NewPoint(lat/count, unshiftLongitude(lon/count))
+}
+
+func featureLines(g Geometry) []*LineString {
+
+	lines := make([]*LineString, 0)
+
+	for _, part := range Flatten(g) {
+
+		l, ok := part.(*LineString)
+
+		if ok {
+			lines = append(lines, l)
+		}
+	}
+
+	return lines
+}
+
+func planarLength(points []*geo.Point, closed bool) float64 {
+
+	count := len(points)
+	length := 0.0
+
+	for i := 1; i < count; i++ {
+		length += math.Hypot(points[i].Lng()-points[i-1].Lng(), points[i].Lat()-points[i-1].Lat())
+	}
+
+	if closed && count > 1 && !samePoint(points[0], points[count-1]) {
+		length += math.Hypot(points[0].Lng()-points[count-1].Lng(), points[0].Lat()-points[count-1].Lat())
+	}
+
+	return length
+}
+
+func geodesicLength(points []*geo.Point, closed bool) float64 {
+
+	count := len(points)
+	length := 0.0
+
+	for i := 1; i < count; i++ {
+		length += vincentyDistance(points[i-1], points[i])
+	}
+
+	if closed && count > 1 && !samePoint(points[0], points[count-1]) {
+		length += vincentyDistance(points[count-1], points[0])
+	}
+
+	return length
+}
+
+// the signed area of a ring on the WGS84 ellipsoid, in square meters. Latitudes are converted
+// to authalic latitudes and the area is then calculated on a sphere with the same surface area
+// as the ellipsoid, one edge at a time.
+
+func geodesicRingArea(points []*geo.Point) float64 {
+
+	count := len(points)
+
+	if count < 3 {
+		return 0.0
+	}
+
+	e := math.Sqrt(wgs84F * (2.0 - wgs84F))
+	qp := authalicQ(1.0, e)
+	radius := wgs84A * math.Sqrt(qp/2.0)
+
+	beta := func(pt *geo.Point) float64 {
+
+		q := authalicQ(math.Sin(pt.Lat()*math.Pi/180.0), e)
+		return math.Asin(math.Max(-1.0, math.Min(1.0, q/qp)))
+	}
+
+	total := 0.0
+
+	for i := 0; i < count; i++ {
+
+		a := points[i]
+		b := points[(i+1)%count]
+
+		lambda := (b.Lng() - a.Lng()) * math.Pi / 180.0
+
+		t1 := math.Tan(beta(a) / 2.0)
+		t2 := math.Tan(beta(b) / 2.0)
+
+		total += 2.0 * math.Atan2(math.Tan(lambda/2.0)*(t1+t2), 1.0+t1*t2)
+	}
+
+	return total * radius * radius
+}
+
+func authalicQ(sin_lat float64, e float64) float64 {
+
+	e_sin := e * sin_lat
+	return (1.0 - e*e) * (sin_lat/(1.0-e_sin*e_sin) - (1.0/(2.0*e))*math.Log((1.0-e_sin)/(1.0+e_sin)))
+}
+
+// vincentyDistance returns the distance between two points on the WGS84 ellipsoid, in meters.
+// In the (very) rare case that the formulae don't converge (nearly antipodal points) it falls
+// back to the great circle distance.
+
+func vincentyDistance(a *geo.Point, b *geo.Point) float64 {
+
+	if samePoint(a, b) {
+		return 0.0
+	}
+
+	L := (b.Lng() - a.Lng()) * math.Pi / 180.0
+
+	if L > math.Pi {
+		L -= 2.0 * math.Pi
+	}
+
+	if L < -math.Pi {
+		L += 2.0 * math.Pi
+	}
+
+	U1 := math.Atan((1.0 - wgs84F) * math.Tan(a.Lat()*math.Pi/180.0))
+	U2 := math.Atan((1.0 - wgs84F) * math.Tan(b.Lat()*math.Pi/180.0))
+
+	sin_u1, cos_u1 := math.Sincos(U1)
+	sin_u2, cos_u2 := math.Sincos(U2)
+
+	lambda := L
+
+	var sin_sigma, cos_sigma, sigma, cos_sq_alpha, cos_2sigma_m float64
+	converged := false
+
+	for i := 0; i < 200; i++ {
+
+		sin_lambda, cos_lambda := math.Sincos(lambda)
+
+		sin_sigma = math.Hypot(cos_u2*sin_lambda, cos_u1*sin_u2-sin_u1*cos_u2*cos_lambda)
+
+		if sin_sigma == 0.0 {
+			return 0.0
+		}
+
+		cos_sigma = sin_u1*sin_u2 + cos_u1*cos_u2*cos_lambda
+		sigma = math.Atan2(sin_sigma, cos_sigma)
+
+		sin_alpha := cos_u1 * cos_u2 * sin_lambda / sin_sigma
+		cos_sq_alpha = 1.0 - sin_alpha*sin_alpha
+
+		cos_2sigma_m = 0.0
+
+		if cos_sq_alpha != 0.0 {
+			cos_2sigma_m = cos_sigma - 2.0*sin_u1*sin_u2/cos_sq_alpha
+		}
+
+		C := wgs84F / 16.0 * cos_sq_alpha * (4.0 + wgs84F*(4.0-3.0*cos_sq_alpha))
+
+		prev := lambda
+		lambda = L + (1.0-C)*wgs84F*sin_alpha*(sigma+C*sin_sigma*(cos_2sigma_m+C*cos_sigma*(-1.0+2.0*cos_2sigma_m*cos_2sigma_m)))
+
+		if math.Abs(lambda-prev) < 1e-12 {
+			converged = true
+			break
+		}
+	}
+
+	// in the rare cases where Vincenty doesn't converge (nearly antipodal points) settle for
+	// the great circle distance (see EarthRadius)
+
+	if !converged {
+		return a.GreatCircleDistance(b) / geo.EARTH_RADIUS * EarthRadius
+	}
+
+	u_sq := cos_sq_alpha * (wgs84A*wgs84A - wgs84B*wgs84B) / (wgs84B * wgs84B)
+
+	A := 1.0 + u_sq/16384.0*(4096.0+u_sq*(-768.0+u_sq*(320.0-175.0*u_sq)))
+	B := u_sq / 1024.0 * (256.0 + u_sq*(-128.0+u_sq*(74.0-47.0*u_sq)))
+
+	delta_sigma := B * sin_sigma * (cos_2sigma_m + B/4.0*(cos_sigma*(-1.0+2.0*cos_2sigma_m*cos_2sigma_m)-B/6.0*cos_2sigma_m*(-3.0+4.0*sin_sigma*sin_sigma)*(-3.0+4.0*cos_2sigma_m*cos_2sigma_m)))
+
+	return wgs84B * A * (sigma - delta_sigma)
+}
@@ -0,0 +1,163 @@
+package geojson
+
+import (
+	geo "github.com/kellydunn/golang-geo"
+	"testing"
+)
+
+func TestVincentyDistance(t *testing.T) {
+
+	tests := []struct {
+		a        *geo.Point
+		b        *geo.Point
+		expected float64
+	}{
+		{geo.NewPoint(0, 0), geo.NewPoint(0, 0), 0.0},
+		{geo.NewPoint(0, 0), geo.NewPoint(0, 1), 111319.491},
+		{geo.NewPoint(0, 0), geo.NewPoint(1, 0), 110574.389},
+		{geo.NewPoint(0, 179.5), geo.NewPoint(0, -179.5), 111319.491},
+		// Flinders Peak to Buninyong, from Vincenty's paper
+		{geo.NewPoint(-37.951033417, 144.424867889), geo.NewPoint(-37.652821139, 143.926495528), 54972.271},
+	}
+
+	for _, test := range tests {
+
+		d := vincentyDistance(test.a, test.b)
+
+		if !almostEqual(d, test.expected, 0.001) {
+			t.Errorf("expected the distance from %v to %v to be %v, got %v", test.a, test.b, test.expected, d)
+		}
+	}
+
+	// nearly antipodal points don't converge but still get an answer
+
+	d := vincentyDistance(geo.NewPoint(0, 0), geo.NewPoint(0.5, 179.7))
+
+	if d < 19900000.0 || d > 20100000.0 {
+		t.Errorf("expected nearly antipodal points to be about half way round, got %v", d)
+	}
+}
+
+func TestPolygonMeasurements(t *testing.T) {
+
+	tests := []struct {
+		name              string
+		geometry          string
+		area              float64
+		geodesic_area     float64
+		perimeter         float64
+		centroid          [2]float64 // lat, lon
+		geodesic_centroid [2]float64
+	}{
+		{"square", `{"type":"Polygon","coordinates":[[[0,0],[2,0],[2,2],[0,2],[0,0]]]}`, 4, 49231550683.99, 8, [2]float64{1, 1}, [2]float64{1.00005, 1}},
+		{"clockwise", `{"type":"Polygon","coordinates":[[[0,0],[0,2],[2,2],[2,0],[0,0]]]}`, 4, 49231550683.99, 8, [2]float64{1, 1}, [2]float64{1.00005, 1}},
+		{"hole", `{"type":"Polygon","coordinates":[[[0,0],[4,0],[4,4],[0,4],[0,0]],[[0,0],[0,2],[2,2],[2,0],[0,0]]]}`, 12, 0, 24, [2]float64{2.3333, 2.3333}, [2]float64{2.3342, 2.3340}},
+		{"antimeridian", `{"type":"Polygon","coordinates":[[[179,0],[-179,0],[-179,2],[179,2],[179,0]]]}`, 4, 49231550683.99, 8, [2]float64{1, 180}, [2]float64{1.00005, -180}}, // the same place, either way
+		{"flat", `{"type":"Polygon","coordinates":[[[0,0],[2,0],[4,0],[0,0]]]}`, 0, 0, 8, [2]float64{0, 1.5}, [2]float64{0, 1.5}},
+	}
+
+	for _, test := range tests {
+
+		p := testGeometry(t, test.geometry).Polygons()[0]
+
+		if !almostEqual(p.Area(), test.area, 1e-9) {
+			t.Errorf("%s: expected an area of %v, got %v", test.name, test.area, p.Area())
+		}
+
+		if test.geodesic_area != 0.0 && !almostEqual(p.GeodesicArea(), test.geodesic_area, 1.0) {
+			t.Errorf("%s: expected a geodesic area of %v, got %v", test.name, test.geodesic_area, p.GeodesicArea())
+		}
+
+		if !almostEqual(p.Perimeter(), test.perimeter, 1e-9) {
+			t.Errorf("%s: expected a perimeter of %v, got %v", test.name, test.perimeter, p.Perimeter())
+		}
+
+		c := p.Centroid()
+
+		if !almostEqual(c.Lat(), test.centroid[0], 1e-4) || !almostEqual(c.Lng(), test.centroid[1], 1e-4) {
+			t.Errorf("%s: expected a centroid of %v, got %v", test.name, test.centroid, c)
+		}
+
+		gc := p.GeodesicCentroid()
+
+		if !almostEqual(gc.Lat(), test.geodesic_centroid[0], 1e-4) || !almostEqual(gc.Lng(), test.geodesic_centroid[1], 1e-4) {
+			t.Errorf("%s: expected a geodesic centroid of %v, got %v", test.name, test.geodesic_centroid, gc)
+		}
+	}
+}
+
+func TestFeatureMeasurements(t *testing.T) {
+
+	tests := []struct {
+		name      string
+		geometry  string
+		area      float64
+		perimeter float64
+		centroid  [2]float64 // lat, lon
+	}{
+		{"multipolygon", `{"type":"MultiPolygon","coordinates":[[[[0,0],[2,0],[2,2],[0,2],[0,0]]],[[[4,0],[5,0],[5,1],[4,1],[4,0]]]]}`, 5, 12, [2]float64{0.9, 1.7}},
+		{"line", `{"type":"LineString","coordinates":[[0,0],[3,0],[3,1]]}`, 0, 4, [2]float64{0.125, 1.875}},
+		{"lines", `{"type":"MultiLineString","coordinates":[[[0,0],[2,0]],[[0,2],[2,2]]]}`, 0, 4, [2]float64{1, 1}},
+		{"antimeridian line", `{"type":"LineString","coordinates":[[179,0],[-179,0]]}`, 0, 2, [2]float64{0, 180}},
+		{"points", `{"type":"MultiPoint","coordinates":[[0,0],[2,0],[2,4]]}`, 0, 0, [2]float64{1.3333, 1.3333}},
+		{"collection", `{"type":"GeometryCollection","geometries":[{"type":"Point","coordinates":[50,50]},{"type":"Polygon","coordinates":[[[0,0],[2,0],[2,2],[0,2],[0,0]]]}]}`, 4, 8, [2]float64{1, 1}},
+	}
+
+	for _, test := range tests {
+
+		f := geometryFeature(t, test.geometry)
+
+		area, err := f.Area()
+
+		if err != nil || !almostEqual(area, test.area, 1e-9) {
+			t.Errorf("%s: expected an area of %v, got %v %v", test.name, test.area, area, err)
+		}
+
+		perimeter, err := f.Perimeter()
+
+		if err != nil || !almostEqual(perimeter, test.perimeter, 1e-9) {
+			t.Errorf("%s: expected a perimeter of %v, got %v %v", test.name, test.perimeter, perimeter, err)
+		}
+
+		c, err := f.Centroid()
+
+		if err != nil || !almostEqual(c.Lat(), test.centroid[0], 1e-4) || !almostEqual(c.Lng(), test.centroid[1], 1e-4) {
+			t.Errorf("%s: expected a centroid of %v, got %v %v", test.name, test.centroid, c, err)
+		}
+
+		// features without any area fall back to the planar centroid
+
+		gc, err := f.GeodesicCentroid()
+
+		if err != nil {
+			t.Errorf("%s: %s", test.name, err)
+		} else if test.area == 0.0 && (gc.Lat() != c.Lat() || gc.Lng() != c.Lng()) {
+			t.Errorf("%s: expected the geodesic centroid to be the same as the centroid, got %v", test.name, gc)
+		}
+	}
+
+	f := geometryFeature(t, `{"type":"LineString","coordinates":[[0,0],[1,0]]}`)
+	length, err := f.GeodesicPerimeter()
+
+	if err != nil || !almostEqual(length, 111319.491, 0.001) {
+		t.Errorf("expected the geodesic length of a line to be 111319.491, got %v %v", length, err)
+	}
+
+	f = geometryFeature(t, `{"type":"MultiPoint","coordinates":[]}`)
+	_, err = f.Centroid()
+
+	if err == nil {
+		t.Error("expected a geometry without any coordinates not to have a centroid")
+	}
+
+	f = geometryFeature(t, `{"type":"Polygon","coordinates":"nope"}`)
+
+	for name, measure := range map[string]func() (float64, error){"Area": f.Area, "GeodesicArea": f.GeodesicArea, "Perimeter": f.Perimeter, "GeodesicPerimeter": f.GeodesicPerimeter} {
+
+		_, err = measure()
+
+		if err == nil {
+			t.Errorf("expected %s to fail for a broken geometry", name)
+		}
+	}
+}
@@ -0,0 +1,246 @@
+package geojson
+
+import (
+	"reflect"
+	"time"
+)
+
+/*
+
+Setters for features. Every change updates wof:lastmodified (to the current Unix time) so that
+nobody has to remember to, and replacing the geometry also updates everything that is derived
+from it:
+
+- bbox
+- geom:hash (and wof:geomhash, if the record has one; see hash.go)
+- geom:area (square degrees) and geom:area_square_m (square meters, on the WGS84 ellipsoid)
+- geom:bbox
+- geom:latitude and geom:longitude (the planar centroid)
+
+An empty geometry has no bounds, area or centroid so setting one removes all of those but the
+hash (which is still the hash of the empty geometry) instead.
+
+The changes are made to Parsed, in place, so use WriteFile to save them.
+
+*/
+
+// the geom: properties that SetGeometry removes when the geometry is empty
+
+var boundsProperties = []string{
+	"geom:area",
+	"geom:area_square_m",
+	"geom:bbox",
+	"geom:latitude",
+	"geom:longitude",
+}
+
+// SetProperty sets properties.{prop} to value, replacing whatever was there
+
+func (wof WOFFeature) SetProperty(prop string, value interface{}) error {
+
+	_, err := wof.Parsed.Set(value, "properties", prop)
+
+	if err != nil {
+		return err
+	}
+
+	return wof.touch()
+}
+
+// AppendToList adds values to the end of the list at properties.{prop}, creating it if it
+// doesn't exist yet. Values that are already in the list are left alone. It is an error for
+// the property to be something other than a list.
+
+func (wof WOFFeature) AppendToList(prop string, values ...interface{}) error {
+
+	path := "properties." + prop
+	list := make([]interface{}, 0)
+
+	if wof.Parsed.Exists("properties", prop) {
+
+		existing, err := decodeList(wof.Parsed.Search("properties", prop).Data(), path)
+
+		if err != nil {
+			return err
+		}
+
+		list = append(list, existing...)
+	}
+
+	changed := false
+
+	for _, v := range values {
+
+		if listContains(list, v, path) {
+			continue
+		}
+
+		list = append(list, v)
+		changed = true
+	}
+
+	if !changed && wof.Parsed.Exists("properties", prop) {
+		return nil
+	}
+
+	return wof.SetProperty(prop, list)
+}
+
+// RemoveProperty deletes properties.{prop}. Removing a property that doesn't exist is not an
+// error (and doesn't count as a change).
+
+func (wof WOFFeature) RemoveProperty(prop string) error {
+
+	if !wof.Parsed.Exists("properties", prop) {
+		return nil
+	}
+
+	err := wof.Parsed.Delete("properties", prop)
+
+	if err != nil {
+		return err
+	}
+
+	return wof.touch()
+}
+
+// SetGeometry replaces the feature's geometry and updates (or, for an empty geometry, removes)
+// the bbox and geom: properties to match (see above)
+
+func (wof WOFFeature) SetGeometry(g Geometry) error {
+
+	encoded := EncodeGeometry(g)
+
+	_, err := wof.Parsed.Set(encoded, "geometry")
+
+	if err != nil {
+		return err
+	}
+
+	if wof.geometry != nil {
+		wof.geometry.reset()
+	}
+
+	hash, err := hashRawGeometry(encoded)
+
+	if err != nil {
+		return err
+	}
+
+	properties := map[string]interface{}{
+		"geom:hash": hash,
+	}
+
+	if wof.Parsed.Exists("properties", "wof:geomhash") {
+		properties["wof:geomhash"] = hash
+	}
+
+	bb := ComputeBounds(g)
+
+	if bb.IsEmpty() {
+
+		// an empty geometry has no bounds, area or centroid so anything left over from the
+		// old geometry has to go
+
+		if wof.Parsed.Exists("bbox") {
+
+			err = wof.Parsed.Delete("bbox")
+
+			if err != nil {
+				return err
+			}
+		}
+
+		for _, prop := range boundsProperties {
+
+			if !wof.Parsed.Exists("properties", prop) {
+				continue
+			}
+
+			err = wof.Parsed.Delete("properties", prop)
+
+			if err != nil {
+				return err
+			}
+		}
+
+	} else {
+
+		// the same thing encoding/json would have decoded, so that BoundingBox can read it
+
+		bbox := make([]interface{}, 0)
+
+		for _, coord := range bb.Array() {
+			bbox = append(bbox, coord)
+		}
+
+		_, err = wof.Parsed.Set(bbox, "bbox")
+
+		if err != nil {
+			return err
+		}
+
+		area, err := wof.Area()
+
+		if err != nil {
+			return err
+		}
+
+		area_m, err := wof.GeodesicArea()
+
+		if err != nil {
+			return err
+		}
+
+		centroid, err := wof.Centroid()
+
+		if err != nil {
+			return err
+		}
+
+		properties["geom:area"] = area
+		properties["geom:area_square_m"] = area_m
+		properties["geom:bbox"] = bb.String()
+		properties["geom:latitude"] = centroid.Lat()
+		properties["geom:longitude"] = centroid.Lng()
+	}
+
+	for k, v := range properties {
+
+		_, err = wof.Parsed.Set(v, "properties", k)
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return wof.touch()
+}
+
+// touch updates wof:lastmodified; it is stored as an int so that IntProperty can read it
+
+func (wof WOFFeature) touch() error {
+
+	_, err := wof.Parsed.Set(int(time.Now().Unix()), "properties", "wof:lastmodified")
+	return err
+}
+
+func listContains(list []interface{}, value interface{}, path string) bool {
+
+	value, err := normalizeValue(value, path)
+
+	if err != nil {
+		return false
+	}
+
+	for i, item := range list {
+
+		item, err := normalizeValue(item, indexPath(path, i))
+
+		if err == nil && reflect.DeepEqual(numberValues(item), numberValues(value)) {
+			return true
+		}
+	}
+
+	return false
+}
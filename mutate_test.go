@@ -0,0 +1,246 @@
+package geojson
+
+import (
+	"testing"
+	"time"
+)
+
+const mutateFeature = `{
+	"id": 1,
+	"type": "Feature",
+	"properties": {
+		"wof:id": 1,
+		"wof:name": "Square",
+		"wof:belongsto": [2, 3],
+		"wof:geomhash": "stale",
+		"wof:lastmodified": 0
+	},
+	"bbox": [0, 0, 10, 10],
+	"geometry": {"type": "Polygon", "coordinates": [[[0,0],[10,0],[10,10],[0,10],[0,0]]]}
+}`
+
+func checkLastModified(t *testing.T, f *WOFFeature, before int64) {
+
+	t.Helper()
+
+	lastmod, ok := f.IntProperty("wof:lastmodified")
+
+	if !ok {
+		t.Fatal("expected wof:lastmodified to be readable with IntProperty")
+	}
+
+	if int64(lastmod) < before {
+		t.Errorf("expected wof:lastmodified to be updated, got %d", lastmod)
+	}
+}
+
+func TestSetProperty(t *testing.T) {
+
+	f := testFeature(t, mutateFeature)
+	now := time.Now().Unix()
+
+	err := f.SetProperty("wof:name", "Carré")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	name, _ := f.StringProperty("wof:name")
+
+	if name != "Carré" {
+		t.Errorf("expected wof:name to be Carré, got %s", name)
+	}
+
+	checkLastModified(t, f, now)
+}
+
+func TestAppendToList(t *testing.T) {
+
+	tests := []struct {
+		prop     string
+		values   []interface{}
+		expected int
+		changed  bool
+		err      bool
+	}{
+		{"wof:belongsto", []interface{}{4}, 3, true, false},
+		{"wof:belongsto", []interface{}{2, 3.0}, 2, false, false},
+		{"wof:belongsto", []interface{}{4, 4, 5}, 4, true, false},
+		{"wof:supersedes", []interface{}{10}, 1, true, false},
+		{"wof:name", []interface{}{"x"}, 0, false, true},
+	}
+
+	for _, test := range tests {
+
+		f := testFeature(t, mutateFeature)
+		now := time.Now().Unix()
+
+		err := f.AppendToList(test.prop, test.values...)
+
+		if test.err {
+
+			if err == nil {
+				t.Errorf("expected appending to %s to fail", test.prop)
+			}
+
+			continue
+		}
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		count, _ := f.Body().ArrayCount("properties", test.prop)
+
+		if count != test.expected {
+			t.Errorf("expected %s to have %d items after appending %v, got %d", test.prop, test.expected, test.values, count)
+		}
+
+		if test.changed {
+			checkLastModified(t, f, now)
+		} else if lastmod, _ := f.IntProperty("wof:lastmodified"); lastmod != 0 {
+			t.Errorf("expected appending %v to %s not to count as a change", test.values, test.prop)
+		}
+	}
+}
+
+func TestRemoveProperty(t *testing.T) {
+
+	f := testFeature(t, mutateFeature)
+
+	err := f.RemoveProperty("wof:nope")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	lastmod, _ := f.IntProperty("wof:lastmodified")
+
+	if lastmod != 0 {
+		t.Error("expected removing a missing property not to count as a change")
+	}
+
+	now := time.Now().Unix()
+	err = f.RemoveProperty("wof:name")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if f.Body().Exists("properties", "wof:name") {
+		t.Error("expected wof:name to be removed")
+	}
+
+	checkLastModified(t, f, now)
+}
+
+func TestSetGeometry(t *testing.T) {
+
+	f := testFeature(t, mutateFeature)
+	now := time.Now().Unix()
+
+	// make sure the old geometry is cached so we know the cache gets reset
+
+	f.Geometry()
+
+	g := testGeometry(t, `{"type": "Polygon", "coordinates": [[[0,0],[2,0],[2,2],[0,2],[0,0]]]}`)
+
+	err := f.SetGeometry(g)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	checkLastModified(t, f, now)
+
+	bb, err := f.BoundingBox()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bb.Equals(&BoundingBox{SWLat: 0, SWLon: 0, NELat: 2, NELon: 2}, 0.0) {
+		t.Errorf("expected bbox to be 0,0,2,2, got %s", bb)
+	}
+
+	area, err := f.Area()
+
+	if err != nil || area != 4.0 {
+		t.Errorf("expected the new geometry to have an area of 4, got %f (%v)", area, err)
+	}
+
+	expected, _ := HashGeometry(g)
+
+	for _, prop := range []string{"geom:hash", "wof:geomhash"} {
+
+		hash, _ := f.StringProperty(prop)
+
+		if hash != expected {
+			t.Errorf("expected %s to be %s, got %s", prop, expected, hash)
+		}
+	}
+
+	floats := map[string]float64{
+		"geom:area":      4.0,
+		"geom:latitude":  1.0,
+		"geom:longitude": 1.0,
+	}
+
+	for prop, expected := range floats {
+
+		value, ok := f.Body().S("properties", prop).Data().(float64)
+
+		if !ok || value != expected {
+			t.Errorf("expected %s to be %f, got %v", prop, expected, f.Body().S("properties", prop).Data())
+		}
+	}
+
+	bbox, _ := f.StringProperty("geom:bbox")
+
+	if bbox != "0,0,2,2" {
+		t.Errorf("expected geom:bbox to be 0,0,2,2, got %s", bbox)
+	}
+
+	area_m, ok := f.Body().S("properties", "geom:area_square_m").Data().(float64)
+
+	if !ok || !almostEqual(area_m, 49231550683.99, 1.0) {
+		t.Errorf("unexpected geom:area_square_m %v", f.Body().S("properties", "geom:area_square_m").Data())
+	}
+}
+
+func TestSetGeometryEmpty(t *testing.T) {
+
+	f := testFeature(t, mutateFeature)
+
+	err := f.SetGeometry(testGeometry(t, `{"type": "Polygon", "coordinates": [[[0,0],[2,0],[2,2],[0,2],[0,0]]]}`))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	empty := testGeometry(t, `null`)
+
+	err = f.SetGeometry(empty)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if f.Body().Exists("bbox") {
+		t.Error("expected bbox to be removed")
+	}
+
+	for _, prop := range []string{"geom:area", "geom:area_square_m", "geom:bbox", "geom:latitude", "geom:longitude"} {
+
+		if f.Body().Exists("properties", prop) {
+			t.Errorf("expected %s to be removed, got %v", prop, f.Body().S("properties", prop).Data())
+		}
+	}
+
+	expected, _ := HashGeometry(empty)
+	hash, _ := f.StringProperty("geom:hash")
+
+	if hash != expected {
+		t.Errorf("expected geom:hash to be %s, got %s", expected, hash)
+	}
+}
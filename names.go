@@ -0,0 +1,414 @@
+package geojson
+
+import (
+	"sort"
+	"strings"
+)
+
+/*
+
+Names for a place in other languages, as stored in the name:{LANG}_x_{QUALIFIER} properties -
+for example:
+
+	"name:eng_x_preferred": ["Montreal"],
+	"name:fra_x_preferred": ["Montréal"],
+	"name:fra_x_variant": ["Ville-Marie"]
+
+where LANG is an ISO 639-3 language code (sometimes followed by other subtags, like a script,
+separated by underscores) and QUALIFIER is usually one of preferred, variant, colloquial or
+historical. The values are lists of strings.
+
+Lookups take BCP-47 language tags (en, fr-CA, zh-Hant) because that's what browsers and
+everything else speak. The language is mapped to its ISO 639-3 equivalent - see iso639_3 below
+for the codes we know about; three-letter codes are used as-is - and subtags are tried first
+and then dropped, so fr-CA will find name:fra_ca_x_preferred if there is one and otherwise
+name:fra_x_preferred.
+
+*/
+
+const (
+	NamePreferred  = "preferred"
+	NameVariant    = "variant"
+	NameColloquial = "colloquial"
+	NameHistorical = "historical"
+)
+
+// the order in which qualifiers are sorted (and tried by Names.Label)
+
+var nameQualifiers = []string{
+	NamePreferred,
+	NameVariant,
+	NameColloquial,
+	NameHistorical,
+}
+
+type Name struct {
+	Language  string   // ISO 639-3
+	Subtags   []string // anything between the language and the qualifier (lower case)
+	Qualifier string   // empty if the property doesn't have one
+	Value     string
+}
+
+// Tag returns the language code and any subtags, joined by underscores, as they appear in the
+// property name
+
+func (n *Name) Tag() string {
+	return strings.Join(append([]string{n.Language}, n.Subtags...), "_")
+}
+
+type Names struct {
+	names []*Name
+}
+
+// Names returns all of the feature's name:{LANG}_x_{QUALIFIER} properties. Properties that
+// aren't strings, or lists of strings, are ignored.
+
+func (wof WOFFeature) Names() *Names {
+
+	names := make([]*Name, 0)
+
+	properties, ok := wof.Parsed.S("properties").Data().(map[string]interface{})
+
+	if !ok {
+		return &Names{names: names}
+	}
+
+	for k, v := range properties {
+
+		if !strings.HasPrefix(k, "name:") {
+			continue
+		}
+
+		tag := strings.TrimPrefix(k, "name:")
+		qualifier := ""
+
+		idx := strings.Index(tag, "_x_")
+
+		if idx != -1 {
+			qualifier = tag[idx+3:]
+			tag = tag[0:idx]
+		}
+
+		subtags := strings.Split(strings.ToLower(tag), "_")
+
+		if subtags[0] == "" {
+			continue
+		}
+
+		values := make([]string, 0)
+
+		switch value := v.(type) {
+
+		case string:
+			values = append(values, value)
+
+		case []interface{}:
+
+			for _, item := range value {
+
+				str, ok := item.(string)
+
+				if ok {
+					values = append(values, str)
+				}
+			}
+		}
+
+		for _, value := range values {
+
+			n := Name{
+				Language:  subtags[0],
+				Subtags:   subtags[1:],
+				Qualifier: qualifier,
+				Value:     value,
+			}
+
+			names = append(names, &n)
+		}
+	}
+
+	rank := func(qualifier string) int {
+
+		for i, q := range nameQualifiers {
+
+			if q == qualifier {
+				return i
+			}
+		}
+
+		return len(nameQualifiers)
+	}
+
+	sort.SliceStable(names, func(i, j int) bool {
+
+		a := names[i]
+		b := names[j]
+
+		if a.Tag() != b.Tag() {
+			return a.Tag() < b.Tag()
+		}
+
+		if rank(a.Qualifier) != rank(b.Qualifier) {
+			return rank(a.Qualifier) < rank(b.Qualifier)
+		}
+
+		return a.Qualifier < b.Qualifier
+	})
+
+	return &Names{names: names}
+}
+
+// DefaultName returns the wof:name (or failing that name) property
+
+func (wof WOFFeature) DefaultName() (string, bool) {
+
+	name, ok := wof.name("properties.wof:name")
+
+	if ok {
+		return name, true
+	}
+
+	return wof.name("properties.name")
+}
+
+// All returns every name, sorted by language and then qualifier
+
+func (n *Names) All() []*Name {
+	return n.names
+}
+
+// Languages returns the (ISO 639-3) languages there are names for, sorted
+
+func (n *Names) Languages() []string {
+
+	seen := make(map[string]bool)
+	languages := make([]string, 0)
+
+	for _, name := range n.names {
+
+		if !seen[name.Language] {
+			seen[name.Language] = true
+			languages = append(languages, name.Language)
+		}
+	}
+
+	sort.Strings(languages)
+	return languages
+}
+
+// Get returns the names for a BCP-47 language tag and qualifier (see above for how tags are
+// matched)
+
+func (n *Names) Get(tag string, qualifier string) ([]string, bool) {
+
+	for _, candidate := range nameCandidates(tag) {
+
+		values := make([]string, 0)
+
+		for _, name := range n.names {
+
+			if name.Tag() == candidate && name.Qualifier == qualifier {
+				values = append(values, name.Value)
+			}
+		}
+
+		if len(values) > 0 {
+			return values, true
+		}
+	}
+
+	return nil, false
+}
+
+// Preferred returns the (first) preferred name for a BCP-47 language tag
+
+func (n *Names) Preferred(tag string) (string, bool) {
+
+	values, ok := n.Get(tag, NamePreferred)
+
+	if !ok {
+		return "", false
+	}
+
+	return values[0], true
+}
+
+// Label returns the best name for someone who speaks the given languages, in order of
+// preference (like an Accept-Language header): the preferred name in the first language that
+// has one or, failing that, a variant, colloquial or historical name in the first language that
+// has one of those.
+
+func (n *Names) Label(tags ...string) (string, bool) {
+
+	for _, qualifier := range nameQualifiers {
+
+		for _, tag := range tags {
+
+			values, ok := n.Get(tag, qualifier)
+
+			if ok {
+				return values[0], true
+			}
+		}
+	}
+
+	return "", false
+}
+
+// Label returns the best name (see Names.Label) for someone who speaks the given languages,
+// falling back to the default name (see DefaultName) if there isn't one
+
+func (wof WOFFeature) Label(tags ...string) (string, bool) {
+
+	name, ok := wof.Names().Label(tags...)
+
+	if ok {
+		return name, true
+	}
+
+	return wof.DefaultName()
+}
+
+// nameCandidates returns the property tags to try for a BCP-47 language tag, most specific
+// first: fr-CA becomes fra_ca and then fra
+
+func nameCandidates(tag string) []string {
+
+	subtags := strings.Split(strings.ToLower(strings.Replace(tag, "-", "_", -1)), "_")
+
+	language := subtags[0]
+
+	if code, ok := iso639_3[language]; ok {
+		language = code
+	}
+
+	candidates := make([]string, 0)
+
+	for i := len(subtags); i > 0; i-- {
+		candidates = append(candidates, strings.Join(append([]string{language}, subtags[1:i]...), "_"))
+	}
+
+	return candidates
+}
+
+// ISO 639-1 (two letter) and ISO 639-2/B (bibliographic) codes and their ISO 639-3 equivalents
+
+var iso639_3 = map[string]string{
+	"af":  "afr",
+	"am":  "amh",
+	"ar":  "ara",
+	"az":  "aze",
+	"be":  "bel",
+	"bg":  "bul",
+	"bn":  "ben",
+	"bo":  "bod",
+	"br":  "bre",
+	"bs":  "bos",
+	"ca":  "cat",
+	"cs":  "ces",
+	"cy":  "cym",
+	"da":  "dan",
+	"de":  "deu",
+	"el":  "ell",
+	"en":  "eng",
+	"eo":  "epo",
+	"es":  "spa",
+	"et":  "est",
+	"eu":  "eus",
+	"fa":  "fas",
+	"fi":  "fin",
+	"fo":  "fao",
+	"fr":  "fra",
+	"fy":  "fry",
+	"ga":  "gle",
+	"gd":  "gla",
+	"gl":  "glg",
+	"gu":  "guj",
+	"he":  "heb",
+	"hi":  "hin",
+	"hr":  "hrv",
+	"ht":  "hat",
+	"hu":  "hun",
+	"hy":  "hye",
+	"id":  "ind",
+	"is":  "isl",
+	"it":  "ita",
+	"ja":  "jpn",
+	"jv":  "jav",
+	"ka":  "kat",
+	"kk":  "kaz",
+	"km":  "khm",
+	"kn":  "kan",
+	"ko":  "kor",
+	"ku":  "kur",
+	"ky":  "kir",
+	"la":  "lat",
+	"lb":  "ltz",
+	"lo":  "lao",
+	"lt":  "lit",
+	"lv":  "lav",
+	"mi":  "mri",
+	"mk":  "mkd",
+	"ml":  "mal",
+	"mn":  "mon",
+	"mr":  "mar",
+	"ms":  "msa",
+	"mt":  "mlt",
+	"my":  "mya",
+	"nb":  "nob",
+	"ne":  "nep",
+	"nl":  "nld",
+	"nn":  "nno",
+	"no":  "nor",
+	"oc":  "oci",
+	"pa":  "pan",
+	"pl":  "pol",
+	"ps":  "pus",
+	"pt":  "por",
+	"qu":  "que",
+	"rm":  "roh",
+	"ro":  "ron",
+	"ru":  "rus",
+	"sk":  "slk",
+	"sl":  "slv",
+	"so":  "som",
+	"sq":  "sqi",
+	"sr":  "srp",
+	"sv":  "swe",
+	"sw":  "swa",
+	"ta":  "tam",
+	"te":  "tel",
+	"tg":  "tgk",
+	"th":  "tha",
+	"tl":  "tgl",
+	"tr":  "tur",
+	"uk":  "ukr",
+	"ur":  "urd",
+	"uz":  "uzb",
+	"vi":  "vie",
+	"xh":  "xho",
+	"yi":  "yid",
+	"yo":  "yor",
+	"zh":  "zho",
+	"zu":  "zul",
+	"alb": "sqi",
+	"arm": "hye",
+	"baq": "eus",
+	"bur": "mya",
+	"chi": "zho",
+	"cze": "ces",
+	"dut": "nld",
+	"fre": "fra",
+	"geo": "kat",
+	"ger": "deu",
+	"gre": "ell",
+	"ice": "isl",
+	"mac": "mkd",
+	"mao": "mri",
+	"may": "msa",
+	"per": "fas",
+	"rum": "ron",
+	"slo": "slk",
+	"tib": "bod",
+	"wel": "cym",
+}
@@ -0,0 +1,169 @@
+package geojson
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestNameCandidates(t *testing.T) {
+
+	tests := []struct {
+		tag      string
+		expected []string
+	}{
+		{"en", []string{"eng"}},
+		{"fr-CA", []string{"fra_ca", "fra"}},
+		{"zh-Hant-TW", []string{"zho_hant_tw", "zho_hant", "zho"}},
+		{"zh_Hant", []string{"zho_hant", "zho"}},
+		{"ger", []string{"deu"}},
+		{"eng", []string{"eng"}},
+		{"tlh", []string{"tlh"}},
+		{"xx", []string{"xx"}},
+	}
+
+	for _, test := range tests {
+
+		candidates := nameCandidates(test.tag)
+
+		if !sameStrings(candidates, test.expected) {
+			t.Errorf("expected the candidates for %s to be %v, got %v", test.tag, test.expected, candidates)
+		}
+	}
+}
+
+const namesFeature = `{"type":"Feature","properties":{
+	"wof:name":"Montreal",
+	"name:fra_x_variant":["Ville-Marie"],
+	"name:fra_x_preferred":["Montréal"],
+	"name:fra_ca_x_colloquial":["Mourial"],
+	"name:eng_x_preferred":["Montreal"],
+	"name:eng_x_historical":["Mount Royal", 1, "Ville Marie"],
+	"name:moh_x_variant":"Tiohtià:ke",
+	"name:zho_hant_x_preferred":["蒙特婁"],
+	"name:ita":["Montreal"],
+	"name:_x_preferred":["nope"],
+	"name:deu_x_preferred":{"nope":1},
+	"names:eng_x_preferred":["nope"]
+},"geometry":null}`
+
+func TestNames(t *testing.T) {
+
+	names := testFeature(t, namesFeature).Names()
+
+	all := make([]string, 0)
+
+	for _, n := range names.All() {
+		all = append(all, n.Tag()+" "+n.Qualifier+" "+n.Value)
+	}
+
+	expected := []string{
+		"eng preferred Montreal",
+		"eng historical Mount Royal",
+		"eng historical Ville Marie",
+		"fra preferred Montréal",
+		"fra variant Ville-Marie",
+		"fra_ca colloquial Mourial",
+		"ita  Montreal",
+		"moh variant Tiohtià:ke",
+		"zho_hant preferred 蒙特婁",
+	}
+
+	if !sameStrings(all, expected) {
+		t.Errorf("expected the names to be\n%s\ngot\n%s", strings.Join(expected, "\n"), strings.Join(all, "\n"))
+	}
+
+	languages := names.Languages()
+
+	if !sameStrings(languages, []string{"eng", "fra", "ita", "moh", "zho"}) {
+		t.Errorf("unexpected languages %v", languages)
+	}
+
+	tests := []struct {
+		tag       string
+		qualifier string
+		expected  []string
+	}{
+		{"en", NamePreferred, []string{"Montreal"}},
+		{"en-GB", NameHistorical, []string{"Mount Royal", "Ville Marie"}},
+		{"fr", NameVariant, []string{"Ville-Marie"}},
+		{"fr-CA", NameColloquial, []string{"Mourial"}},
+		{"fr", NameColloquial, nil},
+		{"fre", NamePreferred, []string{"Montréal"}},
+		{"zh-Hant", NamePreferred, []string{"蒙特婁"}},
+		{"zh", NamePreferred, nil},
+		{"it", "", []string{"Montreal"}},
+		{"de", NamePreferred, nil},
+	}
+
+	for _, test := range tests {
+
+		values, ok := names.Get(test.tag, test.qualifier)
+
+		if ok != (test.expected != nil) || !sameStrings(values, test.expected) {
+			t.Errorf("expected Get(%s, %s) to be %v, got %v", test.tag, test.qualifier, test.expected, values)
+		}
+	}
+
+	preferred, ok := names.Preferred("fr-CA")
+
+	if !ok || preferred != "Montréal" {
+		t.Errorf("expected the preferred name for fr-CA to fall back to fra, got %s", preferred)
+	}
+
+	_, ok = names.Preferred("moh")
+
+	if ok {
+		t.Error("expected there not to be a preferred name in Mohawk")
+	}
+}
+
+func TestLabel(t *testing.T) {
+
+	f := testFeature(t, namesFeature)
+
+	tests := []struct {
+		tags     []string
+		expected string
+	}{
+		{[]string{"fr-CA", "en"}, "Montréal"},
+		{[]string{"en", "fr"}, "Montreal"},
+		{[]string{"de", "zh-Hant"}, "蒙特婁"},
+		{[]string{"moh", "fr"}, "Montréal"},
+		{[]string{"moh"}, "Tiohtià:ke"},
+		{[]string{"de"}, "Montreal"},
+		{[]string{}, "Montreal"},
+	}
+
+	for _, test := range tests {
+
+		label, ok := f.Label(test.tags...)
+
+		if !ok || label != test.expected {
+			t.Errorf("expected the label for %v to be %s, got %s", test.tags, test.expected, label)
+		}
+	}
+
+	_, ok := f.Names().Label("de")
+
+	if ok {
+		t.Error("expected Names.Label not to fall back to wof:name")
+	}
+
+	f = testFeature(t, `{"type":"Feature","properties":{"name":"Somewhere"},"geometry":null}`)
+
+	label, ok := f.Label("en")
+
+	if !ok || label != "Somewhere" {
+		t.Errorf("expected the label to fall back to name, got %s", label)
+	}
+
+	f = geometryFeature(t, `null`)
+
+	if _, ok := f.Label("en"); ok {
+		t.Error("expected a feature without any names not to have a label")
+	}
+
+	if len(f.Names().All()) != 0 {
+		t.Error("expected a feature without any names not to have any names")
+	}
+}
@@ -0,0 +1,109 @@
+package geojson
+
+/*
+
+The list of Who's On First placetypes (see https://github.com/whosonfirst/whosonfirst-placetypes).
+Only some of them are part of the hierarchy - the chain from planet to country, region,
+locality, neighbourhood and so on down to venue and address - and it's only those that have a
+rank, ordered from the least to the most specific, which is good enough to answer questions like
+"which of these two records is more specific?" The others (oceans and marine areas, postal codes,
+timezones and custom places) sit alongside the hierarchy rather than in it: a timezone polygon
+isn't the parent of the locality it overlaps.
+
+*/
+
+var hierarchy_placetypes = []string{
+	"planet",
+	"continent",
+	"empire",
+	"country",
+	"dependency",
+	"disputed",
+	"macroregion",
+	"region",
+	"macrocounty",
+	"county",
+	"metroarea",
+	"localadmin",
+	"locality",
+	"borough",
+	"macrohood",
+	"neighbourhood",
+	"microhood",
+	"campus",
+	"building",
+	"wing",
+	"concourse",
+	"arcade",
+	"enclosure",
+	"installation",
+	"venue",
+	"address",
+	"intersection",
+}
+
+// the placetypes that aren't part of the hierarchy
+
+var other_placetypes = []string{
+	"ocean",
+	"marinearea",
+	"postalcode",
+	"timezone",
+	"custom",
+}
+
+var placetypes []string
+var placetype_ranks map[string]int
+
+func init() {
+
+	placetypes = make([]string, 0)
+	placetypes = append(placetypes, hierarchy_placetypes...)
+	placetypes = append(placetypes, other_placetypes...)
+
+	placetype_ranks = make(map[string]int)
+
+	for i, pt := range hierarchy_placetypes {
+		placetype_ranks[pt] = i
+	}
+}
+
+func Placetypes() []string {
+
+	pts := make([]string, len(placetypes))
+	copy(pts, placetypes)
+
+	return pts
+}
+
+func IsValidPlacetype(pt string) bool {
+
+	for _, other := range placetypes {
+
+		if other == pt {
+			return true
+		}
+	}
+
+	return false
+}
+
+// HierarchyPlacetypes returns the placetypes that are part of the hierarchy, from the least to
+// the most specific
+
+func HierarchyPlacetypes() []string {
+
+	pts := make([]string, len(hierarchy_placetypes))
+	copy(pts, hierarchy_placetypes)
+
+	return pts
+}
+
+// PlacetypeRank returns a number that is bigger the more specific pt is (so a locality ranks
+// higher than a region) and false if pt isn't part of the hierarchy (see above)
+
+func PlacetypeRank(pt string) (int, bool) {
+
+	rank, ok := placetype_ranks[pt]
+	return rank, ok
+}
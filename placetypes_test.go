@@ -0,0 +1,76 @@
+package geojson
+
+import (
+	"testing"
+)
+
+func TestPlacetypeRank(t *testing.T) {
+
+	tests := []struct {
+		less_specific string
+		more_specific string
+	}{
+		{"planet", "continent"},
+		{"country", "region"},
+		{"region", "county"},
+		{"region", "locality"},
+		{"locality", "neighbourhood"},
+		{"neighbourhood", "microhood"},
+		{"microhood", "venue"},
+	}
+
+	for _, test := range tests {
+
+		a, ok_a := PlacetypeRank(test.less_specific)
+		b, ok_b := PlacetypeRank(test.more_specific)
+
+		if !ok_a || !ok_b || a >= b {
+			t.Errorf("expected %s to rank higher than %s, got %d and %d", test.more_specific, test.less_specific, b, a)
+		}
+	}
+
+	// valid placetypes that aren't part of the hierarchy don't have a rank either, so that
+	// (say) a timezone is never more specific than a venue
+
+	for _, pt := range []string{"city", "ocean", "marinearea", "postalcode", "timezone", "custom"} {
+
+		if _, ok := PlacetypeRank(pt); ok {
+			t.Errorf("expected %s not to have a rank", pt)
+		}
+	}
+
+	for i, pt := range HierarchyPlacetypes() {
+
+		rank, ok := PlacetypeRank(pt)
+
+		if !ok || rank != i {
+			t.Errorf("expected %s to have the rank %d, got %d", pt, i, rank)
+		}
+	}
+}
+
+func TestIsValidPlacetype(t *testing.T) {
+
+	for _, pt := range Placetypes() {
+
+		if !IsValidPlacetype(pt) {
+			t.Errorf("expected %s to be valid", pt)
+		}
+	}
+
+	for _, pt := range []string{"", "city", "Locality", "neighborhood"} {
+
+		if IsValidPlacetype(pt) {
+			t.Errorf("expected %q not to be valid", pt)
+		}
+	}
+
+	// Placetypes hands back a copy
+
+	pts := Placetypes()
+	pts[0] = "city"
+
+	if IsValidPlacetype("city") || Placetypes()[0] != "planet" {
+		t.Error("expected changing the list Placetypes returns not to change the placetypes")
+	}
+}
@@ -0,0 +1,691 @@
+package geojson
+
+import (
+	geo "github.com/kellydunn/golang-geo"
+	"math"
+	"sort"
+)
+
+/*
+
+Spatial predicates between two geometries, in the spirit of DE-9IM (the model behind the OGC
+"simple features" predicates):
+
+- Intersects: the geometries have at least one point in common
+- Disjoint: they don't
+- Covers: no point of the second geometry is outside the first
+- Within: the first geometry is covered by the second and their interiors have something in
+  common (which is the difference between "inside" and "running along the edge of")
+- Touches: the geometries have at least one point in common but their interiors don't
+- Overlaps: the geometries are the same kind of thing (both polygons, say), their interiors
+  have something in common and neither one covers the other
+
+Rather than computing the full intersection matrix the answers are worked out from where the
+two boundaries cross or touch each other and where a handful of sample points (vertices, the
+midpoints of edges and, for polygons, a point that is known to be inside them - see LabelPoint)
+are relative to the other geometry. Interior rings and multi-geometries are taken in to account,
+although MultiPolygons are assumed to be valid (their polygons don't overlap each other).
+
+Geometries that cross the antimeridian are tested using shifted longitudes (see
+antimeridian.go). Whether a geometry is shifted is decided for each one separately: one that
+crosses is always shifted and one that doesn't is only shifted if the other one crosses and it
+is entirely west of the prime meridian, so that it ends up next to the shifted one rather than
+on the other side of the planet from it. The one thing this gets wrong is a geometry that
+doesn't cross the antimeridian but stretches from the prime meridian nearly all the way to it
+(say from 10E to 179W) compared with one that does cross; the part of the first one that is
+west of the antimeridian is never compared with the second one.
+
+*/
+
+const (
+	locationExterior = iota
+	locationBoundary
+	locationInterior
+)
+
+type predicatePolygon struct {
+	polygon  *WOFPolygon
+	rings    [][]*geo.Point
+	prepared *preparedPolygon
+	interior *geo.Point
+	shifted  bool
+}
+
+// the point that is known to be inside the polygon is only worked out if it is needed
+
+func (p *predicatePolygon) interiorPoint() *geo.Point {
+
+	if p.interior == nil {
+
+		bb := ComputeBounds(&Polygon{p.polygon})
+		precision := math.Max(bb.NELat-bb.SWLat, bb.NELon-bb.SWLon) / 100.0
+
+		pt := p.polygon.LabelPoint(precision)
+
+		// LabelPoint always hands back longitudes between -180 and 180
+
+		if p.shifted {
+			pt = geo.NewPoint(pt.Lat(), shiftLongitude(pt.Lng()))
+		}
+
+		p.interior = pt
+	}
+
+	return p.interior
+}
+
+type predicateGeometry struct {
+	dimension int
+	points    []*geo.Point
+	lines     [][]*geo.Point
+	polygons  []*predicatePolygon
+}
+
+func newPredicateGeometry(g Geometry, shift bool) *predicateGeometry {
+
+	pg := predicateGeometry{
+		dimension: -1,
+		points:    make([]*geo.Point, 0),
+		lines:     make([][]*geo.Point, 0),
+		polygons:  make([]*predicatePolygon, 0),
+	}
+
+	maybeShift := func(points []*geo.Point) []*geo.Point {
+
+		if shift {
+			return shiftPoints(points)
+		}
+
+		return points
+	}
+
+	for _, part := range Flatten(g) {
+
+		switch geom := part.(type) {
+
+		case *Point:
+
+			pg.points = append(pg.points, maybeShift([]*geo.Point{geom.Coordinate})...)
+			pg.dimension = maxInt(pg.dimension, 0)
+
+		case *LineString:
+
+			pg.lines = append(pg.lines, maybeShift(geom.Coordinates))
+			pg.dimension = maxInt(pg.dimension, 1)
+
+		case *Polygon:
+
+			rings := polygonRings(geom.WOFPolygon)
+
+			for i, r := range rings {
+				rings[i] = maybeShift(r)
+			}
+
+			poly := WOFPolygon{
+				OuterRing:     *geo.NewPolygon(rings[0]),
+				InteriorRings: make([]geo.Polygon, 0),
+			}
+
+			for _, r := range rings[1:] {
+				poly.InteriorRings = append(poly.InteriorRings, *geo.NewPolygon(r))
+			}
+
+			pp := predicatePolygon{
+				polygon:  &poly,
+				rings:    rings,
+				prepared: preparePolygon(&poly),
+				shifted:  shift,
+			}
+
+			pg.polygons = append(pg.polygons, &pp)
+			pg.dimension = maxInt(pg.dimension, 2)
+		}
+	}
+
+	return &pg
+}
+
+func maxInt(a int, b int) int {
+
+	if a > b {
+		return a
+	}
+
+	return b
+}
+
+func (pg *predicateGeometry) isEmpty() bool {
+	return pg.dimension == -1
+}
+
+// locate reports whether a point is in the interior, on the boundary or outside of the geometry
+
+func (pg *predicateGeometry) locate(pt *geo.Point) int {
+
+	lat := pt.Lat()
+	lon := pt.Lng()
+
+	location := locationExterior
+
+	for _, p := range pg.points {
+
+		if samePoint(p, pt) {
+			return locationInterior
+		}
+	}
+
+	for _, l := range pg.lines {
+
+		count := len(l)
+
+		if count == 0 {
+			continue
+		}
+
+		// the ends of a line are its boundary, unless it is closed in which case it
+		// doesn't have one
+
+		if !samePoint(l[0], l[count-1]) && (samePoint(l[0], pt) || samePoint(l[count-1], pt)) {
+			location = locationBoundary
+			continue
+		}
+
+		if (&LineString{Coordinates: l}).Contains(lat, lon) {
+			return locationInterior
+		}
+	}
+
+	for _, p := range pg.polygons {
+
+		if p.prepared.onBoundary(lat, lon) {
+			location = locationBoundary
+			continue
+		}
+
+		if p.prepared.contains(lat, lon) {
+			return locationInterior
+		}
+	}
+
+	return location
+}
+
+// the edges of all of the lines and polygon rings
+
+func (pg *predicateGeometry) edges(set int) []*edge {
+
+	edges := make([]*edge, 0)
+
+	for _, l := range pg.lines {
+		edges = append(edges, pathEdges(set, l, false)...)
+	}
+
+	for _, p := range pg.polygons {
+
+		for _, r := range p.rings {
+			edges = append(edges, pathEdges(set, r, true)...)
+		}
+	}
+
+	return edges
+}
+
+// the points that are worth testing against another geometry: every vertex and the midpoint of
+// every edge
+
+func (pg *predicateGeometry) samples() []*geo.Point {
+
+	samples := make([]*geo.Point, 0)
+	samples = append(samples, pg.points...)
+
+	add := func(points []*geo.Point, closed bool) {
+
+		count := len(points)
+
+		for i, pt := range points {
+
+			samples = append(samples, pt)
+
+			if i > 0 {
+				samples = append(samples, midpoint(points[i-1], pt))
+			}
+		}
+
+		if closed && count > 1 && !samePoint(points[0], points[count-1]) {
+			samples = append(samples, midpoint(points[count-1], points[0]))
+		}
+	}
+
+	for _, l := range pg.lines {
+		add(l, false)
+	}
+
+	for _, p := range pg.polygons {
+
+		for _, r := range p.rings {
+			add(r, true)
+		}
+	}
+
+	return samples
+}
+
+// the points that are definitely in the interior of the geometry (as opposed to on its boundary)
+
+func (pg *predicateGeometry) interiorSamples() []*geo.Point {
+
+	samples := make([]*geo.Point, 0)
+	samples = append(samples, pg.points...)
+
+	for _, l := range pg.lines {
+
+		count := len(l)
+
+		for i := 1; i < count; i++ {
+
+			samples = append(samples, midpoint(l[i-1], l[i]))
+
+			if i < count-1 || samePoint(l[0], l[count-1]) {
+				samples = append(samples, l[i])
+			}
+		}
+	}
+
+	for _, p := range pg.polygons {
+		samples = append(samples, p.interiorPoint())
+	}
+
+	return samples
+}
+
+func midpoint(a *geo.Point, b *geo.Point) *geo.Point {
+	return geo.NewPoint((a.Lat()+b.Lat())/2.0, (a.Lng()+b.Lng())/2.0)
+}
+
+// edgesCross reports whether any edge of a crosses (or, if touching is true, touches) any edge
+// of b
+
+func edgesCross(a *predicateGeometry, b *predicateGeometry, touching bool) bool {
+
+	return sweepEdges(a, b, func(e *edge, other *edge) bool {
+		_, _, ok := crossEdges(e, other, touching)
+		return ok
+	})
+}
+
+// edgeOverlaps returns the midpoint of every stretch where an edge of a runs along an edge of b
+// (crossEdges only ever reports a single point for those)
+
+func edgeOverlaps(a *predicateGeometry, b *predicateGeometry) []*geo.Point {
+
+	overlaps := make([]*geo.Point, 0)
+
+	sweepEdges(a, b, func(e *edge, other *edge) bool {
+
+		pt, ok := overlapEdges(e, other)
+
+		if ok {
+			overlaps = append(overlaps, pt)
+		}
+
+		return false
+	})
+
+	return overlaps
+}
+
+// sweepEdges calls fn with pairs of edges, one from a and one from b, whose longitudes overlap
+// until it returns true. This is the same sweep as findIntersections except that we only care
+// about pairs of edges from different geometries.
+
+func sweepEdges(a *predicateGeometry, b *predicateGeometry, fn func(e *edge, other *edge) bool) bool {
+
+	edges := append(a.edges(0), b.edges(1)...)
+
+	sort.Slice(edges, func(i, j int) bool {
+		return edges[i].minx < edges[j].minx
+	})
+
+	active := make([]*edge, 0)
+
+	for _, e := range edges {
+
+		still_active := active[:0]
+
+		for _, other := range active {
+
+			if other.maxx >= e.minx {
+				still_active = append(still_active, other)
+			}
+		}
+
+		active = still_active
+
+		for _, other := range active {
+
+			if other.ring == e.ring {
+				continue
+			}
+
+			if fn(e, other) {
+				return true
+			}
+		}
+
+		active = append(active, e)
+	}
+
+	return false
+}
+
+// overlapEdges reports whether two edges are collinear and share more than a single point and,
+// if they do, the midpoint of the part they share
+
+func overlapEdges(a *edge, b *edge) (*geo.Point, bool) {
+
+	if orientation(a.ax, a.ay, a.bx, a.by, b.ax, b.ay) != 0.0 || orientation(a.ax, a.ay, a.bx, a.by, b.bx, b.by) != 0.0 {
+		return nil, false
+	}
+
+	dx := a.bx - a.ax
+	dy := a.by - a.ay
+	length := dx*dx + dy*dy
+
+	// where b's ends are along a, where a goes from 0 to 1
+
+	ta := ((b.ax-a.ax)*dx + (b.ay-a.ay)*dy) / length
+	tb := ((b.bx-a.ax)*dx + (b.by-a.ay)*dy) / length
+
+	lo := math.Max(0.0, math.Min(ta, tb))
+	hi := math.Min(1.0, math.Max(ta, tb))
+
+	if lo >= hi {
+		return nil, false
+	}
+
+	t := (lo + hi) / 2.0
+	return geo.NewPoint(a.ay+t*dy, a.ax+t*dx), true
+}
+
+func newPredicateGeometries(a Geometry, b Geometry) (*predicateGeometry, *predicateGeometry) {
+
+	a_crosses := CrossesAntimeridian(a)
+	b_crosses := CrossesAntimeridian(b)
+
+	return newPredicateGeometry(a, predicateShift(a, a_crosses, b_crosses)), newPredicateGeometry(b, predicateShift(b, b_crosses, a_crosses))
+}
+
+// predicateShift decides whether g needs to be shifted (see above); shifting something that
+// doesn't cross the antimeridian but does cross the prime meridian would tear it in two
+
+func predicateShift(g Geometry, crosses bool, other_crosses bool) bool {
+
+	if crosses {
+		return true
+	}
+
+	if !other_crosses {
+		return false
+	}
+
+	bb := ComputeBounds(g)
+	return !bb.IsEmpty() && bb.NELon < 0.0
+}
+
+func intersects(a *predicateGeometry, b *predicateGeometry) bool {
+
+	if a.isEmpty() || b.isEmpty() {
+		return false
+	}
+
+	if edgesCross(a, b, true) {
+		return true
+	}
+
+	// no edges touch so either one geometry is (at least partly) inside the other or
+	// they have nothing to do with each other, in which case any vertex will do
+
+	for _, pt := range a.samples() {
+
+		if b.locate(pt) != locationExterior {
+			return true
+		}
+	}
+
+	for _, pt := range b.samples() {
+
+		if a.locate(pt) != locationExterior {
+			return true
+		}
+	}
+
+	return false
+}
+
+func interiorsIntersect(a *predicateGeometry, b *predicateGeometry) bool {
+
+	if a.isEmpty() || b.isEmpty() {
+		return false
+	}
+
+	if a.dimension > b.dimension {
+		a, b = b, a
+	}
+
+	// a line crossing another line or a polygon's boundary, or two polygon boundaries
+	// crossing, means their interiors meet
+
+	if a.dimension >= 1 && edgesCross(a, b, false) {
+		return true
+	}
+
+	// as does a line running along another line, in which case the middle of the stretch
+	// they share is in the interior of both (if one of them is a polygon boundary it won't
+	// be, and that's the right answer)
+
+	if a.dimension >= 1 {
+
+		for _, pt := range edgeOverlaps(a, b) {
+
+			if a.locate(pt) == locationInterior && b.locate(pt) == locationInterior {
+				return true
+			}
+		}
+	}
+
+	for _, pt := range a.interiorSamples() {
+
+		if b.locate(pt) == locationInterior {
+			return true
+		}
+	}
+
+	// if both are polygons then any point of either one's boundary that is inside the
+	// other means their interiors meet
+
+	if a.dimension == 2 {
+
+		for _, pt := range a.samples() {
+
+			if b.locate(pt) == locationInterior {
+				return true
+			}
+		}
+
+		for _, pt := range b.samples() {
+
+			if a.locate(pt) == locationInterior {
+				return true
+			}
+		}
+
+		for _, pt := range b.interiorSamples() {
+
+			if a.locate(pt) == locationInterior {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// covered reports whether every point of a is also a point of b
+
+func covered(a *predicateGeometry, b *predicateGeometry) bool {
+
+	if a.isEmpty() || b.isEmpty() {
+		return false
+	}
+
+	if a.dimension > b.dimension {
+		return false
+	}
+
+	for _, pt := range a.samples() {
+
+		if b.locate(pt) == locationExterior {
+			return false
+		}
+	}
+
+	// a polygon whose boundary is entirely on b's boundary can still be outside of b (in one
+	// of its holes, say) so check somewhere in the middle of it too
+
+	for _, pt := range a.interiorSamples() {
+
+		if b.locate(pt) == locationExterior {
+			return false
+		}
+	}
+
+	// if a crosses b's boundary then some of it is outside of b, even if none of the
+	// samples are
+
+	if len(b.polygons) > 0 && edgesCross(a, b, false) {
+		return false
+	}
+
+	// and if any part of b's boundary is inside a polygon then so is some of b's exterior
+	// (or one of its holes)
+
+	if a.dimension == 2 {
+
+		for _, pt := range b.samples() {
+
+			if a.locate(pt) == locationInterior {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+// Intersects reports whether a and b have at least one point in common
+
+func Intersects(a Geometry, b Geometry) bool {
+
+	pa, pb := newPredicateGeometries(a, b)
+	return intersects(pa, pb)
+}
+
+// Disjoint reports whether a and b have no points in common
+
+func Disjoint(a Geometry, b Geometry) bool {
+	return !Intersects(a, b)
+}
+
+// Covers reports whether no point of b is outside of a
+
+func Covers(a Geometry, b Geometry) bool {
+
+	pa, pb := newPredicateGeometries(a, b)
+	return covered(pb, pa)
+}
+
+// Within reports whether a is covered by b and their interiors have at least one point in common
+
+func Within(a Geometry, b Geometry) bool {
+
+	pa, pb := newPredicateGeometries(a, b)
+	return covered(pa, pb) && interiorsIntersect(pa, pb)
+}
+
+// Touches reports whether a and b have at least one point in common but their interiors don't
+
+func Touches(a Geometry, b Geometry) bool {
+
+	pa, pb := newPredicateGeometries(a, b)
+	return intersects(pa, pb) && !interiorsIntersect(pa, pb)
+}
+
+// Overlaps reports whether a and b are the same kind of geometry (points, lines or polygons),
+// their interiors have at least one point in common and neither one covers the other
+
+func Overlaps(a Geometry, b Geometry) bool {
+
+	pa, pb := newPredicateGeometries(a, b)
+
+	if pa.dimension != pb.dimension {
+		return false
+	}
+
+	return interiorsIntersect(pa, pb) && !covered(pa, pb) && !covered(pb, pa)
+}
+
+func (wof WOFFeature) relate(other *WOFFeature, predicate func(a Geometry, b Geometry) bool) (bool, error) {
+
+	a, err := wof.Geometry()
+
+	if err != nil {
+		return false, err
+	}
+
+	b, err := other.Geometry()
+
+	if err != nil {
+		return false, err
+	}
+
+	return predicate(a, b), nil
+}
+
+// Intersects reports whether the two features' geometries have at least one point in common
+
+func (wof WOFFeature) Intersects(other *WOFFeature) (bool, error) {
+	return wof.relate(other, Intersects)
+}
+
+// Disjoint reports whether the two features' geometries have no points in common
+
+func (wof WOFFeature) Disjoint(other *WOFFeature) (bool, error) {
+	return wof.relate(other, Disjoint)
+}
+
+// Covers reports whether no point of the other feature's geometry is outside of this one's
+
+func (wof WOFFeature) Covers(other *WOFFeature) (bool, error) {
+	return wof.relate(other, Covers)
+}
+
+// Within reports whether the feature's geometry is inside the other feature's geometry (see
+// the package function of the same name)
+
+func (wof WOFFeature) Within(other *WOFFeature) (bool, error) {
+	return wof.relate(other, Within)
+}
+
+// Touches reports whether the two features' geometries meet without their interiors having
+// anything in common
+
+func (wof WOFFeature) Touches(other *WOFFeature) (bool, error) {
+	return wof.relate(other, Touches)
+}
+
+// Overlaps reports whether the two features' geometries partly (but not completely) cover each
+// other
+
+func (wof WOFFeature) Overlaps(other *WOFFeature) (bool, error) {
+	return wof.relate(other, Overlaps)
+}
@@ -0,0 +1,130 @@
+package geojson
+
+import (
+	"fmt"
+	"testing"
+)
+
+func squareGeometry(minx float64, miny float64, maxx float64, maxy float64) string {
+	return fmt.Sprintf(`{"type":"Polygon","coordinates":[[[%v,%v],[%v,%v],[%v,%v],[%v,%v],[%v,%v]]]}`, minx, miny, maxx, miny, maxx, maxy, minx, maxy, minx, miny)
+}
+
+func TestPredicates(t *testing.T) {
+
+	donut := `{"type":"Polygon","coordinates":[[[0,0],[6,0],[6,6],[0,6],[0,0]],[[2,2],[2,4],[4,4],[4,2],[2,2]]]}`
+
+	tests := []struct {
+		name       string
+		a          string
+		b          string
+		intersects bool
+		covers     bool // Covers(a, b)
+		within     bool // Within(a, b)
+		touches    bool
+		overlaps   bool
+	}{
+		{"inside", squareGeometry(0, 0, 4, 4), squareGeometry(1, 1, 2, 2), true, true, false, false, false},
+		{"outside", squareGeometry(1, 1, 2, 2), squareGeometry(0, 0, 4, 4), true, false, true, false, false},
+		{"same", squareGeometry(0, 0, 2, 2), squareGeometry(0, 0, 2, 2), true, true, true, false, false},
+		{"inside along an edge", squareGeometry(0, 0, 4, 4), squareGeometry(0, 0, 2, 2), true, true, false, false, false},
+		{"overlapping", squareGeometry(0, 0, 2, 2), squareGeometry(1, 1, 3, 3), true, false, false, false, true},
+		{"crosswise", squareGeometry(0, 1, 3, 2), squareGeometry(1, 0, 2, 3), true, false, false, false, true},
+		{"side by side", squareGeometry(0, 0, 2, 2), squareGeometry(2, 0, 4, 2), true, false, false, true, false},
+		{"corner to corner", squareGeometry(0, 0, 1, 1), squareGeometry(1, 1, 2, 2), true, false, false, true, false},
+		{"far apart", squareGeometry(0, 0, 1, 1), squareGeometry(5, 5, 6, 6), false, false, false, false, false},
+		{"in the hole", donut, squareGeometry(2.5, 2.5, 3.5, 3.5), false, false, false, false, false},
+		{"filling the hole", donut, squareGeometry(2, 2, 4, 4), true, false, false, true, false},
+		{"covering the hole", squareGeometry(1, 1, 5, 5), donut, true, false, false, false, true},
+		{"around the donut", squareGeometry(-1, -1, 7, 7), donut, true, true, false, false, false},
+		{"point inside", squareGeometry(0, 0, 2, 2), `{"type":"Point","coordinates":[1,1]}`, true, true, false, false, false},
+		{"point within", `{"type":"Point","coordinates":[1,1]}`, squareGeometry(0, 0, 2, 2), true, false, true, false, false},
+		{"point on the edge", squareGeometry(0, 0, 2, 2), `{"type":"Point","coordinates":[2,1]}`, true, true, false, true, false},
+		{"point in the hole", donut, `{"type":"Point","coordinates":[3,3]}`, false, false, false, false, false},
+		{"line across", squareGeometry(0, 0, 2, 2), `{"type":"LineString","coordinates":[[-1,1],[3,1]]}`, true, false, false, false, false},
+		{"line inside", squareGeometry(0, 0, 2, 2), `{"type":"LineString","coordinates":[[0.5,1],[1.5,1]]}`, true, true, false, false, false},
+		{"line along the edge", squareGeometry(0, 0, 2, 2), `{"type":"LineString","coordinates":[[0,0],[2,0]]}`, true, true, false, true, false},
+		{"line cutting the corner", `{"type":"LineString","coordinates":[[-1,1],[1,-1]]}`, squareGeometry(0, 0, 2, 2), true, false, false, true, false},
+		{"overlapping lines", `{"type":"LineString","coordinates":[[0,0],[2,0]]}`, `{"type":"LineString","coordinates":[[1,0],[3,0]]}`, true, false, false, false, true},
+		{"lines end to end", `{"type":"LineString","coordinates":[[0,0],[2,0]]}`, `{"type":"LineString","coordinates":[[2,0],[3,1]]}`, true, false, false, true, false},
+		{"points", `{"type":"MultiPoint","coordinates":[[0,0],[1,1]]}`, `{"type":"MultiPoint","coordinates":[[1,1],[2,2]]}`, true, false, false, false, true},
+		{"multipolygon", `{"type":"MultiPolygon","coordinates":[[[[0,0],[1,0],[1,1],[0,1],[0,0]]],[[[5,5],[6,5],[6,6],[5,6],[5,5]]]]}`, squareGeometry(5.2, 5.2, 5.8, 5.8), true, true, false, false, false},
+		{"empty", `{"type":"GeometryCollection","geometries":[]}`, squareGeometry(0, 0, 1, 1), false, false, false, false, false},
+	}
+
+	for _, test := range tests {
+
+		a := testGeometry(t, test.a)
+		b := testGeometry(t, test.b)
+
+		if Intersects(a, b) != test.intersects || Intersects(b, a) != test.intersects {
+			t.Errorf("%s: expected Intersects to be %t", test.name, test.intersects)
+		}
+
+		if Disjoint(a, b) == test.intersects {
+			t.Errorf("%s: expected Disjoint to be %t", test.name, !test.intersects)
+		}
+
+		if Covers(a, b) != test.covers {
+			t.Errorf("%s: expected Covers to be %t", test.name, test.covers)
+		}
+
+		if Within(a, b) != test.within {
+			t.Errorf("%s: expected Within to be %t", test.name, test.within)
+		}
+
+		if Touches(a, b) != test.touches || Touches(b, a) != test.touches {
+			t.Errorf("%s: expected Touches to be %t", test.name, test.touches)
+		}
+
+		if Overlaps(a, b) != test.overlaps || Overlaps(b, a) != test.overlaps {
+			t.Errorf("%s: expected Overlaps to be %t", test.name, test.overlaps)
+		}
+	}
+}
+
+func TestFeaturePredicates(t *testing.T) {
+
+	big := geometryFeature(t, squareGeometry(0, 0, 4, 4))
+	small := geometryFeature(t, squareGeometry(1, 1, 2, 2))
+	broken := geometryFeature(t, `{"type":"Polygon","coordinates":"nope"}`)
+
+	tests := []struct {
+		name      string
+		predicate func(*WOFFeature) (bool, error)
+		other     *WOFFeature
+		expected  bool
+	}{
+		{"Intersects", big.Intersects, small, true},
+		{"Disjoint", big.Disjoint, small, false},
+		{"Covers", big.Covers, small, true},
+		{"Within", small.Within, big, true},
+		{"Touches", big.Touches, small, false},
+		{"Overlaps", big.Overlaps, small, false},
+	}
+
+	for _, test := range tests {
+
+		ok, err := test.predicate(test.other)
+
+		if err != nil {
+			t.Errorf("%s: %s", test.name, err)
+			continue
+		}
+
+		if ok != test.expected {
+			t.Errorf("expected %s to be %t", test.name, test.expected)
+		}
+
+		_, err = test.predicate(broken)
+
+		if err == nil {
+			t.Errorf("expected %s to fail for a broken geometry", test.name)
+		}
+	}
+
+	_, err := broken.Intersects(big)
+
+	if err == nil {
+		t.Error("expected Intersects to fail for a broken geometry")
+	}
+}
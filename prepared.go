@@ -0,0 +1,296 @@
+package geojson
+
+import (
+	geo "github.com/kellydunn/golang-geo"
+	"math"
+)
+
+/*
+
+PreparedFeature is for when you are going to ask the same feature whether it contains a point
+over and over again (say in a point-in-polygon service). The geometry is decoded once and the
+edges of every polygon are bucketed in to bands by longitude (the axis the raycasting cares
+about) so that a containment test only has to look at the handful of edges that could possibly
+cross the ray rather than all of them.
+
+Raycasting uses the same edge test as WOFPolygon.Contains so the answers are the same; there is
+just a lot less of it. See cmd/wof-geojson-benchmark-contains.go for numbers.
+
+*/
+
+type PreparedFeature struct {
+	Feature    *WOFFeature
+	parts      []preparedGeometry
+	geometries []Geometry
+}
+
+// a simple geometry (see Flatten) that has been made ready for repeated containment tests
+
+type preparedGeometry interface {
+	contains(latitude float64, longitude float64) bool
+}
+
+// points and lines don't need any preparing
+
+type unpreparedGeometry struct {
+	geometry Geometry
+}
+
+func (g *unpreparedGeometry) contains(latitude float64, longitude float64) bool {
+	return g.geometry.Contains(latitude, longitude)
+}
+
+type preparedEdge struct {
+	start *geo.Point
+	end   *geo.Point
+}
+
+// the edges for a single ring that fall in a given band
+
+type preparedRing struct {
+	ring  int // 0 is the outer ring, everything else is a hole
+	edges []preparedEdge
+}
+
+type preparedPolygon struct {
+	swlat     float64
+	swlon     float64
+	nelat     float64
+	nelon     float64
+	bandwidth float64
+	bands     [][]preparedRing
+	shifted   bool // the polygon crosses the antimeridian (see antimeridian.go)
+}
+
+func NewPreparedFeature(f *WOFFeature) (*PreparedFeature, error) {
+
+	geom, err := f.Geometry()
+
+	if err != nil {
+		return nil, err
+	}
+
+	parts := make([]preparedGeometry, 0)
+	geometries := Flatten(geom)
+
+	for _, part := range geometries {
+
+		poly, ok := part.(*Polygon)
+
+		if ok {
+			parts = append(parts, preparePolygon(poly.WOFPolygon))
+		} else {
+			parts = append(parts, &unpreparedGeometry{part})
+		}
+	}
+
+	pf := PreparedFeature{
+		Feature:    f,
+		parts:      parts,
+		geometries: geometries,
+	}
+
+	return &pf, nil
+}
+
+// Contains reports whether any of the feature's simple geometries contains the point
+
+func (pf *PreparedFeature) Contains(latitude float64, longitude float64) bool {
+
+	for _, part := range pf.parts {
+
+		if part.contains(latitude, longitude) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ContainsAtOffset reports whether the simple geometry at offset (as in the Offset property
+// of the WOFSpatial objects returned by EnSpatializeGeom) contains the point
+
+func (pf *PreparedFeature) ContainsAtOffset(offset int, latitude float64, longitude float64) bool {
+
+	if offset < 0 || offset >= len(pf.parts) {
+		return false
+	}
+
+	return pf.parts[offset].contains(latitude, longitude)
+}
+
+func preparePolygon(p *WOFPolygon) *preparedPolygon {
+
+	rings, shifted := shiftedPolygonRings(p)
+
+	count := 0
+
+	swlat := math.Inf(1)
+	swlon := math.Inf(1)
+	nelat := math.Inf(-1)
+	nelon := math.Inf(-1)
+
+	for _, pt := range rings[0] {
+
+		swlat = math.Min(swlat, pt.Lat())
+		swlon = math.Min(swlon, pt.Lng())
+		nelat = math.Max(nelat, pt.Lat())
+		nelon = math.Max(nelon, pt.Lng())
+	}
+
+	for _, points := range rings {
+		count += len(points)
+	}
+
+	// roughly 8 edges per band seems to be a reasonable trade-off between
+	// memory and speed but it's not very scientific...
+
+	nbands := count / 8
+
+	if nbands < 1 {
+		nbands = 1
+	}
+
+	if nbands > 4096 {
+		nbands = 4096
+	}
+
+	bandwidth := (nelon - swlon) / float64(nbands)
+
+	if !(bandwidth > 0.0) {
+		nbands = 1
+		bandwidth = 1.0
+	}
+
+	pp := preparedPolygon{
+		swlat:     swlat,
+		swlon:     swlon,
+		nelat:     nelat,
+		nelon:     nelon,
+		bandwidth: bandwidth,
+		bands:     make([][]preparedRing, nbands),
+		shifted:   shifted,
+	}
+
+	for idx, points := range rings {
+
+		// like golang-geo we treat rings with fewer than 3 points as
+		// containing nothing at all
+
+		if len(points) < 3 {
+			continue
+		}
+
+		for i, end := range points {
+
+			start := points[len(points)-1]
+
+			if i > 0 {
+				start = points[i-1]
+			}
+
+			first := pp.band(math.Min(start.Lng(), end.Lng()))
+			last := pp.band(math.Max(start.Lng(), end.Lng()))
+
+			for b := first; b <= last; b++ {
+				pp.addEdge(b, idx, preparedEdge{start, end})
+			}
+		}
+	}
+
+	return &pp
+}
+
+func (pp *preparedPolygon) band(longitude float64) int {
+
+	b := int((longitude - pp.swlon) / pp.bandwidth)
+
+	if b < 0 {
+		return 0
+	}
+
+	if b >= len(pp.bands) {
+		return len(pp.bands) - 1
+	}
+
+	return b
+}
+
+func (pp *preparedPolygon) addEdge(band int, ring int, edge preparedEdge) {
+
+	rings := pp.bands[band]
+	count := len(rings)
+
+	if count == 0 || rings[count-1].ring != ring {
+		pp.bands[band] = append(rings, preparedRing{ring: ring, edges: []preparedEdge{edge}})
+		return
+	}
+
+	rings[count-1].edges = append(rings[count-1].edges, edge)
+}
+
+func (pp *preparedPolygon) contains(latitude float64, longitude float64) bool {
+
+	if pp.shifted {
+		longitude = shiftLongitude(longitude)
+	}
+
+	if latitude < pp.swlat || latitude > pp.nelat || longitude < pp.swlon || longitude > pp.nelon {
+		return false
+	}
+
+	pt := geo.NewPoint(latitude, longitude)
+	rings := pp.bands[pp.band(longitude)]
+
+	for _, r := range rings {
+
+		inside := false
+
+		for _, e := range r.edges {
+
+			if intersectsWithRaycast(pt, e.start, e.end) {
+				inside = !inside
+			}
+		}
+
+		if r.ring == 0 && !inside {
+			return false
+		}
+
+		if r.ring != 0 && inside {
+			return false
+		}
+	}
+
+	// if the outer ring has no edges in this band we're not inside it
+
+	return len(rings) > 0 && rings[0].ring == 0
+}
+
+// onBoundary reports whether the point falls on one of the polygon's edges (give or take the
+// same tiny epsilon that LineString.Contains uses)
+
+func (pp *preparedPolygon) onBoundary(latitude float64, longitude float64) bool {
+
+	if pp.shifted {
+		longitude = shiftLongitude(longitude)
+	}
+
+	epsilon := 1e-12
+
+	if latitude < pp.swlat-epsilon || latitude > pp.nelat+epsilon || longitude < pp.swlon-epsilon || longitude > pp.nelon+epsilon {
+		return false
+	}
+
+	for _, r := range pp.bands[pp.band(longitude)] {
+
+		for _, e := range r.edges {
+
+			if onSegment(latitude, longitude, e.start, e.end) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,136 @@
+package geojson
+
+import (
+	"testing"
+)
+
+func TestPreparedFeatureContains(t *testing.T) {
+
+	f := largeMultiPolygon(t, 4, 1000)
+
+	pf, err := NewPreparedFeature(f)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	polygons := f.GeomToPolygons()
+
+	points := randomPoints(f, 3000)
+
+	// points on the edges of the bands, at vertices and in (and at the edge of) the holes
+
+	for offset, part := range pf.parts {
+
+		pp := part.(*preparedPolygon)
+
+		for b := 0; b <= len(pp.bands); b++ {
+
+			lon := pp.swlon + float64(b)*pp.bandwidth
+
+			for _, lat := range []float64{pp.swlat, 44.5, 45.0, 45.3, 45.9, pp.nelat} {
+				points = append(points, testPoint{lat, lon})
+			}
+		}
+
+		for i, pt := range polygons[offset].OuterRing.Points() {
+
+			if i%50 == 0 {
+				points = append(points, testPoint{pt.Lat(), pt.Lng()})
+			}
+		}
+
+		for _, pt := range polygons[offset].InteriorRings[0].Points() {
+			points = append(points, testPoint{pt.Lat(), pt.Lng()})
+		}
+
+		cx := -100.0 + float64(offset)*3.0
+		points = append(points, testPoint{45.0, cx}, testPoint{45.1, cx + 0.1})
+	}
+
+	inside := 0
+
+	for _, pt := range points {
+
+		expected := f.Contains(pt.lat, pt.lon)
+
+		if expected {
+			inside += 1
+		}
+
+		if pf.Contains(pt.lat, pt.lon) != expected {
+			t.Errorf("PreparedFeature.Contains(%v, %v) is %t, expected %t", pt.lat, pt.lon, !expected, expected)
+		}
+
+		at_offset := false
+
+		for offset, p := range polygons {
+
+			contains := p.Contains(pt.lat, pt.lon)
+
+			if pf.ContainsAtOffset(offset, pt.lat, pt.lon) != contains {
+				t.Errorf("ContainsAtOffset(%d, %v, %v) is %t, expected %t", offset, pt.lat, pt.lon, !contains, contains)
+			}
+
+			at_offset = at_offset || contains
+		}
+
+		if at_offset != expected {
+			t.Errorf("WOFPolygon.Contains and WOFFeature.Contains disagree about %v, %v", pt.lat, pt.lon)
+		}
+	}
+
+	if inside == 0 || inside == len(points) {
+		t.Fatalf("expected a mix of points inside and outside, got %d/%d inside", inside, len(points))
+	}
+
+	// the middle of a hole isn't inside anything
+
+	if pf.Contains(45.0, -100.0) {
+		t.Error("expected the middle of a hole to be outside")
+	}
+
+	if pf.ContainsAtOffset(-1, 45.0, -99.0) || pf.ContainsAtOffset(len(polygons), 45.0, -99.0) {
+		t.Error("expected offsets out of range to contain nothing")
+	}
+}
+
+func BenchmarkWOFPolygonContains(b *testing.B) {
+
+	f := largeMultiPolygon(b, 8, 5000)
+	polygons := f.GeomToPolygons()
+	points := randomPoints(f, 1000)
+
+	b.ResetTimer()
+
+	for n := 0; n < b.N; n++ {
+
+		pt := points[n%len(points)]
+
+		for _, p := range polygons {
+
+			if p.Contains(pt.lat, pt.lon) {
+				break
+			}
+		}
+	}
+}
+
+func BenchmarkPreparedFeatureContains(b *testing.B) {
+
+	f := largeMultiPolygon(b, 8, 5000)
+	points := randomPoints(f, 1000)
+
+	pf, err := NewPreparedFeature(f)
+
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	b.ResetTimer()
+
+	for n := 0; n < b.N; n++ {
+		pt := points[n%len(points)]
+		pf.Contains(pt.lat, pt.lon)
+	}
+}
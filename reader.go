@@ -0,0 +1,359 @@
+package geojson
+
+import (
+	"encoding/json"
+	"fmt"
+	gabs "github.com/jeffail/gabs"
+	"io"
+	"os"
+)
+
+/*
+
+Reader decodes features one at a time from an io.Reader rather than loading the whole thing
+in to memory (the way UnmarshalFeatureCollection does) so that memory use stays more or less
+flat no matter how big the input is. It works out for itself whether it's been handed:
+
+- a single Feature
+- a FeatureCollection (the "features" list is streamed, one feature at a time)
+- newline-delimited GeoJSON or GeoJSON text sequences (RFC 8142) which are just a stream of
+  Features, optionally prefixed with the ASCII record separator character
+
+Note that anything that isn't valid JSON is fatal since there is no way to resynchronize the
+underlying decoder. Things that are valid JSON but not Features (for example a Geometry in the
+"features" list, or a list where a Feature should be) are reported as a *DecodeError and are
+skipped, so reading can carry on with the next one.
+
+*/
+
+const (
+	FormatUnknown           = ""
+	FormatFeature           = "Feature"
+	FormatFeatureCollection = "FeatureCollection"
+	FormatGeoJSONSeq        = "GeoJSONSeq"
+)
+
+type Reader struct {
+	decoder   *json.Decoder
+	separator *recordSeparatorReader
+	format    string
+	streaming bool // true when we are inside the "features" list of a FeatureCollection
+	records   int  // the number of top-level values read
+	features  int  // the number of items read from the current "features" list
+}
+
+func NewReader(r io.Reader) *Reader {
+
+	separator := &recordSeparatorReader{reader: r}
+
+	rdr := Reader{
+		decoder:   json.NewDecoder(separator),
+		separator: separator,
+		format:    FormatUnknown,
+	}
+
+	// see parseJSON in geojson.go
+
+	rdr.decoder.UseNumber()
+
+	return &rdr
+}
+
+// Format returns the kind of input the reader thinks it is reading. It's not possible to tell a
+// single Feature apart from a sequence of Features until the second one has been read so the
+// answer may change after the first call to Next.
+
+func (r *Reader) Format() string {
+	return r.format
+}
+
+// Next returns the next feature in the input or io.EOF when there are no more of them
+
+func (r *Reader) Next() (*WOFFeature, error) {
+
+	for {
+
+		if r.streaming {
+
+			if r.decoder.More() {
+
+				path := indexPath("features", r.features)
+				r.features += 1
+
+				var raw interface{}
+				err := r.decoder.Decode(&raw)
+
+				if err != nil {
+					return nil, err
+				}
+
+				return toFeature(raw, path)
+			}
+
+			// the closing ']' of the features list and then whatever else is left
+			// in the FeatureCollection (we don't care about any of it)
+
+			_, err := r.decoder.Token()
+
+			if err != nil {
+				return nil, err
+			}
+
+			r.streaming = false
+
+			err = r.skipObject()
+
+			if err != nil {
+				return nil, err
+			}
+
+			continue
+		}
+
+		tok, err := r.decoder.Token()
+
+		if err != nil {
+			return nil, err // including io.EOF
+		}
+
+		path := indexPath("", r.records)
+		r.records += 1
+
+		if r.records > 1 && r.format == FormatFeature {
+			r.format = FormatGeoJSONSeq
+		}
+
+		if r.separator.seen {
+			r.format = FormatGeoJSONSeq
+		}
+
+		delim, ok := tok.(json.Delim)
+
+		if !ok || delim != '{' {
+
+			// skip over the rest of it so that the next call starts with the next record
+
+			err = r.skipRest(tok)
+
+			if err != nil {
+				return nil, err
+			}
+
+			return nil, &DecodeError{Path: path, Reason: "expected dictionary"}
+		}
+
+		// Read the object one key at a time so that if (when) we hit a "features"
+		// list we can stream it rather than reading the whole thing in to memory
+
+		dict := make(map[string]interface{})
+
+		for r.decoder.More() {
+
+			tok, err := r.decoder.Token()
+
+			if err != nil {
+				return nil, err
+			}
+
+			key, _ := tok.(string)
+
+			if key == "features" {
+
+				tok, err := r.decoder.Token()
+
+				if err != nil {
+					return nil, err
+				}
+
+				delim, ok := tok.(json.Delim)
+
+				if !ok || delim != '[' {
+
+					// skip over the rest of it, and the rest of the record
+
+					err = r.skipRest(tok)
+
+					if err == nil {
+						err = r.skipObject()
+					}
+
+					if err != nil {
+						return nil, err
+					}
+
+					return nil, &DecodeError{Path: "features", Reason: "expected list"}
+				}
+
+				if r.format == FormatUnknown {
+					r.format = FormatFeatureCollection
+				}
+
+				r.streaming = true
+				r.features = 0
+				break
+			}
+
+			var value interface{}
+			err = r.decoder.Decode(&value)
+
+			if err != nil {
+				return nil, err
+			}
+
+			dict[key] = value
+		}
+
+		if r.streaming {
+			continue
+		}
+
+		_, err = r.decoder.Token() // the closing '}'
+
+		if err != nil {
+			return nil, err
+		}
+
+		// an empty FeatureCollection (no "features" key at all)
+
+		if dict["type"] == "FeatureCollection" {
+			continue
+		}
+
+		if r.format == FormatUnknown {
+			r.format = FormatFeature
+		}
+
+		return toFeature(dict, path)
+	}
+}
+
+// skipRest skips whatever is left of a value whose first token has already been read
+
+func (r *Reader) skipRest(tok json.Token) error {
+
+	switch tok {
+	case json.Delim('{'):
+		return r.skipObject()
+	case json.Delim('['):
+		return r.skipList()
+	default:
+		return nil
+	}
+}
+
+func (r *Reader) skipList() error {
+
+	for r.decoder.More() {
+
+		var ignore json.RawMessage
+		err := r.decoder.Decode(&ignore)
+
+		if err != nil {
+			return err
+		}
+	}
+
+	_, err := r.decoder.Token() // ']'
+	return err
+}
+
+func (r *Reader) skipObject() error {
+
+	for r.decoder.More() {
+
+		_, err := r.decoder.Token() // key
+
+		if err != nil {
+			return err
+		}
+
+		var ignore json.RawMessage
+		err = r.decoder.Decode(&ignore)
+
+		if err != nil {
+			return err
+		}
+	}
+
+	_, err := r.decoder.Token() // '}'
+	return err
+}
+
+func toFeature(raw interface{}, path string) (*WOFFeature, error) {
+
+	dict, ok := raw.(map[string]interface{})
+
+	if !ok {
+		return nil, expected(path, "dictionary", raw)
+	}
+
+	if dict["type"] != "Feature" {
+		return nil, &DecodeError{Path: path + ".type", Reason: fmt.Sprintf("expected Feature, got %v", dict["type"])}
+	}
+
+	parsed, err := gabs.Consume(dict)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return NewWOFFeature(parsed), nil
+}
+
+// RFC 8142 records start with an ASCII record separator (0x1E) which the JSON decoder
+// doesn't know what to do with so we just turn it in to whitespace on the way through
+
+type recordSeparatorReader struct {
+	reader io.Reader
+	seen   bool
+}
+
+func (r *recordSeparatorReader) Read(p []byte) (int, error) {
+
+	n, err := r.reader.Read(p)
+
+	for i := 0; i < n; i++ {
+
+		if p[i] == 0x1e {
+			p[i] = '\n'
+			r.seen = true
+		}
+	}
+
+	return n, err
+}
+
+// UnmarshalFileMulti reads all the features in a file, whether it is a single Feature, a
+// FeatureCollection or a GeoJSON sequence. Remember that this means they all end up in
+// memory - if that's a problem use NewReader instead.
+
+func UnmarshalFileMulti(path string) ([]*WOFFeature, error) {
+
+	fh, err := os.Open(path)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer fh.Close()
+
+	reader := NewReader(fh)
+	features := make([]*WOFFeature, 0)
+
+	for {
+
+		f, err := reader.Next()
+
+		if err == io.EOF {
+			break
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		features = append(features, f)
+	}
+
+	return features, nil
+}
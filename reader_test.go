@@ -0,0 +1,127 @@
+package geojson
+
+import (
+	"io"
+	"strings"
+	"testing"
+)
+
+func readerFeature(id string) string {
+	return `{"type":"Feature","id":` + id + `,"properties":{"wof:id":` + id + `},"geometry":{"type":"Point","coordinates":[0,0]}}`
+}
+
+// readAll reads everything from input, returning the ids of the features and the number of
+// (non-fatal) DecodeErrors along the way
+
+func readAll(t *testing.T, input string) (*Reader, []int, int, error) {
+
+	r := NewReader(strings.NewReader(input))
+
+	ids := make([]int, 0)
+	errors := 0
+
+	// more than enough calls for any of the inputs below, in case a bad record is never
+	// consumed
+
+	for i := 0; i < 100; i++ {
+
+		f, err := r.Next()
+
+		if err != nil {
+
+			if _, ok := err.(*DecodeError); ok {
+				errors += 1
+				continue
+			}
+
+			if err == io.EOF {
+				return r, ids, errors, nil
+			}
+
+			return r, ids, errors, err
+		}
+
+		ids = append(ids, f.Id())
+	}
+
+	t.Fatalf("expected the reader to reach the end of %s", input)
+	return nil, nil, 0, nil
+}
+
+func TestReader(t *testing.T) {
+
+	one := readerFeature("1")
+	two := readerFeature("2")
+	three := readerFeature("3")
+
+	tests := []struct {
+		name   string
+		input  string
+		format string
+		ids    []int
+		errors int
+	}{
+		{"feature", one, FormatFeature, []int{1}, 0},
+		{"collection", `{"type":"FeatureCollection","features":[` + one + `,` + two + `]}`, FormatFeatureCollection, []int{1, 2}, 0},
+		{"collection with other keys", `{"type":"FeatureCollection","bbox":[0,0,0,0],"features":[` + one + `],"crs":{"x":[1,{"y":2}]}}`, FormatFeatureCollection, []int{1}, 0},
+		{"empty collection", `{"type":"FeatureCollection","features":[]}`, FormatFeatureCollection, []int{}, 0},
+		{"collection without features", `{"type":"FeatureCollection"}`, FormatUnknown, []int{}, 0},
+		{"newline-delimited", one + "\n" + two + "\n" + three + "\n", FormatGeoJSONSeq, []int{1, 2, 3}, 0},
+		{"text sequence", "\x1e" + one + "\n\x1e" + two + "\n", FormatGeoJSONSeq, []int{1, 2}, 0},
+		{"geometry in a collection", `{"type":"FeatureCollection","features":[` + one + `,{"type":"Point","coordinates":[0,0]},[1,2],` + two + `]}`, FormatFeatureCollection, []int{1, 2}, 2},
+		{"list in a sequence", one + "\n[" + two + ",[3]]\n" + three, FormatGeoJSONSeq, []int{1, 3}, 1},
+		{"scalars in a sequence", one + "\n42\n\"x\"\nnull\n" + two, FormatGeoJSONSeq, []int{1, 2}, 3},
+		{"object in a sequence", one + "\n{\"nope\":{\"type\":\"Feature\"}}\n" + two, FormatGeoJSONSeq, []int{1, 2}, 1},
+		{"features that aren't a list", one + "\n" + `{"type":"FeatureCollection","features":{"a":[1]},"more":1}` + "\n" + two, FormatGeoJSONSeq, []int{1, 2}, 1},
+	}
+
+	for _, test := range tests {
+
+		r, ids, errors, err := readAll(t, test.input)
+
+		if err != nil {
+			t.Errorf("%s: unexpected error %s", test.name, err)
+			continue
+		}
+
+		if len(ids) != len(test.ids) {
+			t.Errorf("%s: expected features %v, got %v", test.name, test.ids, ids)
+			continue
+		}
+
+		for i, id := range ids {
+
+			if id != test.ids[i] {
+				t.Errorf("%s: expected features %v, got %v", test.name, test.ids, ids)
+				break
+			}
+		}
+
+		if errors != test.errors {
+			t.Errorf("%s: expected %d errors, got %d", test.name, test.errors, errors)
+		}
+
+		if r.Format() != test.format {
+			t.Errorf("%s: expected format %q, got %q", test.name, test.format, r.Format())
+		}
+	}
+}
+
+func TestReaderInvalidJSON(t *testing.T) {
+
+	for _, input := range []string{
+		readerFeature("1") + "\n{\"type\":",
+		`{"type":"FeatureCollection","features":[` + readerFeature("1") + `,}`,
+	} {
+
+		_, ids, _, err := readAll(t, input)
+
+		if err == nil {
+			t.Errorf("expected invalid JSON to be fatal for %s", input)
+		}
+
+		if len(ids) != 1 {
+			t.Errorf("expected the feature before the invalid JSON to be read, got %v", ids)
+		}
+	}
+}
@@ -0,0 +1,302 @@
+package geojson
+
+import (
+	geo "github.com/kellydunn/golang-geo"
+)
+
+/*
+
+RepairGeometry fixes the problems reported by ValidateGeometry that can be fixed without
+having to guess what the geometry was supposed to look like: repeated points are dropped,
+rings are closed, outer rings are wound counter-clockwise and holes clockwise and rings that
+cross themselves (bow-ties and figure-eights) are split in to separate rings at the point where
+they cross. A polygon whose outer ring gets split becomes a MultiPolygon.
+
+Things like holes outside their shell or rings that cross other rings are left alone and
+returned as problems (along with anything else that couldn't be fixed).
+
+*/
+
+// there's no good reason for a real ring to need more splits than this and it stops us from
+// going round in circles with rings that overlap themselves rather than cross
+
+const maxRingSplits = 64
+
+func RepairGeometry(g Geometry) (Geometry, []*GeometryProblem) {
+
+	repaired := repairGeometry(g)
+	return repaired, ValidateGeometry(repaired)
+}
+
+func repairGeometry(g Geometry) Geometry {
+
+	switch geom := g.(type) {
+
+	case *LineString:
+		return &LineString{Coordinates: dropRepeatedPoints(geom.Coordinates)}
+
+	case *MultiLineString:
+
+		lines := make([]*LineString, 0)
+
+		for _, l := range geom.LineStrings {
+			lines = append(lines, &LineString{Coordinates: dropRepeatedPoints(l.Coordinates)})
+		}
+
+		return &MultiLineString{LineStrings: lines}
+
+	case *Polygon:
+
+		polygons := repairPolygon(geom.WOFPolygon)
+
+		// if there's nothing left then the polygon is beyond saving so
+		// hand it back untouched and let the caller see why
+
+		if len(polygons) == 0 {
+			return g
+		}
+
+		if len(polygons) == 1 {
+			return &Polygon{polygons[0]}
+		}
+
+		return &MultiPolygon{WOFPolygons: polygons}
+
+	case *MultiPolygon:
+
+		polygons := make([]*WOFPolygon, 0)
+
+		for _, p := range geom.WOFPolygons {
+
+			repaired := repairPolygon(p)
+
+			if len(repaired) == 0 {
+				polygons = append(polygons, p)
+				continue
+			}
+
+			polygons = append(polygons, repaired...)
+		}
+
+		return &MultiPolygon{WOFPolygons: polygons}
+
+	case *GeometryCollection:
+
+		geometries := make([]Geometry, 0)
+
+		for _, child := range geom.Geometries {
+			geometries = append(geometries, repairGeometry(child))
+		}
+
+		return &GeometryCollection{Geometries: geometries}
+
+	default:
+		return g
+	}
+}
+
+func repairPolygon(p *WOFPolygon) []*WOFPolygon {
+
+	// polygons that cross the antimeridian are repaired using shifted longitudes (see
+	// antimeridian.go) and shifted back again at the end
+
+	rings, shifted := shiftedPolygonRings(p)
+
+	shell := closeRing(dropRepeatedPoints(rings[0]))
+
+	if len(shell) < 4 {
+		return nil
+	}
+
+	shells := make([][]*geo.Point, 0)
+
+	for _, ring := range splitRing(shell) {
+
+		if ringArea(ring) < 0.0 {
+			ring = reversePoints(ring)
+		}
+
+		shells = append(shells, ring)
+	}
+
+	if len(shells) == 0 {
+		return nil
+	}
+
+	holes := make([][]*geo.Point, 0)
+
+	for _, r := range rings[1:] {
+
+		hole := closeRing(dropRepeatedPoints(r))
+
+		if len(hole) < 4 {
+			continue
+		}
+
+		for _, ring := range splitRing(hole) {
+
+			if ringArea(ring) > 0.0 {
+				ring = reversePoints(ring)
+			}
+
+			holes = append(holes, ring)
+		}
+	}
+
+	polygons := make([]*WOFPolygon, len(shells))
+	prepared := make([]*preparedPolygon, len(shells))
+
+	for i, ring := range shells {
+		polygons[i] = &WOFPolygon{OuterRing: *geo.NewPolygon(ring), InteriorRings: make([]geo.Polygon, 0)}
+		prepared[i] = preparePolygon(polygons[i])
+	}
+
+	// holes go with whichever shell most of their points fall inside; if there isn't one
+	// they stay with the first shell and ValidateGeometry will complain about them
+
+	for _, hole := range holes {
+
+		owner := 0
+		best := 0
+
+		if len(shells) > 1 {
+
+			for i, pp := range prepared {
+
+				inside := 0
+
+				for _, pt := range hole {
+
+					if pp.contains(pt.Lat(), pt.Lng()) {
+						inside += 1
+					}
+				}
+
+				if inside > best {
+					owner = i
+					best = inside
+				}
+			}
+		}
+
+		polygons[owner].InteriorRings = append(polygons[owner].InteriorRings, *geo.NewPolygon(hole))
+	}
+
+	if shifted {
+
+		for _, poly := range polygons {
+
+			poly.OuterRing = *geo.NewPolygon(unshiftPoints(poly.OuterRing.Points()))
+
+			for i, r := range poly.InteriorRings {
+				poly.InteriorRings[i] = *geo.NewPolygon(unshiftPoints(r.Points()))
+			}
+		}
+	}
+
+	return polygons
+}
+
+// splitRing splits a closed ring that crosses (or touches) itself in to rings that don't;
+// rings that collapse to nothing in the process are dropped
+
+func splitRing(points []*geo.Point) [][]*geo.Point {
+
+	rings := make([][]*geo.Point, 0)
+
+	pending := [][]*geo.Point{points}
+	splits := 0
+
+	for len(pending) > 0 {
+
+		ring := pending[0]
+		pending = pending[1:]
+
+		if len(ring) < 4 {
+			continue
+		}
+
+		found := findIntersections([][]*geo.Point{ring}, true)
+
+		if len(found) == 0 || splits >= maxRingSplits {
+
+			if ringArea(ring) != 0.0 {
+				rings = append(rings, ring)
+			}
+
+			continue
+		}
+
+		splits += 1
+
+		x := found[0]
+		pt := geo.NewPoint(x.y, x.x)
+
+		i := x.edge_a
+		j := x.edge_b
+
+		if j < i {
+			i, j = j, i
+		}
+
+		// the ring is closed so the last point is the same as the first
+
+		count := len(ring) - 1
+
+		a := make([]*geo.Point, 0)
+		a = append(a, pt)
+		a = append(a, ring[i+1:j+1]...)
+		a = append(a, pt)
+
+		b := make([]*geo.Point, 0)
+		b = append(b, pt)
+		b = append(b, ring[j+1:count]...)
+		b = append(b, ring[0:i+1]...)
+		b = append(b, pt)
+
+		pending = append(pending, closeRing(dropRepeatedPoints(a)), closeRing(dropRepeatedPoints(b)))
+	}
+
+	return rings
+}
+
+func dropRepeatedPoints(points []*geo.Point) []*geo.Point {
+
+	cleaned := make([]*geo.Point, 0)
+
+	for _, pt := range points {
+
+		count := len(cleaned)
+
+		if count > 0 && samePoint(cleaned[count-1], pt) {
+			continue
+		}
+
+		cleaned = append(cleaned, pt)
+	}
+
+	return cleaned
+}
+
+func closeRing(points []*geo.Point) []*geo.Point {
+
+	count := len(points)
+
+	if count == 0 || samePoint(points[0], points[count-1]) {
+		return points
+	}
+
+	return append(points, points[0])
+}
+
+func reversePoints(points []*geo.Point) []*geo.Point {
+
+	count := len(points)
+	reversed := make([]*geo.Point, count)
+
+	for i, pt := range points {
+		reversed[count-1-i] = pt
+	}
+
+	return reversed
+}
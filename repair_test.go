@@ -0,0 +1,116 @@
+package geojson
+
+import (
+	geo "github.com/kellydunn/golang-geo"
+	"testing"
+)
+
+func TestRepairGeometry(t *testing.T) {
+
+	tests := []struct {
+		name     string
+		geometry string
+		expected string // the type of the repaired geometry
+		polygons int
+		problems []string
+	}{
+		{"square", `{"type":"Polygon","coordinates":[[[0,0],[2,0],[2,2],[0,2],[0,0]]]}`, "Polygon", 1, []string{}},
+		{"clockwise", `{"type":"Polygon","coordinates":[[[0,0],[0,2],[2,2],[2,0],[0,0]]]}`, "Polygon", 1, []string{}},
+		{"unclosed", `{"type":"Polygon","coordinates":[[[0,0],[2,0],[2,2],[0,2]]]}`, "Polygon", 1, []string{}},
+		{"repeated", `{"type":"Polygon","coordinates":[[[0,0],[2,0],[2,0],[2,2],[0,2],[0,0],[0,0]]]}`, "Polygon", 1, []string{}},
+		{"bow-tie", `{"type":"Polygon","coordinates":[[[0,0],[2,0],[0,2],[2,2],[0,0]]]}`, "MultiPolygon", 2, []string{}},
+		{"figure-eight", `{"type":"Polygon","coordinates":[[[0,0],[1,1],[2,0],[2,2],[1,1],[0,2],[0,0]]]}`, "MultiPolygon", 2, []string{}},
+		{"backwards hole", `{"type":"Polygon","coordinates":[[[0,0],[4,0],[4,4],[0,4],[0,0]],[[1,1],[2,1],[2,2],[1,2],[1,1]]]}`, "Polygon", 1, []string{}},
+		{"hole outside", `{"type":"Polygon","coordinates":[[[0,0],[4,0],[4,4],[0,4],[0,0]],[[5,5],[5,6],[6,6],[6,5],[5,5]]]}`, "Polygon", 1, []string{"hole-outside-shell geometry.coordinates[1]"}},
+		{"beyond saving", `{"type":"Polygon","coordinates":[[[0,0],[2,0],[0,0]]]}`, "Polygon", 1, []string{"too-few-points geometry.coordinates[0]"}},
+		{"multipolygon", `{"type":"MultiPolygon","coordinates":[[[[0,0],[2,0],[0,2],[2,2],[0,0]]],[[[5,5],[5,6],[6,6],[6,5],[5,5]]]]}`, "MultiPolygon", 3, []string{}},
+		{"line", `{"type":"LineString","coordinates":[[0,0],[0,0],[1,1]]}`, "LineString", 0, []string{}},
+		{"collection", `{"type":"GeometryCollection","geometries":[{"type":"Point","coordinates":[0,0]},{"type":"MultiLineString","coordinates":[[[0,0],[1,1],[1,1]]]}]}`, "GeometryCollection", 0, []string{}},
+		{"antimeridian", `{"type":"Polygon","coordinates":[[[177,-20],[177,-15],[-178,-15],[-178,-20],[177,-20]]]}`, "Polygon", 1, []string{}},
+	}
+
+	for _, test := range tests {
+
+		repaired, problems := RepairGeometry(testGeometry(t, test.geometry))
+
+		if repaired.Type() != test.expected {
+			t.Errorf("%s: expected a %s, got a %s", test.name, test.expected, repaired.Type())
+		}
+
+		if len(repaired.Polygons()) != test.polygons {
+			t.Errorf("%s: expected %d polygons, got %d", test.name, test.polygons, len(repaired.Polygons()))
+		}
+
+		keys := problemKeys(problems)
+
+		if !sameStrings(keys, test.problems) {
+			t.Errorf("%s: expected %v to be left, got %v", test.name, test.problems, keys)
+		}
+	}
+}
+
+func TestRepairGeometryAntimeridian(t *testing.T) {
+
+	g := testGeometry(t, `{"type":"Polygon","coordinates":[[[177,-20],[177,-15],[-178,-15],[-178,-20],[177,-20]]]}`)
+	repaired, _ := RepairGeometry(g)
+
+	for _, pt := range repaired.Polygons()[0].OuterRing.Points() {
+
+		if pt.Lng() < -180.0 || pt.Lng() > 180.0 {
+			t.Fatalf("expected the repaired ring to be shifted back, got %v", pt)
+		}
+	}
+
+	if !repaired.Contains(-17.0, 179.0) || !repaired.Contains(-17.0, -179.0) || repaired.Contains(-17.0, 0.0) {
+		t.Error("expected the repaired polygon to still cross the antimeridian")
+	}
+}
+
+func TestSplitRing(t *testing.T) {
+
+	ring := func(coords ...float64) []*geo.Point {
+
+		points := make([]*geo.Point, 0)
+
+		for i := 0; i < len(coords); i += 2 {
+			points = append(points, geo.NewPoint(coords[i+1], coords[i]))
+		}
+
+		return points
+	}
+
+	tests := []struct {
+		name     string
+		ring     []*geo.Point
+		expected []float64 // the absolute areas of the rings, in order
+	}{
+		{"square", ring(0, 0, 2, 0, 2, 2, 0, 2, 0, 0), []float64{4}},
+		{"bow-tie", ring(0, 0, 2, 0, 0, 2, 2, 2, 0, 0), []float64{1, 1}},
+		{"lopsided", ring(0, 0, 4, 0, 0, 2, 0, 4, 4, 4, 0, 0), []float64{20.0 / 3.0, 8.0 / 3.0}},
+		{"spike", ring(0, 0, 2, 0, 2, 2, 3, 3, 2, 2, 0, 2, 0, 0), []float64{4}},
+		{"flat", ring(0, 0, 1, 0, 2, 0, 0, 0), []float64{}},
+	}
+
+	for _, test := range tests {
+
+		rings := splitRing(test.ring)
+
+		if len(rings) != len(test.expected) {
+			t.Errorf("%s: expected %d rings, got %d", test.name, len(test.expected), len(rings))
+			continue
+		}
+
+		for i, r := range rings {
+
+			area := ringArea(r)
+
+			if area < 0.0 {
+				area = -area
+			}
+
+			if !almostEqual(area, test.expected[i], 1e-9) {
+				t.Errorf("%s: expected ring %d to have an area of %v, got %v", test.name, i, test.expected[i], area)
+			}
+		}
+	}
+}
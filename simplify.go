@@ -0,0 +1,522 @@
+package geojson
+
+import (
+	"container/heap"
+	"fmt"
+	geo "github.com/kellydunn/golang-geo"
+	"math"
+)
+
+/*
+
+Simplification, for when you don't need every last one of the 5,206 points in a polygon (like
+drawing it on a web map). There are two algorithms to choose from:
+
+- Douglas-Peucker drops any position that is less than Tolerance from the line between the
+  positions either side of it that are being kept
+- Visvalingam-Whyatt repeatedly drops the position that makes the smallest triangle with its
+  neighbours until every triangle left is at least Tolerance squared in area
+
+Tolerance is in degrees unless Meters is true, in which case the polygon (or line) is measured
+in meters using a simple equirectangular projection centered on it, which is more than good
+enough for deciding which positions to throw away.
+
+Simplifying polygons can easily make them invalid so every simplified polygon is checked: each
+ring must still have at least four positions, no edges may cross and the holes must still be
+inside the shell. If the polygon doesn't pass it is simplified again with half the tolerance,
+and so on a few times before giving up and keeping the original.
+
+*/
+
+const (
+	DouglasPeucker     = "douglas-peucker"
+	VisvalingamWhyatt  = "visvalingam-whyatt"
+	maxSimplifyRetries = 8
+)
+
+type SimplifyOptions struct {
+	Algorithm string
+	Tolerance float64
+	Meters    bool
+}
+
+// a projection (if there is one) for the positions being simplified so that distances can be
+// measured in the right units
+
+type simplifier struct {
+	options *SimplifyOptions
+	scale_x float64
+	scale_y float64
+}
+
+func newSimplifier(opts *SimplifyOptions, points []*geo.Point) *simplifier {
+
+	s := simplifier{
+		options: opts,
+		scale_x: 1.0,
+		scale_y: 1.0,
+	}
+
+	if opts.Meters && len(points) > 0 {
+
+		lat := 0.0
+
+		for _, pt := range points {
+			lat += pt.Lat()
+		}
+
+		lat = lat / float64(len(points))
+
+		s.scale_y = MetersPerDegree
+		s.scale_x = MetersPerDegree * math.Cos(lat*math.Pi/180.0)
+	}
+
+	return &s
+}
+
+func (s *simplifier) xy(pt *geo.Point) (float64, float64) {
+	return pt.Lng() * s.scale_x, pt.Lat() * s.scale_y
+}
+
+// Simplify returns a simplified version of the feature's geometry; the feature itself is left
+// alone. See SimplifyGeometry for details.
+
+func (wof WOFFeature) Simplify(opts *SimplifyOptions) (Geometry, error) {
+
+	geom, err := wof.Geometry()
+
+	if err != nil {
+		return nil, err
+	}
+
+	return SimplifyGeometry(geom, opts)
+}
+
+// SimplifyGeometry returns a simplified version of g. Points are left as they are. The error is
+// only ever about the options.
+
+func SimplifyGeometry(g Geometry, opts *SimplifyOptions) (Geometry, error) {
+
+	if opts.Algorithm != DouglasPeucker && opts.Algorithm != VisvalingamWhyatt {
+		return nil, fmt.Errorf("Invalid simplification algorithm '%s'", opts.Algorithm)
+	}
+
+	if opts.Tolerance < 0.0 {
+		return nil, fmt.Errorf("Invalid simplification tolerance")
+	}
+
+	return simplifyGeometry(g, opts), nil
+}
+
+func simplifyGeometry(g Geometry, opts *SimplifyOptions) Geometry {
+
+	switch geom := g.(type) {
+
+	case *LineString:
+		return simplifyLine(geom, opts)
+
+	case *MultiLineString:
+
+		lines := make([]*LineString, 0)
+
+		for _, l := range geom.LineStrings {
+			lines = append(lines, simplifyLine(l, opts))
+		}
+
+		return &MultiLineString{LineStrings: lines}
+
+	case *Polygon:
+		return &Polygon{simplifyPolygon(geom.WOFPolygon, opts)}
+
+	case *MultiPolygon:
+
+		polygons := make([]*WOFPolygon, 0)
+
+		for _, p := range geom.WOFPolygons {
+			polygons = append(polygons, simplifyPolygon(p, opts))
+		}
+
+		return &MultiPolygon{WOFPolygons: polygons}
+
+	case *GeometryCollection:
+
+		geometries := make([]Geometry, 0)
+
+		for _, child := range geom.Geometries {
+			geometries = append(geometries, simplifyGeometry(child, opts))
+		}
+
+		return &GeometryCollection{Geometries: geometries}
+
+	default:
+		return g
+	}
+}
+
+func simplifyLine(l *LineString, opts *SimplifyOptions) *LineString {
+
+	points := l.Coordinates
+	shifted := crossesAntimeridian(points)
+
+	if shifted {
+		points = shiftPoints(points)
+	}
+
+	s := newSimplifier(opts, points)
+	simplified := s.simplify(points, opts.Tolerance, false)
+
+	if shifted {
+		simplified = unshiftPoints(simplified)
+	}
+
+	return &LineString{Coordinates: simplified}
+}
+
+func simplifyPolygon(p *WOFPolygon, opts *SimplifyOptions) *WOFPolygon {
+
+	rings, shifted := shiftedPolygonRings(p)
+	s := newSimplifier(opts, rings[0])
+
+	tolerance := opts.Tolerance
+
+	for i := 0; i < maxSimplifyRetries; i++ {
+
+		simplified := make([][]*geo.Point, 0)
+
+		for _, r := range rings {
+			simplified = append(simplified, s.simplify(r, tolerance, true))
+		}
+
+		if validSimplifiedPolygon(simplified) {
+
+			if shifted {
+
+				for j, r := range simplified {
+					simplified[j] = unshiftPoints(r)
+				}
+			}
+
+			poly := WOFPolygon{
+				OuterRing:     *geo.NewPolygon(simplified[0]),
+				InteriorRings: make([]geo.Polygon, 0),
+			}
+
+			for _, r := range simplified[1:] {
+				poly.InteriorRings = append(poly.InteriorRings, *geo.NewPolygon(r))
+			}
+
+			return &poly
+		}
+
+		tolerance = tolerance / 2.0
+	}
+
+	return p
+}
+
+// these are the problems that simplification can cause; anything else was there to start with
+
+func validSimplifiedPolygon(rings [][]*geo.Point) bool {
+
+	for _, r := range rings {
+
+		if len(r) < 4 || ringArea(r) == 0.0 {
+			return false
+		}
+	}
+
+	if len(findIntersections(rings, true)) > 0 {
+		return false
+	}
+
+	if len(rings) == 1 {
+		return true
+	}
+
+	shell := preparePolygon(&WOFPolygon{OuterRing: *geo.NewPolygon(rings[0])})
+
+	// there are no crossings so a hole is either entirely inside the shell or it isn't
+
+	for _, r := range rings[1:] {
+
+		inside := 0
+
+		for _, pt := range r {
+
+			if shell.contains(pt.Lat(), pt.Lng()) {
+				inside += 1
+			}
+		}
+
+		if inside*2 < len(r) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (s *simplifier) simplify(points []*geo.Point, tolerance float64, ring bool) []*geo.Point {
+
+	count := len(points)
+
+	if tolerance == 0.0 || count < 3 || (ring && count <= 4) {
+		return points
+	}
+
+	if s.options.Algorithm == VisvalingamWhyatt {
+		return s.visvalingamWhyatt(points, tolerance, ring)
+	}
+
+	if !ring {
+		return s.douglasPeucker(points, tolerance)
+	}
+
+	// rings are split in two at the position furthest from the first one, which is always
+	// kept, and then each half is simplified on its own
+
+	fx, fy := s.xy(points[0])
+
+	furthest := 0
+	max_dist := -1.0
+
+	for i, pt := range points {
+
+		x, y := s.xy(pt)
+		dist := math.Hypot(x-fx, y-fy)
+
+		if dist > max_dist {
+			furthest = i
+			max_dist = dist
+		}
+	}
+
+	if furthest == 0 || furthest == count-1 {
+		return points
+	}
+
+	first := s.douglasPeucker(points[0:furthest+1], tolerance)
+	second := s.douglasPeucker(points[furthest:], tolerance)
+
+	simplified := make([]*geo.Point, 0)
+	simplified = append(simplified, first...)
+	simplified = append(simplified, second[1:]...)
+
+	return simplified
+}
+
+func (s *simplifier) douglasPeucker(points []*geo.Point, tolerance float64) []*geo.Point {
+
+	count := len(points)
+
+	if count < 3 {
+		return points
+	}
+
+	keep := make([]bool, count)
+	keep[0] = true
+	keep[count-1] = true
+
+	stack := [][2]int{{0, count - 1}}
+
+	for len(stack) > 0 {
+
+		span := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+
+		first := span[0]
+		last := span[1]
+
+		ax, ay := s.xy(points[first])
+		bx, by := s.xy(points[last])
+
+		index := -1
+		max_dist := -1.0
+
+		for i := first + 1; i < last; i++ {
+
+			x, y := s.xy(points[i])
+			dist := math.Sqrt(segmentDistanceSq(x, y, geo.NewPoint(ay, ax), geo.NewPoint(by, bx)))
+
+			if dist > max_dist {
+				index = i
+				max_dist = dist
+			}
+		}
+
+		if index != -1 && max_dist > tolerance {
+			keep[index] = true
+			stack = append(stack, [2]int{first, index}, [2]int{index, last})
+		}
+	}
+
+	simplified := make([]*geo.Point, 0)
+
+	for i, pt := range points {
+
+		if keep[i] {
+			simplified = append(simplified, pt)
+		}
+	}
+
+	return simplified
+}
+
+// the Visvalingam-Whyatt bits
+
+type vwPoint struct {
+	index   int
+	area    float64
+	prev    *vwPoint
+	next    *vwPoint
+	removed bool
+	pos     int // position in the heap
+}
+
+type vwQueue []*vwPoint
+
+func (q vwQueue) Len() int {
+	return len(q)
+}
+
+func (q vwQueue) Less(i, j int) bool {
+	return q[i].area < q[j].area
+}
+
+func (q vwQueue) Swap(i, j int) {
+	q[i], q[j] = q[j], q[i]
+	q[i].pos = i
+	q[j].pos = j
+}
+
+func (q *vwQueue) Push(x interface{}) {
+
+	p := x.(*vwPoint)
+	p.pos = len(*q)
+
+	*q = append(*q, p)
+}
+
+func (q *vwQueue) Pop() interface{} {
+
+	old := *q
+	count := len(old)
+
+	p := old[count-1]
+	*q = old[0 : count-1]
+
+	return p
+}
+
+func (s *simplifier) visvalingamWhyatt(points []*geo.Point, tolerance float64, ring bool) []*geo.Point {
+
+	count := len(points)
+
+	// a ring's last position is usually the same as its first, in which case we work with
+	// the rest of them as a loop and put it back at the end
+
+	closed := ring && samePoint(points[0], points[count-1])
+
+	if closed {
+		count -= 1
+	}
+
+	threshold := tolerance * tolerance
+
+	area := func(p *vwPoint) float64 {
+
+		ax, ay := s.xy(points[p.prev.index])
+		bx, by := s.xy(points[p.index])
+		cx, cy := s.xy(points[p.next.index])
+
+		return math.Abs((ax*(by-cy) + bx*(cy-ay) + cx*(ay-by)) / 2.0)
+	}
+
+	nodes := make([]*vwPoint, count)
+
+	for i := 0; i < count; i++ {
+		nodes[i] = &vwPoint{index: i}
+	}
+
+	for i := 0; i < count; i++ {
+
+		if i > 0 {
+			nodes[i].prev = nodes[i-1]
+		} else if ring {
+			nodes[i].prev = nodes[count-1]
+		}
+
+		if i < count-1 {
+			nodes[i].next = nodes[i+1]
+		} else if ring {
+			nodes[i].next = nodes[0]
+		}
+	}
+
+	queue := make(vwQueue, 0)
+
+	for _, n := range nodes {
+
+		// the ends of a line (and the first position of a ring) always stay put
+
+		if n.prev == nil || n.next == nil || (ring && n.index == 0) {
+			continue
+		}
+
+		n.area = area(n)
+		heap.Push(&queue, n)
+	}
+
+	// lines need at least 2 positions and rings 3 (plus the closing one)
+
+	minimum := 2
+
+	if ring {
+		minimum = 3
+	}
+
+	remaining := count
+
+	for queue.Len() > 0 && remaining > minimum {
+
+		n := heap.Pop(&queue).(*vwPoint)
+
+		if n.area >= threshold {
+			break
+		}
+
+		n.removed = true
+		remaining -= 1
+
+		n.prev.next = n.next
+		n.next.prev = n.prev
+
+		// a neighbour's effective area is never allowed to be smaller than that of the
+		// point that was just removed, so that points are removed in a sensible order
+
+		for _, neighbour := range []*vwPoint{n.prev, n.next} {
+
+			if neighbour.removed || neighbour.pos < 0 || neighbour.pos >= queue.Len() || queue[neighbour.pos] != neighbour {
+				continue
+			}
+
+			neighbour.area = math.Max(area(neighbour), n.area)
+			heap.Fix(&queue, neighbour.pos)
+		}
+	}
+
+	simplified := make([]*geo.Point, 0)
+
+	for i := 0; i < count; i++ {
+
+		if !nodes[i].removed {
+			simplified = append(simplified, points[i])
+		}
+	}
+
+	if closed {
+		simplified = append(simplified, simplified[0])
+	}
+
+	return simplified
+}
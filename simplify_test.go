@@ -0,0 +1,178 @@
+package geojson
+
+import (
+	geo "github.com/kellydunn/golang-geo"
+	"testing"
+)
+
+func TestSimplifyGeometryOptions(t *testing.T) {
+
+	g := testGeometry(t, `{"type":"LineString","coordinates":[[0,0],[1,0.01],[2,0]]}`)
+
+	tests := []struct {
+		opts SimplifyOptions
+		err  bool
+	}{
+		{SimplifyOptions{Algorithm: DouglasPeucker, Tolerance: 0.1}, false},
+		{SimplifyOptions{Algorithm: VisvalingamWhyatt, Tolerance: 0.1}, false},
+		{SimplifyOptions{Algorithm: "ramer", Tolerance: 0.1}, true},
+		{SimplifyOptions{Algorithm: DouglasPeucker, Tolerance: -1.0}, true},
+	}
+
+	for _, test := range tests {
+
+		_, err := SimplifyGeometry(g, &test.opts)
+
+		if (err != nil) != test.err {
+			t.Errorf("unexpected error for %v: %v", test.opts, err)
+		}
+	}
+}
+
+func TestSimplifyLine(t *testing.T) {
+
+	// a line along the equator with a bump of 0.01 degrees (a little over a kilometer) in it
+
+	g := testGeometry(t, `{"type":"LineString","coordinates":[[0,0],[0.5,0],[1,0.01],[1.5,0],[2,0]]}`)
+
+	tests := []struct {
+		opts     SimplifyOptions
+		expected int
+	}{
+		{SimplifyOptions{Algorithm: DouglasPeucker, Tolerance: 0.0}, 5},
+		{SimplifyOptions{Algorithm: DouglasPeucker, Tolerance: 0.001}, 5},
+		{SimplifyOptions{Algorithm: DouglasPeucker, Tolerance: 0.1}, 2},
+		{SimplifyOptions{Algorithm: VisvalingamWhyatt, Tolerance: 0.01}, 5},
+		{SimplifyOptions{Algorithm: VisvalingamWhyatt, Tolerance: 0.2}, 2},
+		{SimplifyOptions{Algorithm: DouglasPeucker, Tolerance: 500.0, Meters: true}, 5},
+		{SimplifyOptions{Algorithm: DouglasPeucker, Tolerance: 5000.0, Meters: true}, 2},
+	}
+
+	for _, test := range tests {
+
+		simplified, err := SimplifyGeometry(g, &test.opts)
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		count := CountPositions(simplified)
+
+		if count != test.expected {
+			t.Errorf("expected %d positions after simplifying with %v, got %d", test.expected, test.opts, count)
+		}
+	}
+}
+
+func TestSimplifyPolygonRetries(t *testing.T) {
+
+	// a one degree square with a position in the middle of each side; simplifying it with
+	// Douglas-Peucker and a tolerance bigger than the square leaves a ring with three positions
+	// so the tolerance is halved until only the extra positions are dropped or, if it never
+	// gets small enough, the polygon is left alone. Visvalingam-Whyatt always stops at a
+	// triangle, which is still a valid polygon.
+
+	g := testGeometry(t, `{"type":"Polygon","coordinates":[[[0,0],[0.5,0],[1,0],[1,0.5],[1,1],[0.5,1],[0,1],[0,0.5],[0,0]]]}`)
+
+	tests := []struct {
+		algorithm string
+		tolerance float64
+		expected  int
+	}{
+		{DouglasPeucker, 0.1, 5},
+		{DouglasPeucker, 10.0, 5},
+		{DouglasPeucker, 1000.0, 9},
+		{VisvalingamWhyatt, 0.1, 5},
+		{VisvalingamWhyatt, 1000.0, 4},
+	}
+
+	for _, test := range tests {
+
+		opts := SimplifyOptions{Algorithm: test.algorithm, Tolerance: test.tolerance}
+		simplified, err := SimplifyGeometry(g, &opts)
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		count := CountPositions(simplified)
+
+		if count != test.expected {
+			t.Errorf("expected %d positions after simplifying with %v, got %d", test.expected, opts, count)
+		}
+
+		if len(ValidateGeometry(simplified)) > 0 {
+			t.Errorf("expected simplifying with %v to leave a valid polygon", opts)
+		}
+	}
+}
+
+func TestSimplifyKeepsHolesInside(t *testing.T) {
+
+	g := testGeometry(t, `{"type":"Polygon","coordinates":[
+		[[0,0],[5,0.2],[10,0],[9.8,5],[10,10],[5,9.8],[0,10],[0.2,5],[0,0]],
+		[[4,4],[4,6],[6,6],[6,4],[4,4]]
+	]}`)
+
+	opts := SimplifyOptions{Algorithm: DouglasPeucker, Tolerance: 1.0}
+	simplified, err := SimplifyGeometry(g, &opts)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	p := simplified.(*Polygon)
+
+	if len(p.OuterRing.Points()) != 5 || len(p.InteriorRings) != 1 || len(p.InteriorRings[0].Points()) != 5 {
+		t.Errorf("expected the shell to be simplified to a square and the hole to be kept, got %d positions", CountPositions(simplified))
+	}
+
+	if len(ValidateGeometry(simplified)) > 0 {
+		t.Error("expected the simplified polygon to be valid")
+	}
+}
+
+func TestVisvalingamWhyattUnclosedRing(t *testing.T) {
+
+	// a one degree square with a position halfway along the bottom, that doesn't repeat its first
+	// position at the end
+
+	points := []*geo.Point{
+		geo.NewPoint(0, 0),
+		geo.NewPoint(0, 0.5),
+		geo.NewPoint(0, 1),
+		geo.NewPoint(1, 1),
+		geo.NewPoint(1, 0),
+	}
+
+	opts := SimplifyOptions{Algorithm: VisvalingamWhyatt, Tolerance: 0.1}
+	s := newSimplifier(&opts, points)
+
+	simplified := s.visvalingamWhyatt(points, opts.Tolerance, true)
+
+	if len(simplified) != 4 {
+		t.Fatalf("expected 4 positions, got %d", len(simplified))
+	}
+
+	last := simplified[3]
+
+	if last.Lat() != 1 || last.Lng() != 0 {
+		t.Errorf("expected the last position to be kept and not closed, got %v", last)
+	}
+}
+
+func TestSimplifyPoint(t *testing.T) {
+
+	g := testGeometry(t, `{"type":"Point","coordinates":[1,2]}`)
+
+	opts := SimplifyOptions{Algorithm: DouglasPeucker, Tolerance: 10.0}
+	simplified, err := SimplifyGeometry(g, &opts)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if simplified != g {
+		t.Error("expected points to be left alone")
+	}
+}
@@ -0,0 +1,221 @@
+package validator
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+/*
+
+A (deliberately) forgiving Extended Date/Time Format validator. It understands:
+
+- plain dates (YYYY, YYYY-MM, YYYY-MM-DD) and date-times (YYYY-MM-DDThh:mm:ss with an optional time zone)
+- negative years and "Y" prefixed years with more than four digits
+- uncertain, approximate or both qualifiers (?, ~ and %) at the end of a date
+- unspecified digits, using either "X" (current spec) or "u" (the older draft, which lots of
+  Who's On First data still uses)
+- seasons (months 21 to 24)
+- intervals (DATE/DATE) with open ("..", "open") or unknown ("", "unknown") ends
+- sets ([DATE,DATE..DATE] and {DATE,DATE})
+- "u" and "uuuu" on their own, which is how Who's On First says "we don't know"
+
+See also: https://www.loc.gov/standards/datetime/
+
+*/
+
+var re_edtf_date = regexp.MustCompile(`^(-?)([0-9uUxX]{4})(?:-([0-9uUxX]{2})(?:-([0-9uUxX]{2}))?)?[?~%]?$`)
+var re_edtf_long_year = regexp.MustCompile(`^Y-?[0-9]{5,}(?:E[0-9]+)?(?:S[0-9]+)?$`)
+var re_edtf_time = regexp.MustCompile(`^([0-9]{2}):([0-9]{2}):([0-9]{2})(?:Z|[+-][0-9]{2}(?::?[0-9]{2})?)?$`)
+
+func IsValidEDTF(str string) bool {
+
+	switch str {
+	case "", "u", "uuuu":
+		return true
+	}
+
+	first := str[0]
+	last := str[len(str)-1]
+
+	if (first == '[' && last == ']') || (first == '{' && last == '}') {
+		return isValidEDTFSet(str[1 : len(str)-1])
+	}
+
+	if strings.Contains(str, "/") {
+
+		parts := strings.Split(str, "/")
+
+		if len(parts) != 2 {
+			return false
+		}
+
+		for _, p := range parts {
+
+			switch p {
+			case "", "..", "open", "unknown":
+				continue
+			}
+
+			if !isValidEDTFDate(p) {
+				return false
+			}
+		}
+
+		return true
+	}
+
+	return isValidEDTFDate(str)
+}
+
+func isValidEDTFSet(str string) bool {
+
+	if str == "" {
+		return false
+	}
+
+	for _, member := range strings.Split(str, ",") {
+
+		member = strings.TrimSpace(member)
+
+		if strings.Contains(member, "..") {
+
+			bounds := strings.SplitN(member, "..", 2)
+
+			if bounds[0] == "" && bounds[1] == "" {
+				return false
+			}
+
+			for _, b := range bounds {
+
+				if b != "" && !isValidEDTFDate(b) {
+					return false
+				}
+			}
+
+			continue
+		}
+
+		if !isValidEDTFDate(member) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func isValidEDTFDate(str string) bool {
+
+	if re_edtf_long_year.MatchString(str) {
+		return true
+	}
+
+	if strings.Contains(str, "T") {
+
+		parts := strings.SplitN(str, "T", 2)
+		m := re_edtf_time.FindStringSubmatch(parts[1])
+
+		if m == nil {
+			return false
+		}
+
+		hours, _ := strconv.Atoi(m[1])
+		minutes, _ := strconv.Atoi(m[2])
+		seconds, _ := strconv.Atoi(m[3])
+
+		if hours > 23 || minutes > 59 || seconds > 59 {
+			return false
+		}
+
+		// date-times need a complete, fully specified date (YYYY-MM-DD, not counting the
+		// sign of a negative year)
+
+		date := strings.TrimPrefix(parts[0], "-")
+
+		if len(date) != 10 || strings.ContainsAny(date, "uUxX?~%") {
+			return false
+		}
+
+		str = parts[0]
+	}
+
+	m := re_edtf_date.FindStringSubmatch(str)
+
+	if m == nil {
+		return false
+	}
+
+	str_year := m[2]
+	str_month := m[3]
+	str_day := m[4]
+
+	if str_month == "" {
+		return true
+	}
+
+	month, month_err := strconv.Atoi(str_month)
+
+	if month_err == nil {
+
+		is_season := month >= 21 && month <= 24
+
+		if is_season {
+			return str_day == ""
+		}
+
+		if month < 1 || month > 12 {
+			return false
+		}
+
+	} else if str_month[0] != '0' && str_month[0] != '1' && !isUnspecified(str_month[0]) {
+		return false
+	}
+
+	if str_day == "" {
+		return true
+	}
+
+	day, day_err := strconv.Atoi(str_day)
+
+	if day_err != nil {
+		return str_day[0] <= '3' || isUnspecified(str_day[0])
+	}
+
+	if day < 1 || day > 31 {
+		return false
+	}
+
+	if month_err != nil {
+		return true
+	}
+
+	year, year_err := strconv.Atoi(str_year)
+
+	if year_err != nil {
+		return day <= daysInMonth(month, 2000) // assume a leap year if we don't know
+	}
+
+	return day <= daysInMonth(month, year)
+}
+
+func isUnspecified(c byte) bool {
+	return c == 'u' || c == 'U' || c == 'x' || c == 'X'
+}
+
+func daysInMonth(month int, year int) int {
+
+	switch month {
+	case 2:
+
+		if year%4 == 0 && (year%100 != 0 || year%400 == 0) {
+			return 29
+		}
+
+		return 28
+
+	case 4, 6, 9, 11:
+		return 30
+	default:
+		return 31
+	}
+}
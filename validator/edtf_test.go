@@ -0,0 +1,79 @@
+package validator
+
+import (
+	"testing"
+)
+
+func TestIsValidEDTF(t *testing.T) {
+
+	tests := []struct {
+		date     string
+		expected bool
+	}{
+		{"", true},
+		{"u", true},
+		{"uuuu", true},
+		{"2001", true},
+		{"2001-02", true},
+		{"2001-02-03", true},
+		{"-0044-03-15", true},
+		{"Y170000002", true},
+		{"Y-170000002", true},
+		{"Y17E7", false},
+		{"2001?", true},
+		{"2001-02~", true},
+		{"2001-02-03%", true},
+		{"19XX", true},
+		{"19uu-1u", true},
+		{"2001-XX-1X", true},
+		{"2001-21", true},
+		{"2001-24", true},
+		{"2001-21-01", false},
+		{"2001-25", false},
+		{"2001-13", false},
+		{"2001-00", false},
+		{"2001-2u", false},
+		{"2001-02-29", false},
+		{"2000-02-29", true},
+		{"1900-02-29", false},
+		{"uuuu-02-29", true},
+		{"2001-04-31", false},
+		{"2001-01-32", false},
+		{"2001-01-4u", false},
+		{"2001-02-03T04:05:06", true},
+		{"2001-02-03T04:05:06Z", true},
+		{"2001-02-03T04:05:06+05:30", true},
+		{"2001-02-03T24:05:06", false},
+		{"2001-02T04:05:06", false},
+		{"2001-02-uuT04:05:06", false},
+		{"-0044-03-15T12:00:00", true},
+		{"-0044-03-15T12:00:00Z", true},
+		{"-0044-03T12:00:00", false},
+		{"--044-03-15T12:00:00", false},
+		{"2001/2002", true},
+		{"2001/..", true},
+		{"../2001", true},
+		{"open/2001-02", true},
+		{"2001/unknown", true},
+		{"/2001", true},
+		{"2001/2002/2003", false},
+		{"2001/yesterday", false},
+		{"[2001,2003..2005]", true},
+		{"[..2001]", true},
+		{"{2001, 2002-03}", true},
+		{"[]", false},
+		{"[..]", false},
+		{"{2001,2001-13}", false},
+		{"01", false},
+		{"20011", false},
+		{"2001-2", false},
+		{"yesterday", false},
+	}
+
+	for _, test := range tests {
+
+		if IsValidEDTF(test.date) != test.expected {
+			t.Errorf("expected IsValidEDTF(%q) to be %t", test.date, test.expected)
+		}
+	}
+}
@@ -0,0 +1,471 @@
+package validator
+
+import (
+	"fmt"
+	geo "github.com/kellydunn/golang-geo"
+	geojson "github.com/whosonfirst/go-whosonfirst-geojson"
+	"math"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// DefaultRules are the rules a Validator uses if it isn't given any
+
+func DefaultRules() []Rule {
+	return AllRules()
+}
+
+// AllRules returns one of every rule in this package, configured with its defaults
+
+func AllRules() []Rule {
+
+	return []Rule{
+		NewRequiredPropertiesRule(),
+		&IdFilenameRule{},
+		&PlacetypeRule{},
+		&HierarchyRule{},
+		NewBoundingBoxRule(),
+		&ClosedRingsRule{},
+		&GeometryRule{},
+		&GeometryHashRule{},
+		NewEDTFRule(),
+	}
+}
+
+func problem(r Rule, severity Severity, path string, msg string, args ...interface{}) *Problem {
+
+	p := Problem{
+		Rule:     r.Name(),
+		Severity: severity,
+		Path:     path,
+		Message:  fmt.Sprintf(msg, args...),
+	}
+
+	return &p
+}
+
+// RequiredPropertiesRule checks that a list of properties exist (it doesn't care what their
+// values are)
+
+type RequiredPropertiesRule struct {
+	Properties []string
+}
+
+func NewRequiredPropertiesRule() *RequiredPropertiesRule {
+
+	r := RequiredPropertiesRule{
+		Properties: []string{
+			"wof:id",
+			"wof:name",
+			"wof:placetype",
+			"wof:parent_id",
+			"wof:hierarchy",
+			"wof:lastmodified",
+			"wof:superseded_by",
+			"wof:supersedes",
+		},
+	}
+
+	return &r
+}
+
+func (r *RequiredPropertiesRule) Name() string {
+	return "required-properties"
+}
+
+func (r *RequiredPropertiesRule) Validate(path string, f *geojson.WOFFeature) []*Problem {
+
+	problems := make([]*Problem, 0)
+
+	for _, prop := range r.Properties {
+
+		if !f.Body().Exists("properties", prop) {
+			problems = append(problems, problem(r, Error, "properties."+prop, "missing required property"))
+		}
+	}
+
+	return problems
+}
+
+// IdFilenameRule checks that a file is called {ID}.geojson (or {ID}-alt-{SOMETHING}.geojson)
+// and that the top-level "id" and "wof:id" properties agree
+
+type IdFilenameRule struct{}
+
+func (r *IdFilenameRule) Name() string {
+	return "id-filename"
+}
+
+func (r *IdFilenameRule) Validate(path string, f *geojson.WOFFeature) []*Problem {
+
+	problems := make([]*Problem, 0)
+
+	wof_id, ok := f.IntProperty("wof:id")
+
+	if !ok {
+		return problems // this is RequiredPropertiesRule's problem
+	}
+
+	top_id, ok := f.IntValue("id")
+
+	if ok && top_id != wof_id {
+		problems = append(problems, problem(r, Error, "id", "top-level id (%d) does not match wof:id (%d)", top_id, wof_id))
+	}
+
+	if path == "" {
+		return problems
+	}
+
+	fname := filepath.Base(path)
+	str_id := strconv.Itoa(wof_id)
+
+	if fname != str_id+".geojson" && !(strings.HasPrefix(fname, str_id+"-alt-") && strings.HasSuffix(fname, ".geojson")) {
+		problems = append(problems, problem(r, Error, "", "filename %s does not match wof:id (%d)", fname, wof_id))
+	}
+
+	return problems
+}
+
+// PlacetypeRule checks that wof:placetype is one of the known placetypes (see geojson.Placetypes)
+
+type PlacetypeRule struct{}
+
+func (r *PlacetypeRule) Name() string {
+	return "placetype"
+}
+
+func (r *PlacetypeRule) Validate(path string, f *geojson.WOFFeature) []*Problem {
+
+	problems := make([]*Problem, 0)
+
+	pt, ok := f.StringProperty("wof:placetype")
+
+	if ok && !geojson.IsValidPlacetype(pt) {
+		problems = append(problems, problem(r, Error, "properties.wof:placetype", "unknown placetype '%s'", pt))
+	}
+
+	return problems
+}
+
+// HierarchyRule checks that wof:hierarchy agrees with wof:parent_id and with the record itself
+
+type HierarchyRule struct{}
+
+func (r *HierarchyRule) Name() string {
+	return "hierarchy"
+}
+
+func (r *HierarchyRule) Validate(path string, f *geojson.WOFFeature) []*Problem {
+
+	problems := make([]*Problem, 0)
+
+	if !f.Body().Exists("properties", "wof:hierarchy") {
+		return problems
+	}
+
+	hierarchies := f.Hierarchy()
+
+	id := f.Id()
+	pt := f.Placetype()
+	self_key := fmt.Sprintf("%s_id", pt)
+
+	parent_id, has_parent := f.IntProperty("wof:parent_id")
+
+	// negative parent IDs mean "unknown" or "multiple parents" or similar
+
+	if has_parent && parent_id > 0 {
+
+		found := false
+
+		for _, hier := range hierarchies {
+
+			for _, other_id := range hier {
+
+				if other_id == parent_id {
+					found = true
+					break
+				}
+			}
+		}
+
+		if !found {
+			problems = append(problems, problem(r, Error, "properties.wof:parent_id", "parent ID %d does not appear in wof:hierarchy", parent_id))
+		}
+	}
+
+	if has_parent && parent_id > 0 && len(hierarchies) == 0 {
+		problems = append(problems, problem(r, Warning, "properties.wof:hierarchy", "record has a parent but an empty hierarchy"))
+	}
+
+	for i, hier := range hierarchies {
+
+		hier_path := fmt.Sprintf("properties.wof:hierarchy[%d]", i)
+
+		self, ok := hier[self_key]
+
+		if !ok {
+			problems = append(problems, problem(r, Warning, hier_path, "missing %s", self_key))
+		} else if self != id {
+			problems = append(problems, problem(r, Error, hier_path+"."+self_key, "expected %d (the record's own ID), got %d", id, self))
+		}
+
+		for k, v := range hier {
+
+			if v == -1 {
+				problems = append(problems, problem(r, Error, hier_path+"."+k, "invalid ID"))
+			}
+		}
+	}
+
+	return problems
+}
+
+// BoundingBoxRule checks that the feature has a bbox and that it agrees with its geometry, give
+// or take Tolerance degrees
+
+type BoundingBoxRule struct {
+	Tolerance float64
+}
+
+func NewBoundingBoxRule() *BoundingBoxRule {
+
+	r := BoundingBoxRule{
+		Tolerance: 0.000001,
+	}
+
+	return &r
+}
+
+func (r *BoundingBoxRule) Name() string {
+	return "bbox"
+}
+
+func (r *BoundingBoxRule) Validate(path string, f *geojson.WOFFeature) []*Problem {
+
+	problems := make([]*Problem, 0)
+
+	geom, err := f.Geometry()
+
+	if err != nil {
+		problems = append(problems, problem(r, Error, "", "%s", err.Error()))
+		return problems
+	}
+
+	computed := geojson.ComputeBounds(geom)
+
+	if computed.IsEmpty() {
+		return problems // no coordinates, nothing to compare
+	}
+
+	stored, err := f.BoundingBox()
+
+	if err == geojson.ErrNoBoundingBox {
+		problems = append(problems, problem(r, Warning, "bbox", "missing bbox"))
+		return problems
+	}
+
+	if err != nil {
+		problems = append(problems, problem(r, Error, "bbox", "%s", err.Error()))
+		return problems
+	}
+
+	labels := []string{"min longitude", "min latitude", "max longitude", "max latitude"}
+
+	want := computed.Array()
+	got := stored.Array()
+
+	// plenty of records that cross the antimeridian just say they go all the way round
+	// rather than using a west longitude that's greater than the east longitude which
+	// is wasteful but not wrong
+
+	if computed.CrossesAntimeridian() && stored.SWLon == -180.0 && stored.NELon == 180.0 {
+		want[0] = -180.0
+		want[2] = 180.0
+	}
+
+	for i, n := range got {
+
+		if math.Abs(n-want[i]) > r.Tolerance {
+			problems = append(problems, problem(r, Error, fmt.Sprintf("bbox[%d]", i), "%s is %f but the geometry says %f", labels[i], n, want[i]))
+		}
+	}
+
+	return problems
+}
+
+// ClosedRingsRule checks that every polygon ring is closed (the first and last positions are
+// the same) and has at least four positions, per the GeoJSON spec
+
+type ClosedRingsRule struct{}
+
+func (r *ClosedRingsRule) Name() string {
+	return "closed-rings"
+}
+
+func (r *ClosedRingsRule) Validate(path string, f *geojson.WOFFeature) []*Problem {
+
+	problems := make([]*Problem, 0)
+
+	polygons, err := f.GeomToPolygonsWithError()
+
+	if err != nil {
+		return problems // this is BoundingBoxRule's problem
+	}
+
+	for i, poly := range polygons {
+
+		rings := append([]geo.Polygon{poly.OuterRing}, poly.InteriorRings...)
+
+		for j, ring := range rings {
+
+			ring_path := fmt.Sprintf("polygon %d, ring %d", i, j)
+			points := ring.Points()
+			count := len(points)
+
+			if count < 4 {
+				problems = append(problems, problem(r, Error, ring_path, "expected at least 4 positions, got %d", count))
+				continue
+			}
+
+			first := points[0]
+			last := points[count-1]
+
+			if first.Lat() != last.Lat() || first.Lng() != last.Lng() {
+				problems = append(problems, problem(r, Error, ring_path, "ring is not closed"))
+			}
+		}
+	}
+
+	return problems
+}
+
+// GeometryRule reports the problems found by geojson.ValidateGeometry, except for unclosed
+// and short rings which are ClosedRingsRule's problem. Repeated points and winding order are
+// only warnings since there is plenty of otherwise perfectly good data that has them.
+
+type GeometryRule struct{}
+
+func (r *GeometryRule) Name() string {
+	return "geometry"
+}
+
+func (r *GeometryRule) Validate(path string, f *geojson.WOFFeature) []*Problem {
+
+	problems := make([]*Problem, 0)
+
+	geom, err := f.Geometry()
+
+	if err != nil {
+		return problems // this is BoundingBoxRule's problem
+	}
+
+	for _, p := range geojson.ValidateGeometry(geom) {
+
+		switch p.Type {
+		case geojson.ProblemUnclosedRing, geojson.ProblemTooFewPoints:
+			continue
+		case geojson.ProblemRepeatedPoint, geojson.ProblemWindingOrder:
+			problems = append(problems, problem(r, Warning, p.Path, "%s", p.Message))
+		default:
+			problems = append(problems, problem(r, Error, p.Path, "%s", p.Message))
+		}
+	}
+
+	return problems
+}
+
+// GeometryHashRule checks that the geom:hash (and wof:geomhash) properties, if there are any,
+// match the geometry; if they don't the geometry has been edited without going through the
+// export tools. Mismatches are only warnings because plenty of tools edit geometries without
+// knowing about geom:hash.
+
+type GeometryHashRule struct{}
+
+func (r *GeometryHashRule) Name() string {
+	return "geomhash"
+}
+
+func (r *GeometryHashRule) Validate(path string, f *geojson.WOFFeature) []*Problem {
+
+	problems := make([]*Problem, 0)
+
+	computed, err := f.GeometryHash()
+
+	if err != nil {
+		return problems // this is BoundingBoxRule's problem
+	}
+
+	for _, prop := range []string{"geom:hash", "wof:geomhash"} {
+
+		if !f.Body().Exists("properties", prop) {
+			continue
+		}
+
+		prop_path := "properties." + prop
+		stored, ok := f.StringProperty(prop)
+
+		if !ok {
+			problems = append(problems, problem(r, Error, prop_path, "expected a string"))
+			continue
+		}
+
+		if stored != computed {
+			problems = append(problems, problem(r, Warning, prop_path, "stored hash %s does not match geometry (%s)", stored, computed))
+		}
+	}
+
+	return problems
+}
+
+// EDTFRule checks that date properties are valid Extended Date/Time Format strings (see edtf.go)
+
+type EDTFRule struct {
+	Properties []string
+}
+
+func NewEDTFRule() *EDTFRule {
+
+	r := EDTFRule{
+		Properties: []string{
+			"edtf:inception",
+			"edtf:cessation",
+			"edtf:deprecated",
+			"edtf:superseded",
+			"edtf:date",
+		},
+	}
+
+	return &r
+}
+
+func (r *EDTFRule) Name() string {
+	return "edtf"
+}
+
+func (r *EDTFRule) Validate(path string, f *geojson.WOFFeature) []*Problem {
+
+	problems := make([]*Problem, 0)
+
+	for _, prop := range r.Properties {
+
+		prop_path := "properties." + prop
+
+		if !f.Body().Exists("properties", prop) {
+			continue
+		}
+
+		str_date, ok := f.Body().S("properties", prop).Data().(string)
+
+		if !ok {
+			problems = append(problems, problem(r, Error, prop_path, "expected a string"))
+			continue
+		}
+
+		if !IsValidEDTF(str_date) {
+			problems = append(problems, problem(r, Error, prop_path, "invalid EDTF date '%s'", str_date))
+		}
+	}
+
+	return problems
+}
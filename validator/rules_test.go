@@ -0,0 +1,278 @@
+package validator
+
+import (
+	geojson "github.com/whosonfirst/go-whosonfirst-geojson"
+	"testing"
+)
+
+func testFeature(t *testing.T, body string) *geojson.WOFFeature {
+
+	f, err := geojson.UnmarshalFeature([]byte(body))
+
+	if err != nil {
+		t.Fatalf("failed to parse test feature, because %s", err)
+	}
+
+	return f
+}
+
+// checkProblems validates body with a single rule and compares the problems it finds, by
+// path and severity, with the expected ones
+
+func checkProblems(t *testing.T, r Rule, path string, body string, expected map[string]Severity) {
+
+	t.Helper()
+
+	problems := r.Validate(path, testFeature(t, body))
+
+	if len(problems) != len(expected) {
+		t.Errorf("expected %d problems from %s, got %d: %v", len(expected), r.Name(), len(problems), problems)
+		return
+	}
+
+	for _, p := range problems {
+
+		severity, ok := expected[p.Path]
+
+		if !ok {
+			t.Errorf("unexpected problem from %s: %s", r.Name(), p)
+			continue
+		}
+
+		if p.Severity != severity {
+			t.Errorf("expected %s to be a %s, got %s", p, severity, p.Severity)
+		}
+	}
+}
+
+func TestGeometryHashRule(t *testing.T) {
+
+	square := `{"type":"Polygon","coordinates":[[[0,0],[2,0],[2,2],[0,2],[0,0]]]}`
+	hash := "48a788eb00a53820409c2bbf2f126517"
+
+	tests := []struct {
+		properties string
+		expected   map[string]Severity
+	}{
+		{`{}`, map[string]Severity{}},
+		{`{"geom:hash":"` + hash + `"}`, map[string]Severity{}},
+		{`{"geom:hash":"` + hash + `","wof:geomhash":"` + hash + `"}`, map[string]Severity{}},
+		{`{"geom:hash":"stale"}`, map[string]Severity{"properties.geom:hash": Warning}},
+		{`{"geom:hash":"` + hash + `","wof:geomhash":"stale"}`, map[string]Severity{"properties.wof:geomhash": Warning}},
+		{`{"geom:hash":1}`, map[string]Severity{"properties.geom:hash": Error}},
+	}
+
+	for _, test := range tests {
+		body := `{"type":"Feature","properties":` + test.properties + `,"geometry":` + square + `}`
+		checkProblems(t, &GeometryHashRule{}, "1.geojson", body, test.expected)
+	}
+}
+
+func TestStaleGeometryHashIsOK(t *testing.T) {
+
+	body := `{"type":"Feature","properties":{"geom:hash":"stale"},"geometry":{"type":"Point","coordinates":[0,0]}}`
+
+	v := NewValidator(&GeometryHashRule{})
+	result := v.Validate("1.geojson", testFeature(t, body))
+
+	if !result.OK() {
+		t.Errorf("expected a stale geom:hash not to fail validation, got %v", result.Problems)
+	}
+
+	if result.Count(Warning) != 1 {
+		t.Errorf("expected a stale geom:hash to be a warning, got %v", result.Problems)
+	}
+}
+
+func TestRequiredPropertiesRule(t *testing.T) {
+
+	all := `"wof:id":1,"wof:name":"x","wof:placetype":"country","wof:parent_id":-1,"wof:hierarchy":[],"wof:lastmodified":0,"wof:superseded_by":[],"wof:supersedes":[]`
+
+	tests := []struct {
+		properties string
+		expected   map[string]Severity
+	}{
+		{`{` + all + `}`, map[string]Severity{}},
+		{`{"wof:id":1,"wof:name":"x","wof:placetype":"country","wof:parent_id":-1,"wof:hierarchy":[],"wof:lastmodified":0}`, map[string]Severity{
+			"properties.wof:superseded_by": Error,
+			"properties.wof:supersedes":    Error,
+		}},
+	}
+
+	for _, test := range tests {
+		body := `{"type":"Feature","properties":` + test.properties + `,"geometry":null}`
+		checkProblems(t, NewRequiredPropertiesRule(), "1.geojson", body, test.expected)
+	}
+
+	r := RequiredPropertiesRule{Properties: []string{"wof:name"}}
+	checkProblems(t, &r, "", `{"type":"Feature","properties":{},"geometry":null}`, map[string]Severity{"properties.wof:name": Error})
+}
+
+func TestIdFilenameRule(t *testing.T) {
+
+	tests := []struct {
+		path     string
+		body     string
+		expected map[string]Severity
+	}{
+		{"data/123.geojson", `{"type":"Feature","id":123,"properties":{"wof:id":123}}`, map[string]Severity{}},
+		{"data/123-alt-osm.geojson", `{"type":"Feature","id":123,"properties":{"wof:id":123}}`, map[string]Severity{}},
+		{"", `{"type":"Feature","properties":{"wof:id":123}}`, map[string]Severity{}},
+		{"data/456.geojson", `{"type":"Feature","properties":{}}`, map[string]Severity{}},
+		{"data/456.geojson", `{"type":"Feature","id":123,"properties":{"wof:id":123}}`, map[string]Severity{"": Error}},
+		{"data/123-alt.geojson", `{"type":"Feature","properties":{"wof:id":123}}`, map[string]Severity{"": Error}},
+		{"data/123.json", `{"type":"Feature","properties":{"wof:id":123}}`, map[string]Severity{"": Error}},
+		{"data/123.geojson", `{"type":"Feature","id":456,"properties":{"wof:id":123}}`, map[string]Severity{"id": Error}},
+	}
+
+	for _, test := range tests {
+		checkProblems(t, &IdFilenameRule{}, test.path, test.body, test.expected)
+	}
+}
+
+func TestPlacetypeRule(t *testing.T) {
+
+	tests := []struct {
+		properties string
+		expected   map[string]Severity
+	}{
+		{`{"wof:placetype":"locality"}`, map[string]Severity{}},
+		{`{}`, map[string]Severity{}},
+		{`{"wof:placetype":"city"}`, map[string]Severity{"properties.wof:placetype": Error}},
+	}
+
+	for _, test := range tests {
+		body := `{"type":"Feature","properties":` + test.properties + `}`
+		checkProblems(t, &PlacetypeRule{}, "", body, test.expected)
+	}
+}
+
+func TestHierarchyRule(t *testing.T) {
+
+	tests := []struct {
+		properties string
+		expected   map[string]Severity
+	}{
+		{`{"wof:id":3,"wof:placetype":"locality"}`, map[string]Severity{}},
+		{`{"wof:id":3,"wof:placetype":"locality","wof:parent_id":2,"wof:hierarchy":[{"country_id":1,"region_id":2,"locality_id":3}]}`, map[string]Severity{}},
+		{`{"wof:id":3,"wof:placetype":"locality","wof:parent_id":-1,"wof:hierarchy":[]}`, map[string]Severity{}},
+		{`{"wof:id":3,"wof:placetype":"locality","wof:parent_id":5,"wof:hierarchy":[{"country_id":1,"region_id":2,"locality_id":3}]}`, map[string]Severity{
+			"properties.wof:parent_id": Error,
+		}},
+		{`{"wof:id":3,"wof:placetype":"locality","wof:parent_id":2,"wof:hierarchy":[]}`, map[string]Severity{
+			"properties.wof:parent_id": Error,
+			"properties.wof:hierarchy": Warning,
+		}},
+		{`{"wof:id":3,"wof:placetype":"locality","wof:parent_id":2,"wof:hierarchy":[{"country_id":1,"region_id":2}]}`, map[string]Severity{
+			"properties.wof:hierarchy[0]": Warning,
+		}},
+		{`{"wof:id":3,"wof:placetype":"locality","wof:parent_id":2,"wof:hierarchy":[{"country_id":-1,"region_id":2,"locality_id":4}]}`, map[string]Severity{
+			"properties.wof:hierarchy[0].locality_id": Error,
+			"properties.wof:hierarchy[0].country_id":  Error,
+		}},
+	}
+
+	for _, test := range tests {
+		body := `{"type":"Feature","properties":` + test.properties + `}`
+		checkProblems(t, &HierarchyRule{}, "", body, test.expected)
+	}
+}
+
+func TestBoundingBoxRule(t *testing.T) {
+
+	square := `{"type":"Polygon","coordinates":[[[0,0],[2,0],[2,2],[0,2],[0,0]]]}`
+	fiji := `{"type":"Polygon","coordinates":[[[177,-20],[-178,-20],[-178,-15],[177,-15],[177,-20]]]}`
+
+	tests := []struct {
+		bbox     string
+		geometry string
+		expected map[string]Severity
+	}{
+		{`[0,0,2,2]`, square, map[string]Severity{}},
+		{`[0,0,2,2.0000001]`, square, map[string]Severity{}},
+		{``, square, map[string]Severity{"bbox": Warning}},
+		{``, `null`, map[string]Severity{}},
+		{`[0,0,3,2]`, square, map[string]Severity{"bbox[2]": Error}},
+		{`[1,1,2,3]`, square, map[string]Severity{"bbox[0]": Error, "bbox[1]": Error, "bbox[3]": Error}},
+		{`[0,0,2]`, square, map[string]Severity{"bbox": Error}},
+		{`[177,-20,-178,-15]`, fiji, map[string]Severity{}},
+		{`[-180,-20,180,-15]`, fiji, map[string]Severity{}},
+		{`[-178,-20,177,-15]`, fiji, map[string]Severity{"bbox[0]": Error, "bbox[2]": Error}},
+		{`[0,0,2,2]`, `{"type":"Polygon","coordinates":[]}`, map[string]Severity{"": Error}},
+	}
+
+	for _, test := range tests {
+
+		bbox := ""
+
+		if test.bbox != "" {
+			bbox = `"bbox":` + test.bbox + `,`
+		}
+
+		body := `{"type":"Feature","properties":{},` + bbox + `"geometry":` + test.geometry + `}`
+		checkProblems(t, NewBoundingBoxRule(), "", body, test.expected)
+	}
+}
+
+func TestClosedRingsRule(t *testing.T) {
+
+	tests := []struct {
+		geometry string
+		expected map[string]Severity
+	}{
+		{`{"type":"Polygon","coordinates":[[[0,0],[2,0],[2,2],[0,2],[0,0]]]}`, map[string]Severity{}},
+		{`{"type":"Polygon","coordinates":[[[0,0],[2,0],[2,2],[0,2]]]}`, map[string]Severity{"polygon 0, ring 0": Error}},
+		{`{"type":"Polygon","coordinates":[[[0,0],[2,0],[0,0]]]}`, map[string]Severity{"polygon 0, ring 0": Error}},
+		{`{"type":"MultiPolygon","coordinates":[[[[0,0],[2,0],[2,2],[0,0]]],[[[5,5],[6,5],[6,6],[5,6],[5,5]],[[5.2,5.2],[5.2,5.8],[5.8,5.8],[5.8,5.2]]]]}`, map[string]Severity{"polygon 1, ring 1": Error}},
+		{`{"type":"Point","coordinates":[0,0]}`, map[string]Severity{}},
+		{`{"type":"Polygon","coordinates":"nope"}`, map[string]Severity{}},
+	}
+
+	for _, test := range tests {
+		body := `{"type":"Feature","properties":{},"geometry":` + test.geometry + `}`
+		checkProblems(t, &ClosedRingsRule{}, "", body, test.expected)
+	}
+}
+
+func TestGeometryRule(t *testing.T) {
+
+	tests := []struct {
+		geometry string
+		expected map[string]Severity
+	}{
+		{`{"type":"Polygon","coordinates":[[[0,0],[2,0],[2,2],[0,2],[0,0]]]}`, map[string]Severity{}},
+		{`{"type":"Polygon","coordinates":[[[0,0],[2,0],[2,2],[0,2]]]}`, map[string]Severity{}},
+		{`{"type":"Polygon","coordinates":[[[0,0],[0,2],[2,2],[2,0],[0,0]]]}`, map[string]Severity{"geometry.coordinates[0]": Warning}},
+		{`{"type":"Polygon","coordinates":[[[0,0],[2,0],[2,0],[2,2],[0,2],[0,0]]]}`, map[string]Severity{"geometry.coordinates[0][2]": Warning}},
+		{`{"type":"Polygon","coordinates":[[[0,0],[2,0],[0,2],[2,2],[0,0]]]}`, map[string]Severity{"geometry.coordinates[0][1]": Error}},
+		{`{"type":"Point","coordinates":[200,0]}`, map[string]Severity{"geometry.coordinates[0]": Error}},
+		{`{"type":"Point","coordinates":"nope"}`, map[string]Severity{}},
+	}
+
+	for _, test := range tests {
+		body := `{"type":"Feature","properties":{},"geometry":` + test.geometry + `}`
+		checkProblems(t, &GeometryRule{}, "", body, test.expected)
+	}
+}
+
+func TestEDTFRule(t *testing.T) {
+
+	tests := []struct {
+		properties string
+		expected   map[string]Severity
+	}{
+		{`{}`, map[string]Severity{}},
+		{`{"edtf:inception":"2001-02","edtf:cessation":"uuuu","edtf:deprecated":"","edtf:superseded":"2017~","edtf:date":"2001/2002"}`, map[string]Severity{}},
+		{`{"edtf:inception":"2001-13","edtf:cessation":"yesterday"}`, map[string]Severity{
+			"properties.edtf:inception": Error,
+			"properties.edtf:cessation": Error,
+		}},
+		{`{"edtf:deprecated":2017}`, map[string]Severity{"properties.edtf:deprecated": Error}},
+		{`{"edtf:whenever":"nope"}`, map[string]Severity{}},
+	}
+
+	for _, test := range tests {
+		body := `{"type":"Feature","properties":` + test.properties + `}`
+		checkProblems(t, NewEDTFRule(), "", body, test.expected)
+	}
+}
@@ -0,0 +1,243 @@
+package validator
+
+import (
+	"encoding/json"
+	"fmt"
+	geojson "github.com/whosonfirst/go-whosonfirst-geojson"
+	"io"
+	"strings"
+)
+
+/*
+
+A rule-based validator for Who's On First documents. A Validator is just a list of Rule thing-ies
+each of which looks at a feature and reports zero or more Problems. Problems have a severity so
+that consumers can decide for themselves what is fatal and what is just worth knowing about.
+
+See rules.go for the rules that ship with this package (and DefaultRules for the ones that are
+used if you don't say otherwise). Writing your own is a matter of satisfying the Rule interface.
+
+*/
+
+type Severity int
+
+const (
+	Info Severity = iota
+	Warning
+	Error
+)
+
+func (s Severity) String() string {
+
+	switch s {
+	case Info:
+		return "info"
+	case Warning:
+		return "warning"
+	default:
+		return "error"
+	}
+}
+
+func (s Severity) MarshalJSON() ([]byte, error) {
+	return json.Marshal(s.String())
+}
+
+type Problem struct {
+	Rule     string   `json:"rule"`
+	Severity Severity `json:"severity"`
+	Path     string   `json:"path,omitempty"` // where in the feature the problem is, if that's meaningful
+	Message  string   `json:"message"`
+}
+
+func (p *Problem) String() string {
+
+	if p.Path == "" {
+		return fmt.Sprintf("[%s] %s: %s", p.Severity, p.Rule, p.Message)
+	}
+
+	return fmt.Sprintf("[%s] %s: %s: %s", p.Severity, p.Rule, p.Path, p.Message)
+}
+
+type Rule interface {
+	Name() string
+	Validate(path string, f *geojson.WOFFeature) []*Problem
+}
+
+// Result is the list of problems for a single file
+
+type Result struct {
+	Path     string     `json:"path"`
+	Id       int        `json:"wof:id"`
+	Problems []*Problem `json:"problems"`
+}
+
+func (r *Result) Count(severity Severity) int {
+
+	count := 0
+
+	for _, p := range r.Problems {
+
+		if p.Severity == severity {
+			count += 1
+		}
+	}
+
+	return count
+}
+
+// OK is true if there are no problems with a severity of Error
+
+func (r *Result) OK() bool {
+	return r.Count(Error) == 0
+}
+
+type Validator struct {
+	Rules []Rule
+}
+
+func NewValidator(rules ...Rule) *Validator {
+
+	if len(rules) == 0 {
+		rules = DefaultRules()
+	}
+
+	v := Validator{
+		Rules: rules,
+	}
+
+	return &v
+}
+
+func (v *Validator) Validate(path string, f *geojson.WOFFeature) *Result {
+
+	problems := make([]*Problem, 0)
+
+	for _, r := range v.Rules {
+		problems = append(problems, r.Validate(path, f)...)
+	}
+
+	result := Result{
+		Path:     path,
+		Id:       f.Id(),
+		Problems: problems,
+	}
+
+	return &result
+}
+
+// ValidateFile is the same as Validate except that it reads the file first; files that can't
+// be read or parsed are reported as a single Error (with the rule name "parse")
+
+func (v *Validator) ValidateFile(path string) *Result {
+
+	f, err := geojson.UnmarshalFile(path)
+
+	if err != nil {
+
+		problem := Problem{
+			Rule:     "parse",
+			Severity: Error,
+			Message:  err.Error(),
+		}
+
+		result := Result{
+			Path:     path,
+			Id:       -1,
+			Problems: []*Problem{&problem},
+		}
+
+		return &result
+	}
+
+	return v.Validate(path, f)
+}
+
+// Report collects Results across lots of files. Only results with problems are kept around
+// (there may be hundreds of thousands of files) but everything is counted.
+
+type Report struct {
+	Files    int       `json:"files"`
+	Failed   int       `json:"failed"`
+	Errors   int       `json:"errors"`
+	Warnings int       `json:"warnings"`
+	Results  []*Result `json:"results"`
+}
+
+func NewReport() *Report {
+
+	r := Report{
+		Results: make([]*Result, 0),
+	}
+
+	return &r
+}
+
+func (r *Report) Add(result *Result) {
+
+	r.Files += 1
+	r.Errors += result.Count(Error)
+	r.Warnings += result.Count(Warning)
+
+	if !result.OK() {
+		r.Failed += 1
+	}
+
+	if len(result.Problems) > 0 {
+		r.Results = append(r.Results, result)
+	}
+}
+
+func (r *Report) WriteJSON(wr io.Writer) error {
+
+	enc := json.NewEncoder(wr)
+	enc.SetIndent("", "  ")
+
+	return enc.Encode(r)
+}
+
+func (r *Report) WriteText(wr io.Writer) error {
+
+	for _, result := range r.Results {
+
+		for _, p := range result.Problems {
+
+			_, err := fmt.Fprintf(wr, "%s %s\n", result.Path, p)
+
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	_, err := fmt.Fprintf(wr, "%d files, %d failed, %d errors, %d warnings\n", r.Files, r.Failed, r.Errors, r.Warnings)
+	return err
+}
+
+// RulesByName returns the rules (from AllRules) with the given names, which is handy for
+// command line tools
+
+func RulesByName(names ...string) ([]Rule, error) {
+
+	available := make(map[string]Rule)
+
+	for _, r := range AllRules() {
+		available[r.Name()] = r
+	}
+
+	rules := make([]Rule, 0)
+
+	for _, name := range names {
+
+		name = strings.TrimSpace(name)
+		r, ok := available[name]
+
+		if !ok {
+			return nil, fmt.Errorf("Unknown rule '%s'", name)
+		}
+
+		rules = append(rules, r)
+	}
+
+	return rules, nil
+}
@@ -0,0 +1,192 @@
+package validator
+
+import (
+	"bytes"
+	"encoding/json"
+	geojson "github.com/whosonfirst/go-whosonfirst-geojson"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// a rule that reports whatever it's told to
+
+type cannedRule struct {
+	severities []Severity
+}
+
+func (r *cannedRule) Name() string {
+	return "canned"
+}
+
+func (r *cannedRule) Validate(path string, f *geojson.WOFFeature) []*Problem {
+
+	problems := make([]*Problem, 0)
+
+	for _, s := range r.severities {
+		problems = append(problems, problem(r, s, "", "%s", s))
+	}
+
+	return problems
+}
+
+func TestResult(t *testing.T) {
+
+	tests := []struct {
+		severities []Severity
+		errors     int
+		warnings   int
+		ok         bool
+	}{
+		{[]Severity{}, 0, 0, true},
+		{[]Severity{Info, Warning, Warning}, 0, 2, true},
+		{[]Severity{Warning, Error}, 1, 1, false},
+	}
+
+	f := testFeature(t, `{"type":"Feature","properties":{"wof:id":1}}`)
+
+	for _, test := range tests {
+
+		v := NewValidator(&cannedRule{test.severities})
+		result := v.Validate("1.geojson", f)
+
+		if result.Id != 1 || result.Path != "1.geojson" {
+			t.Errorf("expected the result to be for 1.geojson, got %d %s", result.Id, result.Path)
+		}
+
+		if result.Count(Error) != test.errors || result.Count(Warning) != test.warnings {
+			t.Errorf("expected %d errors and %d warnings, got %v", test.errors, test.warnings, result.Problems)
+		}
+
+		if result.OK() != test.ok {
+			t.Errorf("expected OK to be %t for %v", test.ok, result.Problems)
+		}
+	}
+
+	if len(NewValidator().Rules) != len(AllRules()) {
+		t.Error("expected a validator with no rules to use the default rules")
+	}
+}
+
+func TestValidateFile(t *testing.T) {
+
+	dir, err := ioutil.TempDir("", "validator")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer os.RemoveAll(dir)
+
+	good := filepath.Join(dir, "1.geojson")
+	bad := filepath.Join(dir, "2.geojson")
+
+	err = ioutil.WriteFile(good, []byte(`{"type":"Feature","properties":{"wof:id":1}}`), 0644)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = ioutil.WriteFile(bad, []byte(`{"type":"Feature",`), 0644)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	v := NewValidator(&IdFilenameRule{})
+
+	result := v.ValidateFile(good)
+
+	if result.Id != 1 || len(result.Problems) != 0 {
+		t.Errorf("expected %s to be fine, got %v", good, result.Problems)
+	}
+
+	for _, path := range []string{bad, filepath.Join(dir, "3.geojson")} {
+
+		result = v.ValidateFile(path)
+
+		if result.Id != -1 || len(result.Problems) != 1 || result.Problems[0].Rule != "parse" || result.OK() {
+			t.Errorf("expected %s to fail to parse, got %v", path, result.Problems)
+		}
+	}
+}
+
+func TestReport(t *testing.T) {
+
+	f := testFeature(t, `{"type":"Feature","properties":{"wof:id":1}}`)
+	report := NewReport()
+
+	for _, severities := range [][]Severity{{}, {Warning}, {Error, Warning}, {Error}} {
+		report.Add(NewValidator(&cannedRule{severities}).Validate("1.geojson", f))
+	}
+
+	if report.Files != 4 || report.Failed != 2 || report.Errors != 2 || report.Warnings != 2 {
+		t.Errorf("unexpected counts %d files, %d failed, %d errors, %d warnings", report.Files, report.Failed, report.Errors, report.Warnings)
+	}
+
+	if len(report.Results) != 3 {
+		t.Errorf("expected only the results with problems to be kept, got %d", len(report.Results))
+	}
+
+	var text bytes.Buffer
+
+	err := report.WriteText(&text)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(text.String()), "\n")
+
+	if len(lines) != 5 || lines[0] != "1.geojson [warning] canned: warning" || lines[4] != "4 files, 2 failed, 2 errors, 2 warnings" {
+		t.Errorf("unexpected text report %q", text.String())
+	}
+
+	var encoded bytes.Buffer
+
+	err = report.WriteJSON(&encoded)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var decoded struct {
+		Failed  int `json:"failed"`
+		Results []struct {
+			Problems []struct {
+				Severity string `json:"severity"`
+			} `json:"problems"`
+		} `json:"results"`
+	}
+
+	err = json.Unmarshal(encoded.Bytes(), &decoded)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if decoded.Failed != 2 || len(decoded.Results) != 3 || decoded.Results[1].Problems[0].Severity != "error" {
+		t.Errorf("unexpected JSON report %s", encoded.String())
+	}
+}
+
+func TestRulesByName(t *testing.T) {
+
+	rules, err := RulesByName("bbox", " edtf ")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(rules) != 2 || rules[0].Name() != "bbox" || rules[1].Name() != "edtf" {
+		t.Errorf("expected the bbox and edtf rules, got %v", rules)
+	}
+
+	_, err = RulesByName("bbox", "nope")
+
+	if err == nil {
+		t.Error("expected an unknown rule to be an error")
+	}
+}
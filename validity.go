@@ -0,0 +1,517 @@
+package geojson
+
+import (
+	"fmt"
+	geo "github.com/kellydunn/golang-geo"
+	"math"
+	"sort"
+)
+
+/*
+
+ValidateGeometry looks for the things that make a geometry invalid (or at least suspect) in ways
+that matter for the rest of this package: rings that aren't closed or are too short, repeated
+points, holes that aren't inside their shells, edges that cross one another and rings that are
+wound the wrong way round (according to RFC 7946, outer rings should be counter-clockwise and
+holes should be clockwise). See repair.go for fixing them.
+
+*/
+
+const (
+	ProblemInvalidCoordinate = "invalid-coordinate"
+	ProblemTooFewPoints      = "too-few-points"
+	ProblemUnclosedRing      = "unclosed-ring"
+	ProblemRepeatedPoint     = "repeated-point"
+	ProblemHoleOutsideShell  = "hole-outside-shell"
+	ProblemSelfIntersection  = "self-intersection"
+	ProblemWindingOrder      = "winding-order"
+)
+
+// GeometryProblem describes a single problem with a geometry. Offset is the index of the simple
+// geometry in the list returned by Flatten (the same as WOFSpatial.Offset) and Path is where
+// the problem is in the feature, using the same notation as DecodeError. Ring and Position are
+// -1 when they don't apply.
+
+type GeometryProblem struct {
+	Type      string  `json:"type"`
+	Path      string  `json:"path"`
+	Offset    int     `json:"offset"`
+	Ring      int     `json:"ring"`
+	Position  int     `json:"position"`
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+	Message   string  `json:"message"`
+}
+
+func (p *GeometryProblem) String() string {
+	return fmt.Sprintf("%s: %s (%s)", p.Path, p.Message, p.Type)
+}
+
+// a simple geometry and where it lives in the feature
+
+type geometryPart struct {
+	geometry Geometry
+	path     string
+}
+
+func flattenWithPaths(g Geometry, path string) []geometryPart {
+
+	parts := make([]geometryPart, 0)
+
+	switch geom := g.(type) {
+
+	case *MultiPoint:
+
+		for i, pt := range geom.Coordinates {
+			parts = append(parts, geometryPart{&Point{Coordinate: pt}, indexPath(path+".coordinates", i)})
+		}
+
+	case *MultiLineString:
+
+		for i, l := range geom.LineStrings {
+			parts = append(parts, geometryPart{l, indexPath(path+".coordinates", i)})
+		}
+
+	case *MultiPolygon:
+
+		for i, p := range geom.WOFPolygons {
+			parts = append(parts, geometryPart{&Polygon{p}, indexPath(path+".coordinates", i)})
+		}
+
+	case *GeometryCollection:
+
+		for i, child := range geom.Geometries {
+			parts = append(parts, flattenWithPaths(child, indexPath(path+".geometries", i))...)
+		}
+
+	case nil:
+		// pass
+
+	default:
+		parts = append(parts, geometryPart{g, path + ".coordinates"})
+	}
+
+	return parts
+}
+
+func ValidateGeometry(g Geometry) []*GeometryProblem {
+
+	problems := make([]*GeometryProblem, 0)
+
+	for offset, part := range flattenWithPaths(g, "geometry") {
+
+		switch geom := part.geometry.(type) {
+
+		case *Point:
+			problems = append(problems, validatePositions([]*geo.Point{geom.Coordinate}, offset, -1, part.path)...)
+
+		case *LineString:
+
+			points := geom.Coordinates
+
+			problems = append(problems, validatePositions(points, offset, -1, part.path)...)
+			problems = append(problems, validateRepeated(points, offset, -1, part.path)...)
+
+			if len(points) < 2 {
+				problems = append(problems, newGeometryProblem(ProblemTooFewPoints, part.path, offset, -1, -1, nil, "line has %d positions, expected at least 2", len(points)))
+			}
+
+		case *Polygon:
+			problems = append(problems, validatePolygon(geom.WOFPolygon, offset, part.path)...)
+		}
+	}
+
+	return problems
+}
+
+func validatePolygon(poly *WOFPolygon, offset int, path string) []*GeometryProblem {
+
+	problems := make([]*GeometryProblem, 0)
+
+	// polygons that cross the antimeridian are checked using shifted longitudes (see
+	// antimeridian.go) otherwise they look like they wind the wrong way round
+
+	original := polygonRings(poly)
+	rings, _ := shiftedPolygonRings(poly)
+
+	for idx, points := range rings {
+
+		ring_path := indexPath(path, idx)
+		count := len(points)
+
+		raw := original[idx]
+
+		problems = append(problems, validatePositions(raw, offset, idx, ring_path)...)
+		problems = append(problems, validateRepeated(raw, offset, idx, ring_path)...)
+
+		if count < 4 {
+			problems = append(problems, newGeometryProblem(ProblemTooFewPoints, ring_path, offset, idx, -1, nil, "ring has %d positions, expected at least 4", count))
+		}
+
+		if count == 0 {
+			continue
+		}
+
+		if !samePoint(raw[0], raw[count-1]) {
+			problems = append(problems, newGeometryProblem(ProblemUnclosedRing, ring_path, offset, idx, count-1, raw[count-1], "ring is not closed"))
+		}
+
+		if count >= 4 {
+
+			area := ringArea(points)
+
+			if idx == 0 && area < 0.0 {
+				problems = append(problems, newGeometryProblem(ProblemWindingOrder, ring_path, offset, idx, -1, nil, "outer ring is clockwise, expected counter-clockwise"))
+			}
+
+			if idx > 0 && area > 0.0 {
+				problems = append(problems, newGeometryProblem(ProblemWindingOrder, ring_path, offset, idx, -1, nil, "interior ring is counter-clockwise, expected clockwise"))
+			}
+		}
+	}
+
+	// holes should be inside their shell; if a hole crosses the shell that will be
+	// reported as a self-intersection so all we need to worry about here is holes
+	// that are (mostly) on the wrong side of it
+
+	if len(rings) > 1 && len(rings[0]) >= 3 {
+
+		shell := preparePolygon(&WOFPolygon{OuterRing: *geo.NewPolygon(rings[0])})
+
+		for idx, points := range rings[1:] {
+
+			inside := 0
+			outside := 0
+
+			for _, pt := range points {
+
+				if shell.contains(pt.Lat(), pt.Lng()) {
+					inside += 1
+				} else {
+					outside += 1
+				}
+			}
+
+			if outside > inside {
+				problems = append(problems, newGeometryProblem(ProblemHoleOutsideShell, indexPath(path, idx+1), offset, idx+1, -1, nil, "interior ring is not inside the outer ring"))
+			}
+		}
+	}
+
+	for _, x := range findIntersections(rings, false) {
+
+		ring_path := indexPath(path, x.ring_a)
+		pos_path := indexPath(ring_path, x.edge_a)
+		pt := geo.NewPoint(x.y, unshiftLongitude(x.x))
+
+		msg := fmt.Sprintf("edge %d of ring %d crosses edge %d of ring %d", x.edge_a, x.ring_a, x.edge_b, x.ring_b)
+		problems = append(problems, newGeometryProblem(ProblemSelfIntersection, pos_path, offset, x.ring_a, x.edge_a, pt, "%s", msg))
+	}
+
+	return problems
+}
+
+func validatePositions(points []*geo.Point, offset int, ring int, path string) []*GeometryProblem {
+
+	problems := make([]*GeometryProblem, 0)
+
+	for i, pt := range points {
+
+		lat := pt.Lat()
+		lon := pt.Lng()
+
+		if math.IsNaN(lat) || math.IsNaN(lon) || lat < -90.0 || lat > 90.0 || lon < -180.0 || lon > 180.0 {
+			problems = append(problems, newGeometryProblem(ProblemInvalidCoordinate, indexPath(path, i), offset, ring, i, pt, "position is outside the range of valid coordinates"))
+		}
+	}
+
+	return problems
+}
+
+func validateRepeated(points []*geo.Point, offset int, ring int, path string) []*GeometryProblem {
+
+	problems := make([]*GeometryProblem, 0)
+
+	for i := 1; i < len(points); i++ {
+
+		if samePoint(points[i-1], points[i]) {
+			problems = append(problems, newGeometryProblem(ProblemRepeatedPoint, indexPath(path, i), offset, ring, i, points[i], "position is the same as the one before it"))
+		}
+	}
+
+	return problems
+}
+
+func newGeometryProblem(problem_type string, path string, offset int, ring int, position int, pt *geo.Point, msg string, args ...interface{}) *GeometryProblem {
+
+	p := GeometryProblem{
+		Type:     problem_type,
+		Path:     path,
+		Offset:   offset,
+		Ring:     ring,
+		Position: position,
+		Message:  fmt.Sprintf(msg, args...),
+	}
+
+	if pt != nil {
+		p.Latitude = pt.Lat()
+		p.Longitude = pt.Lng()
+	}
+
+	return &p
+}
+
+func polygonRings(poly *WOFPolygon) [][]*geo.Point {
+
+	rings := make([][]*geo.Point, 0)
+	rings = append(rings, poly.OuterRing.Points())
+
+	for _, r := range poly.InteriorRings {
+		rings = append(rings, r.Points())
+	}
+
+	return rings
+}
+
+func samePoint(a *geo.Point, b *geo.Point) bool {
+	return a.Lat() == b.Lat() && a.Lng() == b.Lng()
+}
+
+// ringArea is the signed (planar) area of a ring in square degrees; it is positive if the ring
+// is counter-clockwise and negative otherwise
+
+func ringArea(points []*geo.Point) float64 {
+
+	count := len(points)
+
+	if count < 3 {
+		return 0.0
+	}
+
+	area := 0.0
+
+	for i := 0; i < count; i++ {
+
+		a := points[i]
+		b := points[(i+1)%count]
+
+		area += a.Lng()*b.Lat() - b.Lng()*a.Lat()
+	}
+
+	return area / 2.0
+}
+
+// Finding edges that cross one another. This is a simple sweep along the x (longitude) axis
+// which is good enough for the sort of polygons we see in practice.
+
+type edge struct {
+	ring  int
+	index int // the index of the edge's first point in the ring
+	seq   int // the index of the edge in the ring, ignoring zero-length edges
+	last  bool
+	ax    float64
+	ay    float64
+	bx    float64
+	by    float64
+	minx  float64
+	maxx  float64
+}
+
+type intersection struct {
+	ring_a int
+	edge_a int
+	ring_b int
+	edge_b int
+	x      float64
+	y      float64
+}
+
+func ringEdges(ring int, points []*geo.Point) []*edge {
+	return pathEdges(ring, points, true)
+}
+
+// the edges between consecutive points, plus the one from the last point back to the first if
+// closed is true and they aren't the same already
+
+func pathEdges(ring int, points []*geo.Point, closed bool) []*edge {
+
+	edges := make([]*edge, 0)
+	count := len(points)
+
+	if count < 2 {
+		return edges
+	}
+
+	add := func(i int, a *geo.Point, b *geo.Point) {
+
+		if samePoint(a, b) {
+			return
+		}
+
+		e := edge{
+			ring:  ring,
+			index: i,
+			seq:   len(edges),
+			ax:    a.Lng(),
+			ay:    a.Lat(),
+			bx:    b.Lng(),
+			by:    b.Lat(),
+			minx:  math.Min(a.Lng(), b.Lng()),
+			maxx:  math.Max(a.Lng(), b.Lng()),
+		}
+
+		edges = append(edges, &e)
+	}
+
+	for i := 1; i < count; i++ {
+		add(i-1, points[i-1], points[i])
+	}
+
+	// unclosed rings are implicitly closed
+
+	if closed {
+		add(count-1, points[count-1], points[0])
+	}
+
+	if len(edges) > 0 {
+		edges[len(edges)-1].last = true
+	}
+
+	return edges
+}
+
+func findIntersections(rings [][]*geo.Point, first_only bool) []*intersection {
+
+	edges := make([]*edge, 0)
+
+	for idx, points := range rings {
+		edges = append(edges, ringEdges(idx, points)...)
+	}
+
+	sort.Slice(edges, func(i, j int) bool {
+		return edges[i].minx < edges[j].minx
+	})
+
+	found := make([]*intersection, 0)
+	active := make([]*edge, 0)
+
+	for _, e := range edges {
+
+		still_active := active[:0]
+
+		for _, other := range active {
+
+			if other.maxx >= e.minx {
+				still_active = append(still_active, other)
+			}
+		}
+
+		active = still_active
+
+		for _, other := range active {
+
+			if adjacentEdges(e, other) {
+				continue
+			}
+
+			x, y, ok := crossEdges(e, other, e.ring == other.ring)
+
+			if !ok {
+				continue
+			}
+
+			a := e
+			b := other
+
+			if b.ring < a.ring || (b.ring == a.ring && b.index < a.index) {
+				a, b = b, a
+			}
+
+			found = append(found, &intersection{a.ring, a.index, b.ring, b.index, x, y})
+
+			if first_only {
+				return found
+			}
+		}
+
+		active = append(active, e)
+	}
+
+	sort.Slice(found, func(i, j int) bool {
+
+		if found[i].ring_a != found[j].ring_a {
+			return found[i].ring_a < found[j].ring_a
+		}
+
+		return found[i].edge_a < found[j].edge_a
+	})
+
+	return found
+}
+
+func adjacentEdges(a *edge, b *edge) bool {
+
+	if a.ring != b.ring {
+		return false
+	}
+
+	d := a.seq - b.seq
+
+	if d == 1 || d == -1 {
+		return true
+	}
+
+	return (a.seq == 0 && b.last) || (b.seq == 0 && a.last)
+}
+
+func orientation(ax float64, ay float64, bx float64, by float64, cx float64, cy float64) float64 {
+	return (bx-ax)*(cy-ay) - (by-ay)*(cx-ax)
+}
+
+func onEdge(e *edge, x float64, y float64) bool {
+
+	return x >= math.Min(e.ax, e.bx) && x <= math.Max(e.ax, e.bx) &&
+		y >= math.Min(e.ay, e.by) && y <= math.Max(e.ay, e.by)
+}
+
+// crossEdges reports whether two edges cross and, if they do, where. Edges that only touch (or
+// overlap) are counted if touching is true; otherwise only proper crossings are.
+
+func crossEdges(a *edge, b *edge, touching bool) (float64, float64, bool) {
+
+	d1 := orientation(b.ax, b.ay, b.bx, b.by, a.ax, a.ay)
+	d2 := orientation(b.ax, b.ay, b.bx, b.by, a.bx, a.by)
+	d3 := orientation(a.ax, a.ay, a.bx, a.by, b.ax, b.ay)
+	d4 := orientation(a.ax, a.ay, a.bx, a.by, b.bx, b.by)
+
+	if ((d1 > 0 && d2 < 0) || (d1 < 0 && d2 > 0)) && ((d3 > 0 && d4 < 0) || (d3 < 0 && d4 > 0)) {
+
+		t := d1 / (d1 - d2)
+		x := a.ax + t*(a.bx-a.ax)
+		y := a.ay + t*(a.by-a.ay)
+
+		return x, y, true
+	}
+
+	if !touching {
+		return 0.0, 0.0, false
+	}
+
+	if d1 == 0 && onEdge(b, a.ax, a.ay) {
+		return a.ax, a.ay, true
+	}
+
+	if d2 == 0 && onEdge(b, a.bx, a.by) {
+		return a.bx, a.by, true
+	}
+
+	if d3 == 0 && onEdge(a, b.ax, b.ay) {
+		return b.ax, b.ay, true
+	}
+
+	if d4 == 0 && onEdge(a, b.bx, b.by) {
+		return b.bx, b.by, true
+	}
+
+	return 0.0, 0.0, false
+}
@@ -0,0 +1,94 @@
+package geojson
+
+import (
+	"sort"
+	"testing"
+)
+
+// the type and path of each problem, sorted
+
+func problemKeys(problems []*GeometryProblem) []string {
+
+	keys := make([]string, 0)
+
+	for _, p := range problems {
+		keys = append(keys, p.Type+" "+p.Path)
+	}
+
+	sort.Strings(keys)
+	return keys
+}
+
+func sameStrings(a []string, b []string) bool {
+
+	if len(a) != len(b) {
+		return false
+	}
+
+	for i := range a {
+
+		if a[i] != b[i] {
+			return false
+		}
+	}
+
+	return true
+}
+
+func TestValidateGeometry(t *testing.T) {
+
+	tests := []struct {
+		name     string
+		geometry string
+		expected []string
+	}{
+		{"point", `{"type":"Point","coordinates":[1,2]}`, []string{}},
+		{"bad point", `{"type":"Point","coordinates":[181,2]}`, []string{"invalid-coordinate geometry.coordinates[0]"}},
+		{"multipoint", `{"type":"MultiPoint","coordinates":[[1,2],[1,91]]}`, []string{"invalid-coordinate geometry.coordinates[1][0]"}},
+		{"line", `{"type":"LineString","coordinates":[[0,0],[1,1]]}`, []string{}},
+		{"short line", `{"type":"LineString","coordinates":[[0,0]]}`, []string{"too-few-points geometry.coordinates"}},
+		{"repeated line", `{"type":"MultiLineString","coordinates":[[[0,0],[1,1]],[[0,0],[1,1],[1,1]]]}`, []string{"repeated-point geometry.coordinates[1][2]"}},
+		{"square", `{"type":"Polygon","coordinates":[[[0,0],[2,0],[2,2],[0,2],[0,0]]]}`, []string{}},
+		{"clockwise", `{"type":"Polygon","coordinates":[[[0,0],[0,2],[2,2],[2,0],[0,0]]]}`, []string{"winding-order geometry.coordinates[0]"}},
+		{"unclosed", `{"type":"Polygon","coordinates":[[[0,0],[2,0],[2,2],[0,2]]]}`, []string{"unclosed-ring geometry.coordinates[0]"}},
+		{"triangle", `{"type":"Polygon","coordinates":[[[0,0],[2,0],[0,0]]]}`, []string{"too-few-points geometry.coordinates[0]"}},
+		{"repeated", `{"type":"Polygon","coordinates":[[[0,0],[2,0],[2,0],[2,2],[0,2],[0,0]]]}`, []string{"repeated-point geometry.coordinates[0][2]"}},
+		{"bow-tie", `{"type":"Polygon","coordinates":[[[0,0],[2,0],[0,2],[2,2],[0,0]]]}`, []string{"self-intersection geometry.coordinates[0][1]"}},
+		{"hole", `{"type":"Polygon","coordinates":[[[0,0],[4,0],[4,4],[0,4],[0,0]],[[1,1],[1,2],[2,2],[2,1],[1,1]]]}`, []string{}},
+		{"backwards hole", `{"type":"Polygon","coordinates":[[[0,0],[4,0],[4,4],[0,4],[0,0]],[[1,1],[2,1],[2,2],[1,2],[1,1]]]}`, []string{"winding-order geometry.coordinates[1]"}},
+		{"hole outside", `{"type":"Polygon","coordinates":[[[0,0],[4,0],[4,4],[0,4],[0,0]],[[5,5],[5,6],[6,6],[6,5],[5,5]]]}`, []string{"hole-outside-shell geometry.coordinates[1]"}},
+		{"hole crossing", `{"type":"Polygon","coordinates":[[[0,0],[4,0],[4,4],[0,4],[0,0]],[[3,1],[3,2],[5,2],[5,1],[3,1]]]}`, []string{"self-intersection geometry.coordinates[0][1]", "self-intersection geometry.coordinates[0][1]"}},
+		{"multipolygon", `{"type":"MultiPolygon","coordinates":[[[[0,0],[2,0],[2,2],[0,2],[0,0]]],[[[5,5],[5,6],[6,6],[6,5],[5,5]]]]}`, []string{"winding-order geometry.coordinates[1][0]"}},
+		{"collection", `{"type":"GeometryCollection","geometries":[{"type":"Point","coordinates":[0,0]},{"type":"LineString","coordinates":[[0,0],[0,0]]}]}`, []string{"repeated-point geometry.geometries[1].coordinates[1]"}},
+		{"antimeridian", `{"type":"Polygon","coordinates":[[[177,-20],[-178,-20],[-178,-15],[177,-15],[177,-20]]]}`, []string{}},
+	}
+
+	for _, test := range tests {
+
+		keys := problemKeys(ValidateGeometry(testGeometry(t, test.geometry)))
+
+		if !sameStrings(keys, test.expected) {
+			t.Errorf("%s: expected %v, got %v", test.name, test.expected, keys)
+		}
+	}
+}
+
+func TestValidateGeometryProblemDetails(t *testing.T) {
+
+	g := testGeometry(t, `{"type":"MultiPolygon","coordinates":[[[[10,10],[11,10],[11,11],[10,10]]],[[[0,0],[2,0],[0,2],[2,2],[0,0]]]]}`)
+	problems := ValidateGeometry(g)
+
+	if len(problems) != 1 {
+		t.Fatalf("expected one problem, got %v", problems)
+	}
+
+	p := problems[0]
+
+	if p.Offset != 1 || p.Ring != 0 || p.Position != 1 {
+		t.Errorf("expected the problem to be at offset 1, ring 0, position 1, got %d %d %d", p.Offset, p.Ring, p.Position)
+	}
+
+	if p.Latitude != 1.0 || p.Longitude != 1.0 {
+		t.Errorf("expected the problem to be at 1, 1, got %v, %v", p.Latitude, p.Longitude)
+	}
+}
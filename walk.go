@@ -0,0 +1,114 @@
+package geojson
+
+import (
+	"fmt"
+	walk "github.com/whosonfirst/walk"
+	"os"
+	"strings"
+	"sync"
+)
+
+/*
+
+WalkFeatures is for tools that need to know whether they saw every file in a directory. The
+go-whosonfirst-crawl crawler prints any error it runs in to (a directory that can't be read,
+say) to STDOUT, stops and then reports success anyway, so anything built on it can't tell a
+complete crawl from half of one.
+
+WalkFeatures walks the directory itself: errors from walking the tree and from the callback
+are recorded rather than printed, everything else is still visited and they are all handed
+back (as a WalkError) at the end.
+
+*/
+
+// WalkError is returned by WalkFeatures when something went wrong; Errors has one entry for
+// each directory or file that couldn't be read or processed
+
+type WalkError struct {
+	Root   string
+	Errors []error
+}
+
+func (e *WalkError) Error() string {
+
+	if len(e.Errors) == 1 {
+		return fmt.Sprintf("failed to walk %s: %s", e.Root, e.Errors[0])
+	}
+
+	return fmt.Sprintf("failed to walk %s: %s (and %d other errors)", e.Root, e.Errors[0], len(e.Errors)-1)
+}
+
+// WalkFeatures calls cb with the path of every .geojson file below root, from up to procs
+// goroutines at once, and returns a *WalkError if any part of root couldn't be read or cb
+// returned an error for any file
+
+func WalkFeatures(root string, procs int, cb func(path string) error) error {
+
+	if procs < 1 {
+		procs = 1
+	}
+
+	walk_err := &WalkError{Root: root, Errors: make([]error, 0)}
+	mu := new(sync.Mutex)
+
+	failed := func(err error) {
+		mu.Lock()
+		walk_err.Errors = append(walk_err.Errors, err)
+		mu.Unlock()
+	}
+
+	paths := make(chan string)
+	wg := new(sync.WaitGroup)
+
+	for i := 0; i < procs; i++ {
+
+		wg.Add(1)
+
+		go func() {
+
+			defer wg.Done()
+
+			for path := range paths {
+
+				err := cb(path)
+
+				if err != nil {
+					failed(fmt.Errorf("%s: %s", path, err))
+				}
+			}
+		}()
+	}
+
+	walker := func(path string, info os.FileInfo, err error) error {
+
+		// returning the error would stop the walk so make a note of it and carry on
+		// with everything else
+
+		if err != nil {
+			failed(err)
+			return nil
+		}
+
+		if info.IsDir() || !strings.HasSuffix(path, ".geojson") {
+			return nil
+		}
+
+		paths <- path
+		return nil
+	}
+
+	err := walk.Walk(root, walker)
+
+	close(paths)
+	wg.Wait()
+
+	if err != nil {
+		failed(err)
+	}
+
+	if len(walk_err.Errors) > 0 {
+		return walk_err
+	}
+
+	return nil
+}
@@ -0,0 +1,131 @@
+package geojson
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// walkDirectory creates a directory tree for WalkFeatures; each file's contents is its own name
+
+func walkDirectory(t *testing.T, files ...string) string {
+
+	root, err := ioutil.TempDir("", "walk")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, name := range files {
+
+		path := filepath.Join(root, name)
+
+		err := os.MkdirAll(filepath.Dir(path), 0755)
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		err = ioutil.WriteFile(path, []byte(name), 0644)
+
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	return root
+}
+
+func TestWalkFeatures(t *testing.T) {
+
+	root := walkDirectory(t, "1.geojson", "a/2.geojson", "a/b/3.geojson", "a/README.md", "broken.geojson")
+	defer os.RemoveAll(root)
+
+	visited := make([]string, 0)
+	mu := new(sync.Mutex)
+
+	err := WalkFeatures(root, 4, func(path string) error {
+
+		rel, _ := filepath.Rel(root, path)
+
+		mu.Lock()
+		visited = append(visited, filepath.ToSlash(rel))
+		mu.Unlock()
+
+		if rel == "broken.geojson" {
+			return errors.New("this one is broken")
+		}
+
+		return nil
+	})
+
+	sort.Strings(visited)
+	expected := []string{"1.geojson", "a/2.geojson", "a/b/3.geojson", "broken.geojson"}
+
+	if strings.Join(visited, ",") != strings.Join(expected, ",") {
+		t.Errorf("expected to visit %v, got %v", expected, visited)
+	}
+
+	walk_err, ok := err.(*WalkError)
+
+	if !ok || len(walk_err.Errors) != 1 || !strings.Contains(walk_err.Errors[0].Error(), "broken.geojson: this one is broken") {
+		t.Errorf("expected the callback's error to be returned, got %v", err)
+	}
+
+	err = WalkFeatures(filepath.Join(root, "nope"), 4, func(path string) error {
+		return nil
+	})
+
+	if _, ok := err.(*WalkError); !ok {
+		t.Errorf("expected a missing directory to be an error, got %v", err)
+	}
+}
+
+func TestWalkFeaturesUnreadableDirectory(t *testing.T) {
+
+	if os.Geteuid() == 0 {
+		t.Skip("root can read any directory")
+	}
+
+	root := walkDirectory(t, "1.geojson", "a/2.geojson", "b/3.geojson")
+	defer os.RemoveAll(root)
+
+	unreadable := filepath.Join(root, "a")
+
+	err := os.Chmod(unreadable, 0)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer os.Chmod(unreadable, 0755)
+
+	count := 0
+	mu := new(sync.Mutex)
+
+	err = WalkFeatures(root, 4, func(path string) error {
+
+		mu.Lock()
+		count += 1
+		mu.Unlock()
+
+		return nil
+	})
+
+	// everything else is still visited
+
+	if count != 2 {
+		t.Errorf("expected 2 files to be visited, got %d", count)
+	}
+
+	walk_err, ok := err.(*WalkError)
+
+	if !ok || len(walk_err.Errors) != 1 || !os.IsPermission(walk_err.Errors[0]) {
+		t.Errorf("expected the unreadable directory to be reported, got %v", err)
+	}
+}